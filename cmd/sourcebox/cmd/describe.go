@@ -0,0 +1,452 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// describeFormats lists the supported --format values.
+var describeFormats = []string{"text", "json", "markdown"}
+
+// describeCmd represents the describe command
+var describeCmd = &cobra.Command{
+	Use:   "describe <schema>",
+	Short: "Show the tables, columns and relationships of a schema",
+	Long: `Show what a schema contains without opening the file.
+
+Prints the schema's metadata, then each table with its record count,
+columns (type, nullability, keys, foreign key target and generator with a
+summary of its parameters) and indexes, followed by the relationships
+between tables and the order in which tables are generated.
+
+Relationships include every foreign key, whether or not the schema
+declares it in its relationships array.
+
+<schema> is the name of a built-in or user schema, or the path to a schema
+file (JSON or YAML).`,
+
+	Example: `  # Describe a built-in schema
+  sourcebox describe fintech-loans
+
+  # Focus on one table
+  sourcebox describe fintech-loans --table=loans
+
+  # Generate documentation for a schema you are writing
+  sourcebox describe my-schema.yaml --format=markdown > SCHEMA.md`,
+
+	Args: cobra.ExactArgs(1),
+	RunE: runDescribe,
+}
+
+// schemaDescription is what describe reports about a schema.
+type schemaDescription struct {
+	Name            string                `json:"name"`
+	Description     string                `json:"description,omitempty"`
+	Author          string                `json:"author,omitempty"`
+	Version         string                `json:"version,omitempty"`
+	Industry        string                `json:"industry,omitempty"`
+	Tags            []string              `json:"tags"`
+	ComplexityTier  int                   `json:"complexity_tier,omitempty"`
+	Databases       []string              `json:"databases"`
+	TotalRecords    int                   `json:"total_records"`
+	GenerationOrder []string              `json:"generation_order"`
+	Tables          []tableDescription    `json:"tables"`
+	Relationships   []schema.Relationship `json:"relationships"`
+}
+
+type tableDescription struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	RecordCount int                 `json:"record_count"`
+	Columns     []columnDescription `json:"columns"`
+	Indexes     []schema.Index      `json:"indexes"`
+}
+
+type columnDescription struct {
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	Nullable      bool    `json:"nullable"`
+	PrimaryKey    bool    `json:"primary_key,omitempty"`
+	Unique        bool    `json:"unique,omitempty"`
+	AutoIncrement bool    `json:"auto_increment,omitempty"`
+	Default       *string `json:"default,omitempty"`
+
+	// References is the foreign key target as "table.column", followed by
+	// its cardinality when it has one.
+	References  string                 `json:"references,omitempty"`
+	Generator   string                 `json:"generator,omitempty"`
+	Params      map[string]interface{} `json:"generator_params,omitempty"`
+	Description string                 `json:"description,omitempty"`
+}
+
+func runDescribe(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	table, _ := cmd.Flags().GetString("table")
+
+	s, err := loadSchemaArg(args[0])
+	if err != nil {
+		return err
+	}
+	desc, err := describeSchema(s, table)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch strings.ToLower(format) {
+	case "text":
+		return writeDescribeText(out, desc)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(desc)
+	case "markdown", "md":
+		writeDescribeMarkdown(out, desc)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s': must be one of: %s", format, strings.Join(describeFormats, ", "))
+	}
+}
+
+// describeSchema builds the description of s, limited to one table (and
+// the relationships touching it) when table is set.
+func describeSchema(s *schema.Schema, table string) (*schemaDescription, error) {
+	order, err := s.TableOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &schemaDescription{
+		Name:            s.Name,
+		Description:     s.Description,
+		Author:          s.Author,
+		Version:         s.Version,
+		Industry:        s.Metadata.Industry,
+		Tags:            append([]string{}, s.Metadata.Tags...),
+		ComplexityTier:  s.Metadata.ComplexityTier,
+		Databases:       append([]string{}, s.DatabaseType...),
+		GenerationOrder: order,
+		Tables:          []tableDescription{},
+		Relationships:   []schema.Relationship{},
+	}
+	for _, t := range s.Tables {
+		desc.TotalRecords += t.RecordCount
+	}
+
+	if table != "" && s.Table(table) == nil {
+		names := make([]string, len(s.Tables))
+		for i, t := range s.Tables {
+			names[i] = t.Name
+		}
+		return nil, fmt.Errorf("table '%s' not found in schema '%s' (tables: %s)", table, s.Name, strings.Join(names, ", "))
+	}
+
+	// Tables are listed in generation order, parents before children
+	for _, name := range order {
+		t := s.Table(name)
+		if t == nil || (table != "" && name != table) {
+			continue
+		}
+		desc.Tables = append(desc.Tables, describeTable(t))
+	}
+
+	for _, rel := range s.AllRelationships() {
+		if table == "" || rel.FromTable == table || rel.ToTable == table {
+			desc.Relationships = append(desc.Relationships, rel)
+		}
+	}
+	return desc, nil
+}
+
+func describeTable(t *schema.Table) tableDescription {
+	td := tableDescription{
+		Name:        t.Name,
+		Description: t.Description,
+		RecordCount: t.RecordCount,
+		Columns:     make([]columnDescription, len(t.Columns)),
+		Indexes:     append([]schema.Index{}, t.Indexes...),
+	}
+	for i, col := range t.Columns {
+		cd := columnDescription{
+			Name:          col.Name,
+			Type:          col.Type,
+			Nullable:      col.Nullable,
+			PrimaryKey:    col.PrimaryKey,
+			Unique:        col.Unique,
+			AutoIncrement: col.AutoIncrement,
+			Default:       col.Default,
+			Generator:     col.Generator,
+			Params:        col.GeneratorParams,
+			Description:   col.Description,
+		}
+		if fk := col.ForeignKey; fk != nil {
+			cd.References = fk.Table + "." + fk.Column
+			if c := fk.Cardinality; c != nil {
+				if c.CountColumn != "" {
+					cd.References += fmt.Sprintf(" (%s per parent)", c.CountColumn)
+				} else {
+					cd.References += fmt.Sprintf(" (%d-%d per parent)", c.Min, c.Max)
+				}
+			}
+		}
+		td.Columns[i] = cd
+	}
+	return td
+}
+
+// keys renders a column's key markers, e.g. "PK" or "UQ".
+func (c columnDescription) keys() string {
+	var keys []string
+	if c.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if c.Unique {
+		keys = append(keys, "UQ")
+	}
+	if c.AutoIncrement {
+		keys = append(keys, "AI")
+	}
+	return strings.Join(keys, " ")
+}
+
+// generatorSummary renders the generator and its parameters on one line,
+// e.g. "int_range min=300, max=850".
+func (c columnDescription) generatorSummary() string {
+	params := summarizeParams(c.Params)
+	switch {
+	case c.Generator == "":
+		return params
+	case params == "":
+		return c.Generator
+	}
+	return c.Generator + " " + params
+}
+
+// summarizeParams renders generator parameters as "key=value" pairs in key
+// order. Distributions read as "type(params)", short lists of scalars are
+// spelled out and anything larger is counted.
+func summarizeParams(params map[string]interface{}) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + summarizeValue(params[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func summarizeValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		if kind, ok := v["type"].(string); ok {
+			params, _ := v["params"].(map[string]interface{})
+			return kind + "(" + summarizeParams(params) + ")"
+		}
+		return "{" + summarizeParams(v) + "}"
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Sprintf("[%d %s]", len(v), plural(len(v), "entry", "entries"))
+			}
+			items = append(items, summarizeValue(item))
+		}
+		if len(items) > 5 {
+			return fmt.Sprintf("[%s, ... %d more]", strings.Join(items[:5], ", "), len(items)-5)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func writeDescribeText(out io.Writer, d *schemaDescription) error {
+	title := d.Name
+	if d.Version != "" {
+		title += " (version " + d.Version + ")"
+	}
+	fmt.Fprintln(out, title)
+	if d.Description != "" {
+		fmt.Fprintln(out, d.Description)
+	}
+	fmt.Fprintln(out)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, field := range [][2]string{
+		{"Author", d.Author},
+		{"Industry", d.Industry},
+		{"Tags", strings.Join(d.Tags, ", ")},
+		{"Complexity tier", tierString(d.ComplexityTier)},
+		{"Databases", strings.Join(d.Databases, ", ")},
+		{"Records", strconv.Itoa(d.TotalRecords)},
+		{"Generation order", strings.Join(d.GenerationOrder, " → ")},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(tw, "  %s:\t%s\n", field[0], field[1])
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, t := range d.Tables {
+		fmt.Fprintf(out, "\n%s: %d %s\n", t.Name, t.RecordCount, plural(t.RecordCount, "row", "rows"))
+		if t.Description != "" {
+			fmt.Fprintf(out, "  %s\n", t.Description)
+		}
+		fmt.Fprintln(out)
+
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  COLUMN\tTYPE\tNULLABLE\tKEY\tREFERENCES\tGENERATOR")
+		for _, c := range t.Columns {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.Type, yesNo(c.Nullable),
+				orDash(c.keys()), orDash(c.References), orDash(c.generatorSummary()))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if len(t.Indexes) > 0 {
+			fmt.Fprintln(out, "\n  Indexes:")
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, idx := range t.Indexes {
+				fmt.Fprintf(tw, "    %s\t(%s)\t%s\n", idx.Name, strings.Join(idx.Columns, ", "), indexKind(idx))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(d.Relationships) > 0 {
+		fmt.Fprintln(out, "\nRelationships:")
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, rel := range d.Relationships {
+			fmt.Fprintf(tw, "  %s.%s → %s.%s\t%s\t%s\n", rel.FromTable, rel.FromColumn, rel.ToTable, rel.ToColumn, rel.RelationshipType, rel.Description)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+func tierString(tier int) string {
+	if tier == 0 {
+		return ""
+	}
+	return strconv.Itoa(tier)
+}
+
+// indexKind renders an index's type and uniqueness, e.g. "BTREE, unique".
+func indexKind(idx schema.Index) string {
+	kind := idx.Type
+	if idx.Unique {
+		if kind != "" {
+			kind += ", "
+		}
+		kind += "unique"
+	}
+	return kind
+}
+
+func writeDescribeMarkdown(out io.Writer, d *schemaDescription) {
+	fmt.Fprintf(out, "# %s\n\n", d.Name)
+	if d.Description != "" {
+		fmt.Fprintf(out, "%s\n\n", d.Description)
+	}
+
+	fmt.Fprintln(out, "| Property | Value |")
+	fmt.Fprintln(out, "|----------|-------|")
+	for _, field := range [][2]string{
+		{"Version", d.Version},
+		{"Author", d.Author},
+		{"Industry", d.Industry},
+		{"Tags", strings.Join(d.Tags, ", ")},
+		{"Complexity tier", tierString(d.ComplexityTier)},
+		{"Databases", strings.Join(d.Databases, ", ")},
+		{"Records", strconv.Itoa(d.TotalRecords)},
+		{"Generation order", strings.Join(d.GenerationOrder, " → ")},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(out, "| %s | %s |\n", field[0], markdownCell(field[1]))
+		}
+	}
+
+	fmt.Fprintln(out, "\n## Tables")
+	for _, t := range d.Tables {
+		fmt.Fprintf(out, "\n### %s\n\n", t.Name)
+		if t.Description != "" {
+			fmt.Fprintf(out, "%s\n\n", t.Description)
+		}
+		fmt.Fprintf(out, "%d %s.\n\n", t.RecordCount, plural(t.RecordCount, "row", "rows"))
+
+		fmt.Fprintln(out, "| Column | Type | Nullable | Key | References | Generator | Description |")
+		fmt.Fprintln(out, "|--------|------|----------|-----|------------|-----------|-------------|")
+		for _, c := range t.Columns {
+			fmt.Fprintf(out, "| %s | `%s` | %s | %s | %s | %s | %s |\n", c.Name, c.Type, yesNo(c.Nullable),
+				c.keys(), markdownCell(c.References), markdownCell(c.generatorSummary()), markdownCell(c.Description))
+		}
+
+		if len(t.Indexes) > 0 {
+			fmt.Fprintln(out, "\n| Index | Columns | Type |")
+			fmt.Fprintln(out, "|-------|---------|------|")
+			for _, idx := range t.Indexes {
+				fmt.Fprintf(out, "| %s | %s | %s |\n", idx.Name, strings.Join(idx.Columns, ", "), indexKind(idx))
+			}
+		}
+	}
+
+	if len(d.Relationships) > 0 {
+		fmt.Fprintln(out, "\n## Relationships")
+		fmt.Fprintln(out, "\n| From | To | Type | Description |")
+		fmt.Fprintln(out, "|------|----|------|-------------|")
+		for _, rel := range d.Relationships {
+			fmt.Fprintf(out, "| %s.%s | %s.%s | %s | %s |\n", rel.FromTable, rel.FromColumn, rel.ToTable, rel.ToColumn,
+				rel.RelationshipType, markdownCell(rel.Description))
+		}
+	}
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+
+	describeCmd.Flags().String("table", "", "only describe this table and its relationships")
+	describeCmd.Flags().String("format", "text", "output format: "+strings.Join(describeFormats, ", "))
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeCommand(t *testing.T) {
+	output, _, err := executeCommand(t, "", "describe", "fintech-loans")
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "fintech-loans (version 1.0.0)\n")
+	assert.Contains(t, output, "Generation order:  borrowers → loans → payments\n")
+	assert.Contains(t, output, "\nloans: 725 rows\n")
+	assert.Regexp(t, `borrower_id\s+int\s+no\s+-\s+borrowers\.id \(0-12 per parent\)\s+-\n`, output)
+	assert.Regexp(t, `credit_score\s+int\s+no\s+-\s+-\s+int_range distribution=normal\(max=850, mean=680, min=300, std_dev=80\), max=850, min=300\n`, output)
+	assert.Regexp(t, `idx_borrower_email\s+\(email\)\s+BTREE, unique\n`, output)
+	assert.Regexp(t, `payments\.loan_id → loans\.id\s+many_to_one\s+Each payment`, output)
+}
+
+func TestDescribeCommandTable(t *testing.T) {
+	output, _, err := executeCommand(t, "", "describe", exampleSchemaPath, "--table=payments")
+	require.NoError(t, err)
+	assert.Contains(t, output, "\npayments: 15225 rows\n")
+	assert.NotContains(t, output, "\nborrowers:")
+	assert.Contains(t, output, "payments.loan_id → loans.id")
+	assert.NotContains(t, output, "loans.borrower_id → borrowers.id")
+
+	_, _, err = executeCommand(t, "", "describe", exampleSchemaPath, "--table=fees")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'fees' not found in schema 'fintech-loans' (tables: borrowers, loans, payments)")
+}
+
+func TestDescribeCommandJSON(t *testing.T) {
+	output, _, err := executeCommand(t, "", "describe", "healthcare-patients", "--format=json")
+	require.NoError(t, err)
+
+	var desc schemaDescription
+	require.NoError(t, json.Unmarshal([]byte(output), &desc))
+	assert.Equal(t, "healthcare-patients", desc.Name)
+	assert.Equal(t, desc.GenerationOrder[0], desc.Tables[0].Name, "tables are listed in generation order")
+	assert.Len(t, desc.Relationships, 3)
+
+	var fks int
+	for _, table := range desc.Tables {
+		for _, col := range table.Columns {
+			if col.References != "" {
+				fks++
+			}
+		}
+	}
+	assert.Equal(t, 3, fks)
+}
+
+func TestDescribeCommandMarkdown(t *testing.T) {
+	output, _, err := executeCommand(t, "", "describe", "retail-orders", "--format=markdown", "--table=order_items")
+	require.NoError(t, err)
+	assert.Contains(t, output, "# retail-orders\n")
+	assert.Contains(t, output, "| Generation order | customers → products → orders → order_items |\n")
+	assert.Contains(t, output, "### order_items\n")
+	assert.Contains(t, output, "| order_id | `int` | no |  | orders.id |")
+	assert.Contains(t, output, "## Relationships\n")
+}
+
+func TestDescribeCommandErrors(t *testing.T) {
+	_, _, err := executeCommand(t, "", "describe", "fintech-loans", "--format=html")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format 'html': must be one of: text, json, markdown")
+
+	_, _, err = executeCommand(t, "", "describe", "no-such-schema")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema 'no-such-schema' not found")
+}
+
+func TestSummarizeParams(t *testing.T) {
+	assert.Equal(t, "", summarizeParams(nil))
+	assert.Equal(t, "max=10, min=1.5", summarizeParams(map[string]interface{}{"min": 1.5, "max": 10.0}))
+	assert.Equal(t, "distribution=zipf(s=1.1), values=[a, b]", summarizeParams(map[string]interface{}{
+		"distribution": map[string]interface{}{"type": "zipf", "params": map[string]interface{}{"s": 1.1}},
+		"values":       []interface{}{"a", "b"},
+	}))
+	assert.Equal(t, "values=[2 entries]", summarizeParams(map[string]interface{}{
+		"values": []interface{}{map[string]interface{}{"value": "a"}, map[string]interface{}{"value": "b"}},
+	}))
+	assert.Equal(t, "n=[1, 2, 3, 4, 5, ... 2 more]", summarizeParams(map[string]interface{}{
+		"n": []interface{}{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0},
+	}))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/diagram"
+	"github.com/spf13/cobra"
+)
+
+// diagramCmd represents the diagram command
+var diagramCmd = &cobra.Command{
+	Use:   "diagram <schema>",
+	Short: "Draw an entity-relationship diagram of a schema",
+	Long: `Draw an entity-relationship diagram of a schema as text that Mermaid,
+Graphviz or PlantUML can render.
+
+Each table is drawn with its key columns (primary, foreign and unique
+keys), or with every column when --all-columns is set. Relationships use
+crow's-foot notation taken from their relationship type: a parent has zero
+or more children (zero or one for one_to_one), and a child has exactly one
+parent, or zero or one when its foreign key is nullable. Every foreign key
+is drawn, whether or not the schema declares it in its relationships array.
+
+Formats:
+  mermaid   a Mermaid erDiagram, which GitHub renders in Markdown (default)
+  dot       a Graphviz digraph, for "dot -Tsvg"
+  plantuml  a PlantUML entity diagram
+
+<schema> is the name of a built-in or user schema, or the path to a schema
+file (JSON or YAML).`,
+
+	Example: `  # Print a Mermaid diagram to paste into a README
+  sourcebox diagram fintech-loans
+
+  # Render every column with Graphviz
+  sourcebox diagram my-schema.yaml --format=dot --all-columns | dot -Tsvg > schema.svg
+
+  # Write a PlantUML file
+  sourcebox diagram fintech-loans --format=plantuml -o loans.puml`,
+
+	Args: cobra.ExactArgs(1),
+	RunE: runDiagram,
+}
+
+func runDiagram(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	allColumns, _ := cmd.Flags().GetBool("all-columns")
+	output, _ := cmd.Flags().GetString("output")
+
+	opts := diagram.Options{Format: diagram.Format(strings.ToLower(format)), AllColumns: allColumns}
+	if !containsFormat(diagramFormats(), string(opts.Format)) {
+		return fmt.Errorf("unsupported format '%s': must be one of: %s", format, strings.Join(diagramFormats(), ", "))
+	}
+
+	s, err := loadSchemaArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := diagram.Write(&buf, s, opts); err != nil {
+		return fmt.Errorf("failed to draw diagram: %w", err)
+	}
+
+	if output == "" || output == "-" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if !quiet {
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s (%s)\n", output, opts.Format)
+	}
+	return nil
+}
+
+// diagramFormats lists the supported --format values.
+func diagramFormats() []string {
+	formats := make([]string, len(diagram.Formats))
+	for i, f := range diagram.Formats {
+		formats[i] = string(f)
+	}
+	return formats
+}
+
+func init() {
+	rootCmd.AddCommand(diagramCmd)
+
+	diagramCmd.Flags().String("format", string(diagram.Mermaid), "diagram format: "+strings.Join(diagramFormats(), ", "))
+	diagramCmd.Flags().Bool("all-columns", false, "draw every column, not only key columns")
+	diagramCmd.Flags().StringP("output", "o", "", "write to this file instead of stdout")
+}
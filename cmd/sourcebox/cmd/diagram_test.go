@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagramCommand(t *testing.T) {
+	output, _, err := executeCommand(t, "", "diagram", "fintech-loans")
+	require.NoError(t, err)
+	assert.Contains(t, output, "erDiagram\n    borrowers {\n        int id PK\n")
+	assert.Contains(t, output, "    borrowers ||--o{ loans : \"borrower_id\"\n")
+	assert.NotContains(t, output, "credit_score")
+
+	output, _, err = executeCommand(t, "", "diagram", exampleSchemaPath, "--format=DOT", "--all-columns")
+	require.NoError(t, err)
+	assert.Contains(t, output, `digraph "fintech-loans" {`)
+	assert.Contains(t, output, `port="credit_score"`)
+	assert.Contains(t, output, `"payments":"loan_id" -> "loans":"id"`)
+}
+
+func TestDiagramCommandOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.puml")
+	output, _, err := executeCommand(t, "", "diagram", "fintech-loans", "--format=plantuml", "-o", path)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Wrote "+path+" (plantuml)")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "@startuml\n")
+	assert.Contains(t, string(data), "loans ||--o{ payments : loan_id\n")
+}
+
+func TestDiagramCommandErrors(t *testing.T) {
+	_, _, err := executeCommand(t, "", "diagram", "fintech-loans", "--format=svg")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format 'svg': must be one of: mermaid, dot, plantuml")
+
+	_, _, err = executeCommand(t, "", "diagram", "no-such-schema")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no-such-schema")
+}
@@ -0,0 +1,242 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jbeausoleil/sourcebox/pkg/ddl"
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/sample"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// importCmd groups the commands that build schemas from existing sources.
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create a schema from an existing database definition",
+	Long: `Create a SourceBox schema from something you already have, so you do
+not have to write it by hand: a database's DDL, or sample data in CSV.
+
+The schema is validated before it is written. Review it afterwards: the
+generators and keys are educated guesses.`,
+}
+
+// importDDLCmd represents the import ddl command
+var importDDLCmd = &cobra.Command{
+	Use:   "ddl <file>...",
+	Short: "Create a schema from MySQL or PostgreSQL DDL",
+	Long: `Create a schema from SQL DDL scripts, such as the output of
+"mysqldump --no-data" or "pg_dump --schema-only".
+
+CREATE TABLE, CREATE INDEX, ALTER TABLE, CREATE TYPE ... AS ENUM and
+COMMENT ON statements are read; anything else is skipped. Scripts are read
+in order, so a later file can add foreign keys to tables an earlier one
+created. Use "-" to read a script from stdin.
+
+The importer fills in types, nullability, defaults, keys and indexes, and
+derives generation_order from the foreign keys. Generators are chosen from
+column names and types: an email column gets the email generator,
+created_at a past timestamp, and so on. Columns it has no guess for use
+the default generator for their type.
+
+Anything that could not be imported exactly, such as a composite primary
+key or an unsupported column type, is reported as a warning.
+
+The dialect is detected from the scripts unless --dialect is given. The
+schema is written as JSON, or as YAML when --output ends in .yaml or .yml.`,
+
+	Example: `  # Import a MySQL dump
+  mysqldump --no-data shop > shop.sql
+  sourcebox import ddl shop.sql -o shop.json
+
+  # Import a PostgreSQL schema straight from pg_dump
+  pg_dump --schema-only shop | sourcebox import ddl - --name=shop -o shop.yaml
+
+  # Import tables and their foreign keys from separate files
+  sourcebox import ddl tables.sql constraints.sql --records=1000`,
+
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runImportDDL,
+}
+
+func runImportDDL(cmd *cobra.Command, args []string) error {
+	dialectName, _ := cmd.Flags().GetString("dialect")
+	name, _ := cmd.Flags().GetString("name")
+	records, _ := cmd.Flags().GetInt("records")
+	output, _ := cmd.Flags().GetString("output")
+
+	if records <= 0 {
+		return fmt.Errorf("--records must be greater than 0, got %d", records)
+	}
+
+	opts := ddl.ImportOptions{Name: name, RecordCount: records}
+	if dialectName != "" {
+		d, err := dialect.For(dialectName)
+		if err != nil {
+			return err
+		}
+		opts.Dialect = d
+	}
+	if opts.Name == "" && args[0] != "-" {
+		opts.Name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+	}
+
+	scripts := make([]ddl.Script, 0, len(args))
+	for _, path := range args {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+			path = "<stdin>"
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read DDL: %w", err)
+		}
+		scripts = append(scripts, ddl.Script{Name: path, Text: string(data)})
+	}
+
+	s, warnings, err := ddl.Import(scripts, opts)
+	if err != nil {
+		return withExcerpt(err)
+	}
+	if !quiet {
+		writeDiagnostics(cmd.ErrOrStderr(), warnings)
+	}
+
+	return writeImportedSchema(cmd, s, output)
+}
+
+// importCSVCmd represents the import csv command
+var importCSVCmd = &cobra.Command{
+	Use:   "csv <file>...",
+	Short: "Create a schema from sample CSV data",
+	Long: `Create a schema that generates more data like a sample: each CSV file
+becomes a table named after the file, with a column for each header.
+
+Column types are inferred from the values. A column with a value in every
+row and no value twice becomes the primary key (an id column is added to
+tables without one), and a column whose values all appear in another
+file's primary key, and whose name refers to that table, becomes a foreign
+key.
+
+Generators are fitted to the sample:
+  numbers      their min and max, with a normal or lognormal distribution
+  categories   columns with few distinct values pick them by observed
+               frequency with the weighted generator
+  dates        date_between over the observed dates
+  booleans     the observed true rate
+  text         email, names, phone numbers and so on, guessed from the
+               values and the column name
+Empty cells and NULL, null and \N are missing values; columns with any
+become nullable with the observed null_rate.
+
+Each table's record_count is its number of rows, so "seed --records"
+scales the tables in the sample's proportions. The schema targets both
+MySQL and PostgreSQL and is written as JSON, or as YAML when --output ends
+in .yaml or .yml.`,
+
+	Example: `  # Turn an export into a schema and seed a database with it
+  sourcebox import csv customers.csv orders.csv -o shop.yaml
+  sourcebox seed postgres --schema=shop.yaml --records=10000
+
+  # Read semicolon-separated files
+  sourcebox import csv export/*.csv --delimiter=';' --name=crm`,
+
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runImportCSV,
+}
+
+func runImportCSV(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	delimiter, _ := cmd.Flags().GetString("delimiter")
+	output, _ := cmd.Flags().GetString("output")
+
+	opts := sample.Options{Name: name}
+	switch delimiter {
+	case "":
+	case "tab", `\t`:
+		opts.Comma = '\t'
+	default:
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' {
+			return fmt.Errorf("invalid delimiter '%s': must be a single character other than a quote or newline, or \"tab\"", delimiter)
+		}
+		opts.Comma = r
+	}
+
+	files := make([]sample.File, 0, len(args))
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		files = append(files, sample.File{Name: path, Data: data})
+	}
+
+	s, warnings, err := sample.FromCSV(files, opts)
+	if err != nil {
+		return withExcerpt(err)
+	}
+	if !quiet {
+		writeDiagnostics(cmd.ErrOrStderr(), warnings)
+	}
+
+	return writeImportedSchema(cmd, s, output)
+}
+
+// writeImportedSchema validates s and writes it to output, or to stdout
+// as JSON when output is empty or "-".
+func writeImportedSchema(cmd *cobra.Command, s *schema.Schema, output string) error {
+	if err := schema.Validate(s).Err(); err != nil {
+		return fmt.Errorf("imported schema is not valid: %w", withExcerpt(err))
+	}
+
+	format := schema.FormatJSON
+	if output != "" && output != "-" {
+		format = schema.FormatOf(output)
+	}
+	data, err := schema.Marshal(s, format)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	if output == "" || output == "-" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if !quiet {
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s (%d %s, %s)\n", output, len(s.Tables), plural(len(s.Tables), "table", "tables"), strings.Join(s.DatabaseType, ", "))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.AddCommand(importDDLCmd)
+
+	importDDLCmd.Flags().String("dialect", "", "SQL dialect of the scripts: mysql or postgres (default: detect)")
+	importDDLCmd.Flags().String("name", "", "schema name (default: the first file's name)")
+	importDDLCmd.Flags().Int("records", ddl.DefaultRecordCount, "record_count for every table")
+	importDDLCmd.Flags().StringP("output", "o", "", "write the schema to this file instead of stdout")
+
+	importCmd.AddCommand(importCSVCmd)
+	importCSVCmd.Flags().String("name", "", "schema name (default \"imported\")")
+	importCSVCmd.Flags().String("delimiter", "", "field delimiter, or \"tab\" (default: tab for .tsv files, comma otherwise)")
+	importCSVCmd.Flags().StringP("output", "o", "", "write the schema to this file instead of stdout")
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shopDDL = "CREATE TABLE `customers` (\n" +
+	"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(255) NOT NULL,\n" +
+	"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `uq_email` (`email`)\n" +
+	") ENGINE=InnoDB;\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+	"  `customer_id` int NOT NULL,\n" +
+	"  `shape` geometry,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  CONSTRAINT `fk_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`) ON DELETE CASCADE\n" +
+	") ENGINE=InnoDB;\n"
+
+func TestImportDDLCommand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shop.sql")
+	require.NoError(t, os.WriteFile(path, []byte(shopDDL), 0o644))
+
+	output := filepath.Join(dir, "shop.yaml")
+	stdout, stderr, err := executeCommand(t, "", "import", "ddl", path, "--records=50", "-o", output)
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Wrote "+output+" (2 tables, mysql)")
+	assert.Contains(t, stderr, "WARNING: table 'orders': column 'shape': type geometry has no schema equivalent")
+	assert.Contains(t, stderr, "shop.sql:11:")
+
+	s, err := schema.LoadSchema(output)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", s.Name)
+	assert.Equal(t, []string{"customers", "orders"}, s.GenerationOrder)
+	assert.Equal(t, 50, s.Tables[0].RecordCount)
+	assert.Equal(t, "email", s.Table("customers").Column("email").Generator)
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "unique: false", "zero values are left out")
+}
+
+func TestImportDDLCommandStdin(t *testing.T) {
+	stdout, _, err := executeCommand(t, "CREATE TABLE t (id SERIAL PRIMARY KEY, email TEXT NOT NULL);", "import", "ddl", "-", "--name=tiny")
+	require.NoError(t, err)
+
+	s, err := schema.ParseSchema(strings.NewReader(stdout))
+	require.NoError(t, err, "stdout is a JSON schema")
+	assert.Equal(t, "tiny", s.Name)
+	assert.Equal(t, []string{"postgres"}, s.DatabaseType)
+	assert.True(t, s.Tables[0].Columns[0].AutoIncrement)
+}
+
+func TestImportDDLCommandErrors(t *testing.T) {
+	_, _, err := executeCommand(t, "CREATE TABLE t (\n  id int,\n  name varchar(10\n);", "import", "ddl", "-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unbalanced parenthesis")
+	assert.Contains(t, err.Error(), "<stdin>:1:16")
+
+	_, _, err = executeCommand(t, "CREATE TABLE t (name text);", "import", "ddl", "-")
+	require.Error(t, err, "a table without a primary key cannot be written")
+	assert.Contains(t, err.Error(), "imported schema is not valid")
+
+	_, _, err = executeCommand(t, "", "import", "ddl", "-", "--dialect=oracle")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported database 'oracle'")
+
+	_, _, err = executeCommand(t, "", "import", "ddl", "-", "--records=0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--records must be greater than 0")
+}
+
+func TestImportCSVCommand(t *testing.T) {
+	dir := t.TempDir()
+	customers := filepath.Join(dir, "customers.csv")
+	orders := filepath.Join(dir, "orders.csv")
+	require.NoError(t, os.WriteFile(customers, []byte("id;email;plan\n1;a@example.com;free\n2;b@example.com;pro\n3;c@example.com;free\n4;d@example.com;free\n"), 0o644))
+	require.NoError(t, os.WriteFile(orders, []byte("amount;note\n9.50;\n12.00;gift\n"), 0o644))
+
+	output := filepath.Join(dir, "shop.yaml")
+	stdout, stderr, err := executeCommand(t, "", "import", "csv", orders, customers, "--delimiter=;", "--name=shop", "-o", output)
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Wrote "+output+" (2 tables, mysql, postgres)")
+	assert.Contains(t, stderr, "WARNING: table 'orders' has no column with a distinct value in every row; added primary key 'id'")
+
+	s, err := schema.LoadSchema(output)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", s.Name)
+	assert.Equal(t, 4, s.Table("customers").RecordCount)
+	assert.Equal(t, "weighted", s.Table("customers").Column("plan").Generator)
+	assert.Equal(t, 0.5, s.Table("orders").Column("note").GeneratorParams["null_rate"])
+}
+
+func TestImportCSVCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,name\n1,\"unterminated\n"), 0o644))
+
+	_, _, err := executeCommand(t, "", "import", "csv", path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.csv:2:")
+
+	_, _, err = executeCommand(t, "", "import", "csv", path, "--delimiter=;;")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid delimiter ';;'")
+
+	_, _, err = executeCommand(t, "", "import", "csv", filepath.Join(dir, "missing.csv"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CSV")
+}
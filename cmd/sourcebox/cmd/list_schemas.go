@@ -4,11 +4,22 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/jbeausoleil/sourcebox/pkg/registry"
+	"github.com/jbeausoleil/sourcebox/schemas"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// listFormats lists the supported --format values.
+var listFormats = []string{"table", "json", "yaml"}
+
 // listSchemasCmd represents the list-schemas command
 var listSchemasCmd = &cobra.Command{
 	Use:     "list-schemas",
@@ -20,23 +31,113 @@ SourceBox provides industry-specific schemas for fintech, healthcare,
 retail, and other verticals. Each schema includes realistic field
 distributions, relationships, and edge cases.
 
-Schemas are categorized by industry and use case.`,
+Schemas are categorized by industry and use case. Built-in schemas are
+always available; add your own by placing schema files (JSON or YAML) in
+~/.sourcebox/schemas or in any directory listed in $SOURCEBOX_SCHEMA_PATH.`,
 
 	Example: `  # List all available schemas
   sourcebox list-schemas
 
   # Using short alias
-  sourcebox ls`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Fprintln(cmd.OutOrStdout(), "List-schemas command - implementation coming in F022")
-		fmt.Fprintln(cmd.OutOrStdout(), "Available schemas:")
-		fmt.Fprintln(cmd.OutOrStdout(), "  - fintech-loans")
-		fmt.Fprintln(cmd.OutOrStdout(), "  - healthcare-patients")
-		fmt.Fprintln(cmd.OutOrStdout(), "  - retail-orders")
-	},
+  sourcebox ls
+
+  # Only fintech schemas tagged "loans"
+  sourcebox ls --industry=fintech --tag=loans
+
+  # Machine-readable output for scripts
+  sourcebox list-schemas --format=json`,
+
+	RunE: runListSchemas,
+}
+
+// loadRegistry discovers the built-in and user schemas. It is a variable so
+// tests can substitute their own schema set.
+var loadRegistry = func() *registry.Registry {
+	return registry.Load(schemas.FS, registry.DefaultDirs())
+}
+
+// addListSchemasFlags declares the list-schemas flags on cmd.
+func addListSchemasFlags(cmd *cobra.Command) {
+	cmd.Flags().String("industry", "", "only list schemas for this industry (e.g. fintech)")
+	cmd.Flags().String("tag", "", "only list schemas with this tag")
+	cmd.Flags().Int("tier", 0, "only list schemas of this complexity tier")
+	cmd.Flags().String("format", "table", "output format: "+strings.Join(listFormats, ", "))
+}
+
+func runListSchemas(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	var filter registry.Filter
+	filter.Industry, _ = flags.GetString("industry")
+	filter.Tag, _ = flags.GetString("tag")
+	filter.Tier, _ = flags.GetInt("tier")
+	format, _ := flags.GetString("format")
+
+	if filter.Tier < 0 {
+		return fmt.Errorf("--tier must be a positive number, got %d", filter.Tier)
+	}
+
+	reg := loadRegistry()
+	if !quiet {
+		for _, w := range reg.Warnings() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", w)
+		}
+	}
+
+	entries := reg.Filter(filter)
+	if entries == nil {
+		entries = []registry.Entry{}
+	}
+
+	out := cmd.OutOrStdout()
+	switch strings.ToLower(format) {
+	case "table":
+		return writeSchemaTable(out, entries)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "yaml":
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format '%s': must be one of: %s", format, strings.Join(listFormats, ", "))
+	}
+}
+
+// writeSchemaTable prints entries as an aligned table. The source column is
+// shown with --verbose.
+func writeSchemaTable(out io.Writer, entries []registry.Entry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No schemas match the given filters.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := "NAME\tINDUSTRY\tTABLES\tRECORDS\tTIER\tTAGS"
+	if verbose {
+		header += "\tSOURCE"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, e := range entries {
+		tier := "-"
+		if e.ComplexityTier > 0 {
+			tier = strconv.Itoa(e.ComplexityTier)
+		}
+		line := fmt.Sprintf("%s\t%s\t%d\t%d\t%s\t%s", e.Name, e.Industry, e.Tables, e.TotalRecords, tier, strings.Join(e.Tags, ", "))
+		if verbose {
+			line += "\t" + e.Source
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
 }
 
 func init() {
 	rootCmd.AddCommand(listSchemasCmd)
+	addListSchemasFlags(listSchemasCmd)
 }
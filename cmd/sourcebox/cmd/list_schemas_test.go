@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/fs"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/jbeausoleil/sourcebox/pkg/registry"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,6 +43,7 @@ func getTestRootCommand() *cobra.Command {
 		Run:     listSchemasCmd.Run,
 		RunE:    listSchemasCmd.RunE,
 	}
+	addListSchemasFlags(listCmd)
 
 	// Add the list-schemas command
 	cmd.AddCommand(listCmd)
@@ -78,7 +84,7 @@ func TestListSchemasCommandAlias(t *testing.T) {
 	require.NoError(t, err, "ls alias should execute without error")
 
 	output := buf.String()
-	assert.Contains(t, output, "Available schemas:", "ls alias should produce same output as list-schemas")
+	assert.Contains(t, output, "fintech-loans", "ls alias should produce same output as list-schemas")
 }
 
 // TestListSchemasCommandHelp verifies that the list-schemas command has
@@ -117,7 +123,7 @@ func TestListSchemasCommandHelp(t *testing.T) {
 }
 
 // TestListSchemasCommandExecution verifies that the command executes and
-// produces expected placeholder output.
+// lists the built-in schemas.
 func TestListSchemasCommandExecution(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -128,9 +134,8 @@ func TestListSchemasCommandExecution(t *testing.T) {
 			name: "list-schemas command",
 			args: []string{"list-schemas"},
 			expectedInOutput: []string{
-				"List-schemas command",
-				"implementation coming in F022",
-				"Available schemas:",
+				"NAME",
+				"INDUSTRY",
 				"fintech-loans",
 				"healthcare-patients",
 				"retail-orders",
@@ -140,9 +145,8 @@ func TestListSchemasCommandExecution(t *testing.T) {
 			name: "ls alias",
 			args: []string{"ls"},
 			expectedInOutput: []string{
-				"List-schemas command",
-				"implementation coming in F022",
-				"Available schemas:",
+				"NAME",
+				"INDUSTRY",
 				"fintech-loans",
 				"healthcare-patients",
 				"retail-orders",
@@ -169,11 +173,24 @@ func TestListSchemasCommandExecution(t *testing.T) {
 	}
 }
 
-// TestListSchemasCommandNoFlags verifies that list-schemas has no local flags.
-func TestListSchemasCommandNoFlags(t *testing.T) {
-	// list-schemas should not have any local flags
-	localFlags := listSchemasCmd.LocalFlags()
-	assert.Equal(t, 0, localFlags.NFlag(), "list-schemas should have no local flags")
+// TestListSchemasCommandFlags verifies the filter and format flags and their
+// defaults.
+func TestListSchemasCommandFlags(t *testing.T) {
+	flags := []struct {
+		name     string
+		defValue string
+	}{
+		{"industry", ""},
+		{"tag", ""},
+		{"tier", "0"},
+		{"format", "table"},
+	}
+
+	for _, f := range flags {
+		flag := listSchemasCmd.Flags().Lookup(f.name)
+		require.NotNil(t, flag, "list-schemas should have --%s", f.name)
+		assert.Equal(t, f.defValue, flag.DefValue, "--%s default", f.name)
+	}
 }
 
 // TestListSchemasCommandNoArguments verifies that list-schemas accepts no arguments.
@@ -318,9 +335,8 @@ func TestListSchemasVsLsAlias(t *testing.T) {
 	assert.Equal(t, output1, output2, "list-schemas and ls should produce identical output")
 }
 
-// TestListSchemasPlaceholderContent verifies the specific content of the
-// placeholder output.
-func TestListSchemasPlaceholderContent(t *testing.T) {
+// TestListSchemasTableContent verifies the layout of the default table output.
+func TestListSchemasTableContent(t *testing.T) {
 	buf := new(bytes.Buffer)
 	cmd := getTestRootCommand()
 	cmd.SetOut(buf)
@@ -334,21 +350,149 @@ func TestListSchemasPlaceholderContent(t *testing.T) {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 
 	// Verify output structure
-	require.GreaterOrEqual(t, len(lines), 5, "Output should have at least 5 lines")
+	require.GreaterOrEqual(t, len(lines), 2, "Output should have a header and at least one schema")
+
+	// First line is the column header
+	assert.Equal(t, []string{"NAME", "INDUSTRY", "TABLES", "RECORDS", "TIER", "TAGS"}, strings.Fields(lines[0]))
+
+	// Built-in fintech schema with its metadata
+	var fintech string
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "fintech-loans ") {
+			fintech = line
+		}
+	}
+	require.NotEmpty(t, fintech, "Output should list fintech-loans schema")
+	assert.Equal(t, []string{"fintech-loans", "fintech", "3", "16200", "1", "loans,", "credit,", "borrowers,", "payments"}, strings.Fields(fintech))
+}
+
+// stubRegistry makes list-schemas see only the schemas in builtin.
+func stubRegistry(t *testing.T, builtin fs.FS) {
+	t.Helper()
+	original := loadRegistry
+	loadRegistry = func() *registry.Registry { return registry.Load(builtin, nil) }
+	t.Cleanup(func() { loadRegistry = original })
+}
+
+// testSchemaJSON returns a minimal valid schema document with the given
+// metadata.
+func testSchemaJSON(name, industry string, tier int, tag string) []byte {
+	return []byte(`{
+  "schema_version": "1.0",
+  "name": "` + name + `",
+  "description": "Test schema ` + name + `",
+  "author": "Tests",
+  "version": "1.0.0",
+  "database_type": ["mysql"],
+  "metadata": {"industry": "` + industry + `", "tags": ["` + tag + `"], "total_records": 10, "complexity_tier": ` + strconv.Itoa(tier) + `},
+  "tables": [{"name": "t", "record_count": 10, "columns": [{"name": "id", "type": "int", "primary_key": true}]}],
+  "generation_order": ["t"]
+}`)
+}
+
+// TestListSchemasFilters verifies --industry, --tag and --tier.
+func TestListSchemasFilters(t *testing.T) {
+	stubRegistry(t, fstest.MapFS{
+		"a.json": {Data: testSchemaJSON("alpha", "fintech", 1, "loans")},
+		"b.json": {Data: testSchemaJSON("bravo", "fintech", 2, "cards")},
+		"c.json": {Data: testSchemaJSON("charlie", "healthcare", 2, "patients")},
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"no filters", nil, []string{"alpha", "bravo", "charlie"}},
+		{"industry", []string{"--industry=fintech"}, []string{"alpha", "bravo"}},
+		{"industry is case-insensitive", []string{"--industry=Healthcare"}, []string{"charlie"}},
+		{"tag", []string{"--tag=cards"}, []string{"bravo"}},
+		{"tier", []string{"--tier=2"}, []string{"bravo", "charlie"}},
+		{"combined", []string{"--industry=fintech", "--tier=1"}, []string{"alpha"}},
+		{"no match", []string{"--tag=nope"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			cmd := getTestRootCommand()
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			cmd.SetArgs(append([]string{"list-schemas"}, tt.args...))
+
+			require.NoError(t, cmd.Execute())
 
-	// First line should be the implementation notice
-	assert.Contains(t, lines[0], "List-schemas command", "First line should mention the command")
-	assert.Contains(t, lines[0], "F022", "First line should mention F022")
+			var names []string
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			for _, line := range lines[1:] {
+				names = append(names, strings.Fields(line)[0])
+			}
+			if tt.expected == nil {
+				assert.Equal(t, "No schemas match the given filters.", lines[0])
+				return
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
 
-	// Second line should introduce available schemas
-	assert.Contains(t, lines[1], "Available schemas:", "Second line should introduce schemas")
+// TestListSchemasFormats verifies the machine-readable output formats.
+func TestListSchemasFormats(t *testing.T) {
+	stubRegistry(t, fstest.MapFS{
+		"a.json": {Data: testSchemaJSON("alpha", "fintech", 1, "loans")},
+	})
+
+	run := func(args ...string) (string, error) {
+		buf := new(bytes.Buffer)
+		cmd := getTestRootCommand()
+		cmd.SetOut(buf)
+		cmd.SetErr(buf)
+		cmd.SetArgs(append([]string{"list-schemas"}, args...))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
 
-	// Verify all three schemas are listed
-	schemaLines := lines[2:]
-	schemaText := strings.Join(schemaLines, "\n")
-	assert.Contains(t, schemaText, "fintech-loans", "Output should list fintech-loans schema")
-	assert.Contains(t, schemaText, "healthcare-patients", "Output should list healthcare-patients schema")
-	assert.Contains(t, schemaText, "retail-orders", "Output should list retail-orders schema")
+	t.Run("json", func(t *testing.T) {
+		output, err := run("--format=json")
+		require.NoError(t, err)
+
+		var entries []map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &entries), "Output should be valid JSON")
+		require.Len(t, entries, 1)
+		assert.Equal(t, "alpha", entries[0]["name"])
+		assert.Equal(t, "fintech", entries[0]["industry"])
+		assert.Equal(t, []interface{}{"loans"}, entries[0]["tags"])
+		assert.Equal(t, 1.0, entries[0]["tables"])
+		assert.Equal(t, 10.0, entries[0]["total_records"])
+		assert.Equal(t, 1.0, entries[0]["complexity_tier"])
+		assert.Equal(t, "built-in", entries[0]["source"])
+	})
+
+	t.Run("json with no matches", func(t *testing.T) {
+		output, err := run("--format=json", "--industry=retail")
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", output, "No matches should be an empty array, not null")
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		output, err := run("--format=yaml")
+		require.NoError(t, err)
+		assert.Contains(t, output, "- name: alpha\n")
+		assert.Contains(t, output, "  industry: fintech\n")
+		assert.Contains(t, output, "  complexity_tier: 1\n")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := run("--format=xml")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported format 'xml': must be one of: table, json, yaml")
+	})
+
+	t.Run("negative tier", func(t *testing.T) {
+		_, err := run("--tier=-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--tier must be a positive number")
+	})
 }
 
 // TestListSchemasCommandStructure verifies the command structure matches spec.
@@ -371,8 +515,8 @@ func TestListSchemasCommandStructure(t *testing.T) {
 	// Verify Example exists
 	assert.NotEmpty(t, listSchemasCmd.Example, "Example should not be empty")
 
-	// Verify Run function is set
-	assert.NotNil(t, listSchemasCmd.Run, "Run function should be set")
+	// Verify RunE function is set
+	assert.NotNil(t, listSchemasCmd.RunE, "RunE function should be set")
 }
 
 // TestListSchemasHelpVerbose verifies that verbose help includes all details.
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +19,32 @@ func resetGlobalFlags() {
 	cfgFile = ""
 }
 
+// executeCommand runs "sourcebox <args>" with stdin as its standard input and
+// returns its standard output and error output. The flags of the command it
+// runs are reset to their defaults before and after, so values set by one
+// test do not leak into the next.
+func executeCommand(t *testing.T, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd, _, err := rootCmd.Find(args)
+	require.NoError(t, err)
+	reset := func() {
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		})
+	}
+	reset()
+	t.Cleanup(reset)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
 // TestSetVersion verifies that SetVersion correctly sets the version
 // on the root command.
 func TestSetVersion(t *testing.T) {
@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/registry"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd groups the commands that work on schema files.
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Work with schema files",
+	Long: `Work with SourceBox schema files.
+
+Schemas can be written in JSON or YAML (.yaml or .yml). Both formats
+accept exactly the same fields and are validated the same way; YAML also
+allows comments.`,
+}
+
+// schemaConvertCmd represents the schema convert command
+var schemaConvertCmd = &cobra.Command{
+	Use:   "convert <schema>",
+	Short: "Convert a schema between JSON and YAML",
+	Long: `Convert a schema between JSON and YAML.
+
+The schema is a built-in schema name (see 'sourcebox list-schemas') or the
+path to a schema file. It is validated first, so only working schemas are
+converted. A file's keys keep their order; comments are dropped when
+converting YAML to JSON. Built-in schemas are written out from their
+definition.
+
+The target format defaults to the other format from the input's, or to
+the format of --output's extension when one is given.`,
+
+	Example: `  # Print a JSON schema as YAML
+  sourcebox schema convert loans.json
+
+  # Start a custom schema from a built-in one
+  sourcebox schema convert fintech-loans --output=my-loans.yaml
+
+  # Convert YAML back to JSON in a file
+  sourcebox schema convert loans.yaml --output=loans.json`,
+
+	Args: cobra.ExactArgs(1),
+	RunE: runSchemaConvert,
+}
+
+func runSchemaConvert(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	output, _ := cmd.Flags().GetString("output")
+	to, _ := cmd.Flags().GetString("to")
+
+	s, err := loadSchemaArg(name)
+	if err != nil {
+		return err
+	}
+
+	// Names resolve to the file the schema came from, if it has one
+	path := name
+	if entry, ok := loadRegistry().Get(name); ok {
+		path = entry.Source
+	}
+
+	from := schema.FormatOf(path)
+	target, err := convertTarget(from, to, output)
+	if err != nil {
+		return err
+	}
+
+	var converted []byte
+	if path == registry.BuiltinSource {
+		converted, err = schema.Marshal(s, target)
+	} else {
+		var data []byte
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read schema: %w", err)
+		}
+		converted, err = schema.Convert(data, from, target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w", name, err)
+	}
+
+	if output == "" || output == "-" {
+		_, err := cmd.OutOrStdout().Write(converted)
+		return err
+	}
+	if err := os.WriteFile(output, converted, 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if !quiet {
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s (%s)\n", output, target)
+	}
+	return nil
+}
+
+// convertTarget picks the output format: --to when given, else the
+// extension of --output, else the format the input is not in.
+func convertTarget(from schema.Format, to, output string) (schema.Format, error) {
+	switch strings.ToLower(to) {
+	case "json":
+		return schema.FormatJSON, nil
+	case "yaml", "yml":
+		return schema.FormatYAML, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format '%s': must be one of: json, yaml", to)
+	}
+
+	if output != "" && output != "-" {
+		return schema.FormatOf(output), nil
+	}
+	if from == schema.FormatYAML {
+		return schema.FormatJSON, nil
+	}
+	return schema.FormatYAML, nil
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaConvertCmd)
+
+	schemaConvertCmd.Flags().String("to", "", "output format: json or yaml (default: the other format)")
+	schemaConvertCmd.Flags().StringP("output", "o", "", "write to this file instead of stdout")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaConvertCommand(t *testing.T) {
+	original, err := schema.LoadSchema(exampleSchemaPath)
+	require.NoError(t, err)
+
+	output, _, err := executeCommand(t, "", "schema", "convert", exampleSchemaPath)
+	require.NoError(t, err)
+	assert.Contains(t, output, "name: fintech-loans\n")
+
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "loans.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("# converted\n"+output), 0o644))
+	fromYAML, err := schema.LoadSchema(yamlPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, fromYAML)
+
+	jsonPath := filepath.Join(dir, "loans.json")
+	output, _, err = executeCommand(t, "", "schema", "convert", yamlPath, "--output="+jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Wrote "+jsonPath+" (json)\n", output)
+	roundTrip, err := schema.LoadSchema(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, roundTrip)
+
+	output, _, err = executeCommand(t, "", "schema", "convert", exampleSchemaPath, "--to=json")
+	require.NoError(t, err)
+	assert.Contains(t, output, "\"name\": \"fintech-loans\",\n")
+}
+
+func TestSchemaConvertCommandBuiltin(t *testing.T) {
+	original, err := loadSchemaArg("retail-orders")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "orders.yaml")
+	output, _, err := executeCommand(t, "", "schema", "convert", "retail-orders", "--output="+path)
+	require.NoError(t, err, "built-in schemas should resolve by name")
+	assert.Equal(t, "Wrote "+path+" (yaml)\n", output)
+
+	converted, err := schema.LoadSchema(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, converted)
+}
+
+func TestSchemaConvertCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("name: broken\ndatabase_type: [mysql]\ntables: []\ncolour: blue\n"), 0o644))
+
+	_, _, err := executeCommand(t, "", "schema", "convert", broken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 4, column 1: json: unknown field "colour"`)
+	assert.Contains(t, err.Error(), "4 | colour: blue\n  | ^")
+
+	_, _, err = executeCommand(t, "", "schema", "convert", exampleSchemaPath, "--to=toml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format 'toml': must be one of: json, yaml")
+
+	_, _, err = executeCommand(t, "", "schema", "convert", filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found: use a schema name from 'sourcebox list-schemas' or the path to a schema file")
+}
@@ -5,8 +5,25 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/export"
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/jbeausoleil/sourcebox/pkg/seeder"
+	"github.com/jbeausoleil/sourcebox/pkg/verify"
+	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
@@ -18,10 +35,29 @@ var seedCmd = &cobra.Command{
 
 SourceBox generates realistic data based on industry-specific schemas
 (fintech, healthcare, retail) with proper relationships, distributions,
-and edge cases. Data is deterministic and reproducible.
+and edge cases. Data is deterministic and reproducible: running again with
+the same schema, --records and --seed produces identical output. Without
+--seed, seed 0 is used; pass a different seed for different data.
+
+Tables are created in the target database, which must not already contain
+them, and filled in generation order using batched INSERTs, one
+transaction per table. --records sets the total number of rows; each table
+keeps its share of the schema's record counts. Tables sized by a foreign
+key cardinality follow their parents, so the others are scaled down to
+leave room for them and the total comes out close to --records; small
+runs vary more, since cardinalities are drawn at random.
+
+The schema's validation_rules are checked against the generated data
+before anything is written, and against the database after seeding.
+Rules with severity "error" fail the run; "warning" rules are reported.
+
+With --output the data is written to a self-contained SQL script in the
+dialect of <database> instead (use --output=- for stdout). Load it with
+"mysql demo < file.sql" or "psql -d demo -f file.sql".
 
 Supported databases: mysql, postgres
-Supported schemas: fintech-loans, healthcare-patients, retail-orders`,
+Supported schemas: fintech-loans, healthcare-patients, retail-orders
+(built into the binary; --schema also accepts the path to a schema file)`,
 
 	Example: `  # Seed MySQL with 1000 fintech loan records
   sourcebox seed mysql --schema=fintech-loans --records=1000
@@ -30,19 +66,378 @@ Supported schemas: fintech-loans, healthcare-patients, retail-orders`,
   sourcebox seed postgres --schema=healthcare-patients --records=5000
 
   # Export to SQL file instead of inserting
-  sourcebox seed mysql --schema=fintech-loans --output=loans.sql`,
+  sourcebox seed mysql --schema=fintech-loans --output=loans.sql
+
+  # Reproduce a previous run exactly
+  sourcebox seed mysql --schema=fintech-loans --seed=42`,
 
 	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Fprintln(cmd.OutOrStdout(), "Seed command - implementation coming in F021")
-		fmt.Fprintf(cmd.OutOrStdout(), "  Database: %s\n", args[0])
-		schema, _ := cmd.Flags().GetString("schema")
-		records, _ := cmd.Flags().GetInt("records")
-		fmt.Fprintf(cmd.OutOrStdout(), "  Schema: %s\n", schema)
-		fmt.Fprintf(cmd.OutOrStdout(), "  Records: %d\n", records)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := seedOptionsFromFlags(cmd, args)
+		if err != nil {
+			return err
+		}
+		// The flags are fine, so a failure from here on is not a usage error
+		cmd.SilenceUsage = true
+		return seedRunner(cmd, opts)
 	},
 }
 
+// seedOptions holds the parsed arguments and flags of a seed invocation.
+type seedOptions struct {
+	database string
+	schema   string
+	records  int
+	conn     dialect.ConnConfig
+	output   string
+	dryRun   bool
+	seed     int64
+}
+
+// seedRunner performs a seed run. It is a variable so tests can exercise
+// flag handling without a database server.
+var seedRunner = runSeed
+
+// seedOptionsFromFlags collects the seed command's arguments and flags.
+func seedOptionsFromFlags(cmd *cobra.Command, args []string) (seedOptions, error) {
+	flags := cmd.Flags()
+	opts := seedOptions{database: args[0]}
+
+	opts.seed, _ = flags.GetInt64("seed")
+	opts.schema, _ = flags.GetString("schema")
+	opts.records, _ = flags.GetInt("records")
+	opts.conn.Host, _ = flags.GetString("host")
+	opts.conn.Port, _ = flags.GetInt("port")
+	opts.conn.User, _ = flags.GetString("user")
+	opts.conn.Password, _ = flags.GetString("password")
+	opts.conn.Database, _ = flags.GetString("db-name")
+	opts.output, _ = flags.GetString("output")
+	opts.dryRun, _ = flags.GetBool("dry-run")
+
+	if opts.records <= 0 {
+		return opts, fmt.Errorf("--records must be greater than 0, got %d", opts.records)
+	}
+	if opts.conn.Port < 0 || opts.conn.Port > 65535 {
+		return opts, fmt.Errorf("--port must be between 1 and 65535 (or 0 to auto-detect), got %d", opts.conn.Port)
+	}
+	return opts, nil
+}
+
+// runSeed loads the schema, generates the dataset and inserts it into the
+// target database, writes it to an SQL file for --output, or only reports
+// the plan for --dry-run.
+func runSeed(cmd *cobra.Command, opts seedOptions) error {
+	d, err := dialect.For(opts.database)
+	if err != nil {
+		return err
+	}
+
+	s, err := loadSeedSchema(opts.schema, d)
+	if err != nil {
+		return err
+	}
+	if err := scaleRecordCounts(s, opts.records, opts.seed); err != nil {
+		return fmt.Errorf("failed to generate data: %w", err)
+	}
+
+	ds, err := generator.Generate(s, generator.Options{Seed: opts.seed})
+	if err != nil {
+		return fmt.Errorf("failed to generate data: %w", err)
+	}
+	report, err := verify.Dataset(s, ds)
+	if err := checkRules(cmd, report, err); err != nil {
+		return fmt.Errorf("generated data does not satisfy the schema: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if quiet {
+		out = io.Discard
+	}
+	target := fmt.Sprintf("%s/%s", net.JoinHostPort(opts.conn.Host, strconv.Itoa(dialect.Port(d, opts.conn))), opts.conn.Database)
+
+	if opts.dryRun {
+		fmt.Fprintln(out, "Dry run - no changes will be made")
+		if opts.output != "" {
+			fmt.Fprintf(out, "  Database: %s\n", d.Name())
+			fmt.Fprintf(out, "  Output: %s\n", opts.output)
+		} else {
+			fmt.Fprintf(out, "  Database: %s (%s)\n", d.Name(), target)
+		}
+		fmt.Fprintf(out, "  Schema: %s\n", s.Name)
+		fmt.Fprintf(out, "  Seed: %d\n", opts.seed)
+		fmt.Fprintln(out, "  Tables:")
+		for _, t := range ds.Tables {
+			fmt.Fprintf(out, "    %-24s %d rows\n", t.Name, len(t.Rows))
+		}
+		return nil
+	}
+
+	if opts.output != "" {
+		return exportSQL(cmd, out, d, s, ds, opts)
+	}
+
+	fmt.Fprintf(out, "Seeding %s (%s) with schema %s\n", d.Name(), target, s.Name)
+	fmt.Fprintf(out, "  Seed: %d\n", opts.seed)
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	db, err := seeder.Connect(ctx, d, opts.conn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	result, err := seeder.New(db, d, seeder.Options{Progress: progressReporter(cmd)}).Seed(ctx, s, ds)
+	if err != nil {
+		return err
+	}
+
+	// Check the rules again against what the database actually stored
+	report, err = verify.Database(ctx, db, d, s)
+	if err := checkRules(cmd, report, err); err != nil {
+		return fmt.Errorf("data was seeded but does not satisfy the schema: %w", err)
+	}
+
+	check := color.New(color.FgGreen).SprintFunc()
+	for _, t := range result.Tables {
+		fmt.Fprintf(out, "  %s %-24s %d rows\n", check("✓"), t.Name, t.Rows)
+	}
+	fmt.Fprintf(out, "Seeded %d rows into %d tables in %s\n",
+		result.TotalRows(), len(result.Tables), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// checkRules reports the outcome of checking the schema's validation rules:
+// warning-severity failures are printed to stderr, and error-severity
+// failures (or a rule that could not be evaluated) are returned.
+func checkRules(cmd *cobra.Command, report *verify.Report, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, f := range report.Warnings() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", f)
+	}
+	return report.Err()
+}
+
+// exportSQL writes the dataset as an SQL script to opts.output, or to stdout
+// when it is "-".
+func exportSQL(cmd *cobra.Command, out io.Writer, d dialect.Dialect, s *schema.Schema, ds *generator.Dataset, opts seedOptions) error {
+	exportOpts := export.Options{Seed: opts.seed}
+
+	if opts.output == "-" {
+		return export.Write(cmd.OutOrStdout(), d, s, ds, exportOpts)
+	}
+
+	f, err := os.Create(opts.output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := export.Write(f, d, s, ds, exportOpts); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	rows := 0
+	for _, t := range ds.Tables {
+		rows += len(t.Rows)
+	}
+	fmt.Fprintf(out, "Wrote %d rows in %d tables to %s (%s, seed %d)\n", rows, len(ds.Tables), opts.output, d.Name(), opts.seed)
+	return nil
+}
+
+// withExcerpt appends a caret excerpt of the offending line to a schema
+// error, so authors can see the problem without searching the file. When
+// validation found several problems, all of them are listed, each with its
+// own excerpt, so they can be fixed in one pass.
+func withExcerpt(err error) error {
+	var list schema.ErrorList
+	if errors.As(err, &list) {
+		return &schemaReportError{err: err, report: formatDiagnostics(list)}
+	}
+
+	var located *schema.Error
+	if errors.As(err, &located) {
+		if excerpt := located.Excerpt(); excerpt != "" {
+			return fmt.Errorf("%w\n%s", err, excerpt)
+		}
+	}
+	return err
+}
+
+// schemaReportError prints a formatted diagnostics report in place of the
+// error's own message while still unwrapping to it.
+type schemaReportError struct {
+	err    error
+	report string
+}
+
+func (e *schemaReportError) Error() string { return e.report }
+
+func (e *schemaReportError) Unwrap() error { return e.err }
+
+// formatDiagnostics renders schema diagnostics for the terminal, one per
+// paragraph: severity, rule code and message, then the source excerpt.
+func formatDiagnostics(list schema.ErrorList) string {
+	errs := len(list.Errors())
+	noun := "errors"
+	if errs == 1 {
+		noun = "error"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema validation failed with %d %s:\n", errs, noun)
+	writeDiagnostics(&b, list)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeDiagnostics writes each diagnostic as a paragraph preceded by a
+// blank line.
+func writeDiagnostics(w io.Writer, list schema.ErrorList) {
+	for _, e := range list {
+		fmt.Fprintf(w, "\n%s", strings.ToUpper(string(e.Severity)))
+		if e.Code != "" {
+			fmt.Fprintf(w, " [%s]", e.Code)
+		}
+		fmt.Fprintf(w, ": %v\n", e.Err)
+		if excerpt := e.Excerpt(); excerpt != "" {
+			fmt.Fprintf(w, "%s\n", excerpt)
+		}
+	}
+}
+
+// loadSeedSchema resolves --schema and checks that the schema supports the
+// target database.
+func loadSeedSchema(name string, d dialect.Dialect) (*schema.Schema, error) {
+	s, err := loadSchemaArg(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, db := range s.DatabaseType {
+		if db == d.Name() {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("schema '%s' does not support database '%s' (supports: %v)", s.Name, d.Name(), s.DatabaseType)
+}
+
+// loadSchemaArg loads the schema a command argument names. Names of
+// built-in (and user directory) schemas are tried first, so a plain
+// sourcebox binary works without any files on disk; anything else is
+// treated as the path to a schema file (JSON or YAML).
+func loadSchemaArg(name string) (*schema.Schema, error) {
+	if entry, ok := loadRegistry().Get(name); ok {
+		return entry.Schema, nil
+	}
+	if _, err := os.Stat(name); err != nil {
+		return nil, fmt.Errorf("schema '%s' not found: use a schema name from 'sourcebox list-schemas' or the path to a schema file", name)
+	}
+	s, err := schema.LoadSchema(name)
+	if err != nil {
+		return nil, withExcerpt(err)
+	}
+	return s, nil
+}
+
+// fanOutSample is how many rows sized by record_count scaleRecordCounts
+// generates to measure how many rows foreign key cardinalities add to them.
+const fanOutSample = 1000
+
+// scaleRecordCounts resizes every table so the schema generates about total
+// records while keeping the tables' relative sizes. Tables whose size comes
+// from a foreign key cardinality follow their parents instead, so when there
+// are any, a sample of the schema is generated first to measure how many
+// rows each row sized by record_count brings with it, and the tables are
+// scaled down by that factor.
+func scaleRecordCounts(s *schema.Schema, total int, seed int64) error {
+	sum, fanOut := 0, false
+	for i := range s.Tables {
+		if sizedByCardinality(&s.Tables[i]) {
+			fanOut = true
+			continue
+		}
+		sum += s.Tables[i].RecordCount
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	perRow := 1.0
+	if fanOut {
+		sample := *s
+		sample.Tables = append([]schema.Table(nil), s.Tables...)
+		sampled := resizeTables(&sample, fanOutSample, sum)
+
+		ds, err := generator.Generate(&sample, generator.Options{Seed: seed})
+		if err != nil {
+			return err
+		}
+		rows := 0
+		for _, t := range ds.Tables {
+			rows += len(t.Rows)
+		}
+		perRow = float64(rows) / float64(sampled)
+	}
+
+	resizeTables(s, float64(total)/perRow, sum)
+	return nil
+}
+
+// resizeTables scales the tables sized by record_count, which add up to sum,
+// so they add up to about target, and returns their new total.
+func resizeTables(s *schema.Schema, target float64, sum int) int {
+	resized := 0
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if t.RecordCount == 0 || sizedByCardinality(t) {
+			continue
+		}
+		t.RecordCount = max(1, int(math.Round(float64(t.RecordCount)*target/float64(sum))))
+		resized += t.RecordCount
+	}
+	return resized
+}
+
+// sizedByCardinality reports whether one of t's foreign keys declares a
+// cardinality, which then sets t's row count.
+func sizedByCardinality(t *schema.Table) bool {
+	for _, col := range t.Columns {
+		if col.ForeignKey != nil && col.ForeignKey.Cardinality != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// progressReporter returns a seeder progress callback that draws one
+// progress bar per table on stderr, or nil when output is quiet.
+func progressReporter(cmd *cobra.Command) func(table string, done, total int) {
+	if quiet {
+		return nil
+	}
+
+	var bar *progressbar.ProgressBar
+	current := ""
+	return func(table string, done, total int) {
+		if table != current {
+			current = table
+			bar = progressbar.NewOptions(total,
+				progressbar.OptionSetWriter(cmd.ErrOrStderr()),
+				progressbar.OptionSetDescription(table),
+				progressbar.OptionClearOnFinish(),
+			)
+		}
+		_ = bar.Set(done)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(seedCmd)
 
@@ -56,6 +451,7 @@ func init() {
 	seedCmd.Flags().String("db-name", "demo", "database name")
 	seedCmd.Flags().String("output", "", "export to SQL file instead of inserting")
 	seedCmd.Flags().Bool("dry-run", false, "show what would be done without executing")
+	seedCmd.Flags().Int64("seed", 0, "random seed; the same seed produces the same data")
 
 	// Mark schema flag as required
 	_ = seedCmd.MarkFlagRequired("schema")
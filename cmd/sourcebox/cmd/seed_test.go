@@ -2,13 +2,47 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// exampleSchemaPath is the fintech example schema shipped with the repo.
+var exampleSchemaPath = filepath.Join("..", "..", "..", "schemas", "example-schema.json")
+
+// resetSeedFlags restores every seed flag (including --help) to its default
+// and clears its changed state, so one test's flags do not leak into the next.
+func resetSeedFlags() {
+	seedCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
+
+// stubSeedRunner replaces the seed runner with one that records the parsed
+// options instead of touching a database, restoring it when the test ends.
+func stubSeedRunner(t *testing.T) *seedOptions {
+	t.Helper()
+	got := &seedOptions{}
+	original := seedRunner
+	seedRunner = func(_ *cobra.Command, opts seedOptions) error {
+		*got = opts
+		return nil
+	}
+	t.Cleanup(func() { seedRunner = original })
+	return got
+}
+
 // TestSeedCommandRegistration verifies that the seed command is properly
 // registered with the root command.
 func TestSeedCommandRegistration(t *testing.T) {
@@ -201,6 +235,8 @@ func TestSeedCommandFlagParsing(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			stubSeedRunner(t)
+
 			buf := new(bytes.Buffer)
 			rootCmd.SetOut(buf)
 			rootCmd.SetErr(buf)
@@ -259,6 +295,7 @@ func TestSeedCommandArgumentValidation(t *testing.T) {
 	assert.NotNil(t, seedCmd.Args, "Args validator should be set")
 
 	// Test valid execution with proper arguments
+	stubSeedRunner(t)
 	buf := new(bytes.Buffer)
 	rootCmd.SetOut(buf)
 	rootCmd.SetErr(buf)
@@ -271,21 +308,302 @@ func TestSeedCommandArgumentValidation(t *testing.T) {
 	_ = seedCmd.Flags().Set("schema", "")
 }
 
-// TestSeedCommandPlaceholderOutput verifies that the placeholder Run function
-// is callable. Full output testing is deferred to F021 implementation.
-func TestSeedCommandPlaceholderOutput(t *testing.T) {
-	// This is a basic smoke test to ensure the command runs
-	// Full functionality will be tested in F021
+// TestSeedCommandDryRunOutput verifies that --dry-run generates the data and
+// reports the plan without connecting to a database.
+func TestSeedCommandDryRunOutput(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
 	buf := new(bytes.Buffer)
 	rootCmd.SetOut(buf)
 	rootCmd.SetErr(buf)
-	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=fintech-loans"})
+	rootCmd.SetArgs([]string{"seed", "postgres", "--schema=" + exampleSchemaPath, "--records=2000", "--seed=1", "--dry-run"})
 
 	err := rootCmd.Execute()
-	require.NoError(t, err, "Seed command should execute without error")
+	require.NoError(t, err, "Dry run should not need a database")
 
-	// Reset flags
-	_ = seedCmd.Flags().Set("schema", "")
+	output := buf.String()
+	assert.Contains(t, output, "Dry run - no changes will be made")
+	assert.Contains(t, output, "Database: postgres (localhost:5432/demo)", "port should be auto-detected")
+	assert.Contains(t, output, "Schema: fintech-loans")
+	assert.Contains(t, output, "Seed: 1\n")
+	// --records sizes borrowers; loans and payments follow from their cardinalities
+	assert.Regexp(t, `borrowers\s+32 rows`, output)
+	assert.Regexp(t, `loans\s+67 rows`, output)
+	assert.Regexp(t, `payments\s+1476 rows`, output)
+}
+
+// TestSeedCommandRecordsTotal verifies that --records sets the total number
+// of rows for the fintech schema, whose loans and payments are sized by
+// cardinalities rather than by --records directly.
+func TestSeedCommandRecordsTotal(t *testing.T) {
+	rows := regexp.MustCompile(`(?m)^\s+\w+\s+(\d+) rows$`)
+	sum := 0
+	for _, seed := range []string{"1", "2", "3", "4", "5"} {
+		stdout, _, err := executeCommand(t, "", "seed", "mysql", "--schema=fintech-loans", "--records=10000", "--seed="+seed, "--dry-run")
+		require.NoError(t, err)
+
+		total := 0
+		for _, m := range rows.FindAllStringSubmatch(stdout, -1) {
+			n, err := strconv.Atoi(m[1])
+			require.NoError(t, err)
+			total += n
+		}
+		assert.InEpsilon(t, 10000, total, 0.15, "seed %s", seed)
+		sum += total
+	}
+	assert.InEpsilon(t, 10000, sum/5, 0.05, "the average run should be close to --records")
+}
+
+// TestSeedCommandRecordsWithCardinality verifies that --records is shared
+// among the tables sized by record_count after leaving room for the rows a
+// cardinality adds to them.
+func TestSeedCommandRecordsWithCardinality(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	path := filepath.Join(t.TempDir(), "blog.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "name": "blog",
+  "database_type": ["mysql"],
+  "tables": [
+    {"name": "authors", "record_count": 300, "columns": [
+      {"name": "id", "type": "int", "primary_key": true}
+    ]},
+    {"name": "tags", "record_count": 100, "columns": [
+      {"name": "id", "type": "int", "primary_key": true}
+    ]},
+    {"name": "profiles", "record_count": 5000, "columns": [
+      {"name": "id", "type": "int", "primary_key": true},
+      {"name": "author_id", "type": "int", "foreign_key": {"table": "authors", "column": "id", "on_delete": "CASCADE", "on_update": "CASCADE",
+        "cardinality": {"min": 1, "max": 1}}}
+    ]}
+  ],
+  "generation_order": ["authors", "tags", "profiles"]
+}
+`), 0o644))
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + path, "--records=40", "--seed=1", "--dry-run"})
+	require.NoError(t, rootCmd.Execute())
+
+	output := buf.String()
+	assert.Regexp(t, `authors\s+17 rows`, output)
+	assert.Regexp(t, `tags\s+6 rows`, output)
+	assert.Regexp(t, `profiles\s+17 rows`, output, "one profile per author")
+}
+
+func TestSeedCommandChecksValidationRules(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "name": "rules",
+  "database_type": ["mysql"],
+  "tables": [
+    {"name": "accounts", "record_count": 20, "columns": [
+      {"name": "id", "type": "int", "primary_key": true},
+      {"name": "balance", "type": "int", "generator": "int_range", "generator_params": {"min": 1, "max": 100}}
+    ]}
+  ],
+  "validation_rules": [
+    {"rule": "COUNT(accounts) >= 1000", "severity": "warning"},
+    {"rule": "accounts.balance > 50", "description": "Balances start high", "severity": "error"}
+  ]
+}
+`), 0o644))
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + path, "--records=20", "--seed=1", "--dry-run"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "generated data does not satisfy the schema: 1 of 2 validation rules failed:")
+	assert.Regexp(t, `validation rule 'accounts.balance > 50' failed: \d+ rows of accounts do not satisfy balance > 50 \(Balances start high\)`, err.Error())
+	assert.Contains(t, stderr.String(), "warning: validation rule 'COUNT(accounts) >= 1000' failed: COUNT(accounts) is 20, want >= 1000")
+	assert.NotContains(t, stdout.String(), "Dry run", "nothing should run once an error rule fails")
+}
+
+// TestSeedCommandRunErrors verifies the errors reported before any
+// connection is attempted.
+func TestSeedCommandBuiltinSchemas(t *testing.T) {
+	tests := []struct {
+		schema string
+		tables []string
+	}{
+		{"fintech-loans", []string{"borrowers", "loans", "payments"}},
+		{"healthcare-patients", []string{"providers", "patients", "appointments", "prescriptions"}},
+		{"retail-orders", []string{"customers", "products", "orders", "order_items"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			resetSeedFlags()
+			defer resetSeedFlags()
+
+			buf := new(bytes.Buffer)
+			rootCmd.SetOut(buf)
+			rootCmd.SetErr(buf)
+			rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + tt.schema, "--records=100", "--seed=1", "--dry-run"})
+
+			err := rootCmd.Execute()
+			require.NoError(t, err, "Built-in schemas should resolve by name")
+
+			output := buf.String()
+			assert.Contains(t, output, "Schema: "+tt.schema)
+			for _, table := range tt.tables {
+				assert.Regexp(t, table+`\s+\d+ rows`, output)
+			}
+		})
+	}
+}
+
+func TestSeedCommandOutputFile(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	path := filepath.Join(t.TempDir(), "loans.sql")
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + exampleSchemaPath, "--records=50", "--seed=1", "--output=" + path})
+
+	err := rootCmd.Execute()
+	require.NoError(t, err, "Export should not need a database")
+	assert.Contains(t, buf.String(), "Wrote 51 rows in 3 tables to "+path+" (mysql, seed 1)")
+
+	script, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "-- Seed: 1\n")
+	assert.Contains(t, string(script), "CREATE TABLE `borrowers`")
+	assert.Contains(t, string(script), "INSERT INTO `payments`")
+}
+
+func TestSeedCommandOutputStdout(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"seed", "postgres", "--schema=" + exampleSchemaPath, "--records=50", "--seed=1", "--output=-"})
+
+	err := rootCmd.Execute()
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "-- SourceBox SQL export\n"), "stdout should hold only the script")
+	assert.Contains(t, output, `CREATE TABLE "loans"`)
+	assert.NotContains(t, output, "Wrote ")
+}
+
+func TestSeedCommandSchemaErrorExcerpt(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	require.NoError(t, os.WriteFile(path, []byte("{\n  \"name\": \"broken\",\n  \"tables\": [,]\n}\n"), 0o644))
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + path, "--dry-run"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3, column 14: invalid character ','")
+	assert.Contains(t, err.Error(), "--> "+path+":3:14\n")
+	assert.Contains(t, err.Error(), "3 |   \"tables\": [,]\n  |              ^")
+}
+
+func TestSeedCommandReportsAllSchemaErrors(t *testing.T) {
+	resetSeedFlags()
+	defer resetSeedFlags()
+
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "name": "invalid",
+  "database_type": ["mysql"],
+  "tables": [
+    {"name": "t", "record_count": 0, "columns": [{"name": "id", "type": "money", "primary_key": true}]}
+  ]
+}
+`), 0o644))
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + path, "--dry-run"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	msg := err.Error()
+	assert.True(t, strings.HasPrefix(msg, "schema validation failed with 2 errors:\n"), msg)
+	assert.Contains(t, msg, "ERROR [V-T002]: table 0 (t): record_count must be greater than 0\n --> "+path+":5:35 (/tables/0/record_count)")
+	assert.Contains(t, msg, "ERROR [V-C002]: table 0 (t): column 0 (id): invalid data type \"money\": type not supported\n --> "+path+":5:73 (/tables/0/columns/0/type)")
+
+	var list schema.ErrorList
+	assert.True(t, errors.As(err, &list), "the report should unwrap to the diagnostics")
+}
+
+func TestSeedCommandRunErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		errorMsg string
+		usage    bool
+	}{
+		{
+			name:     "unsupported database",
+			args:     []string{"seed", "oracle", "--schema=" + exampleSchemaPath, "--dry-run"},
+			errorMsg: "unsupported database 'oracle'",
+		},
+		{
+			name:     "unknown schema",
+			args:     []string{"seed", "mysql", "--schema=no-such-schema", "--dry-run"},
+			errorMsg: "schema 'no-such-schema' not found: use a schema name from 'sourcebox list-schemas'",
+		},
+		{
+			name:     "non-positive records",
+			args:     []string{"seed", "mysql", "--schema=" + exampleSchemaPath, "--records=0", "--dry-run"},
+			errorMsg: "--records must be greater than 0",
+			usage:    true,
+		},
+		{
+			name:     "port out of range",
+			args:     []string{"seed", "mysql", "--schema=" + exampleSchemaPath, "--port=70000", "--dry-run"},
+			errorMsg: "--port must be between 1 and 65535",
+			usage:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSeedFlags()
+			defer resetSeedFlags()
+			seedCmd.SilenceUsage = false
+			defer func() { seedCmd.SilenceUsage = false }()
+
+			buf := new(bytes.Buffer)
+			rootCmd.SetOut(buf)
+			rootCmd.SetErr(buf)
+			rootCmd.SetArgs(tt.args)
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+			if tt.usage {
+				assert.Contains(t, buf.String(), "Usage:", "bad flags should print usage")
+			} else {
+				assert.NotContains(t, buf.String(), "Usage:", "a failed run is not a usage error")
+			}
+		})
+	}
 }
 
 // TestSeedCommandFlagDefaults verifies that all flags have correct default values.
@@ -384,6 +702,7 @@ func TestSeedCommandWithGlobalFlags(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Reset flags
 			resetGlobalFlags()
+			stubSeedRunner(t)
 
 			buf := new(bytes.Buffer)
 			rootCmd.SetOut(buf)
@@ -455,3 +774,55 @@ func TestSeedCommandNegativeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestSeedCommandSeedFlag verifies that --seed is parsed and reported, and
+// that omitting it gives the same output every time.
+func TestSeedCommandSeedFlag(t *testing.T) {
+	seedFlag := seedCmd.Flags().Lookup("seed")
+	require.NotNil(t, seedFlag, "seed flag should be defined")
+	assert.Equal(t, "0", seedFlag.DefValue, "seed default should be 0")
+
+	// An earlier --help invocation leaves the help flag set on seedCmd
+	resetSeedFlags()
+
+	t.Run("explicit seed", func(t *testing.T) {
+		defer resetSeedFlags()
+
+		buf := new(bytes.Buffer)
+		rootCmd.SetOut(buf)
+		rootCmd.SetErr(buf)
+		rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + exampleSchemaPath, "--dry-run", "--seed=42"})
+
+		require.NoError(t, rootCmd.Execute())
+		assert.Contains(t, buf.String(), "Seed: 42")
+	})
+
+	t.Run("explicit zero seed", func(t *testing.T) {
+		defer resetSeedFlags()
+
+		buf := new(bytes.Buffer)
+		rootCmd.SetOut(buf)
+		rootCmd.SetErr(buf)
+		rootCmd.SetArgs([]string{"seed", "mysql", "--schema=" + exampleSchemaPath, "--dry-run", "--seed=0"})
+
+		require.NoError(t, rootCmd.Execute())
+		assert.Contains(t, buf.String(), "Seed: 0\n")
+	})
+
+	t.Run("default seed is fixed", func(t *testing.T) {
+		run := func() string {
+			defer resetSeedFlags()
+
+			buf := new(bytes.Buffer)
+			rootCmd.SetOut(buf)
+			rootCmd.SetErr(new(bytes.Buffer))
+			rootCmd.SetArgs([]string{"seed", "postgres", "--schema=" + exampleSchemaPath, "--records=20", "--output=-"})
+			require.NoError(t, rootCmd.Execute())
+			return buf.String()
+		}
+
+		first := run()
+		assert.Contains(t, first, "-- Seed: 0\n", "the script records the seed it was made with")
+		assert.Equal(t, first, run(), "runs without --seed should produce identical output")
+	})
+}
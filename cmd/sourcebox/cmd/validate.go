@@ -0,0 +1,293 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jbeausoleil/sourcebox/pkg/registry"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// validateFormats lists the supported --format values.
+var validateFormats = []string{"human", "json", "github"}
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <schema>...",
+	Short: "Check schema files for errors",
+	Long: `Check schemas for errors without generating any data.
+
+Each argument is a schema file (JSON or YAML), a glob such as
+"schemas/*.json", or the name of a built-in or user schema. Every schema
+is parsed and checked against all validation rules, including generators
+and their parameters, and every problem is reported, not just the first.
+
+Output formats:
+  human   diagnostics with source excerpts (default)
+  json    one result object per schema, for scripts
+  github  workflow commands that annotate the offending lines in a
+          GitHub Actions run
+
+The command exits with a non-zero status when any schema has errors;
+warnings are reported but do not fail it.`,
+
+	Example: `  # Validate a schema you are writing
+  sourcebox validate my-schema.yaml
+
+  # Validate every schema in a directory
+  sourcebox validate 'schemas/*.json'
+
+  # Annotate a pull request from GitHub Actions
+  sourcebox validate --format=github schemas/*.json`,
+
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runValidate,
+}
+
+// validateResult is the outcome of validating one schema.
+type validateResult struct {
+	// Target is the file path, or the name of a built-in schema.
+	Target      string       `json:"target"`
+	Schema      string       `json:"schema,omitempty"`
+	Valid       bool         `json:"valid"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+
+	list schema.ErrorList
+}
+
+// diagnostic is the JSON form of a schema.Error.
+type diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Pointer  string `json:"pointer,omitempty"`
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	format = strings.ToLower(format)
+	if !containsFormat(validateFormats, format) {
+		return fmt.Errorf("unsupported format '%s': must be one of: %s", format, strings.Join(validateFormats, ", "))
+	}
+
+	var results []*validateResult
+	for _, arg := range args {
+		results = append(results, validateArg(arg)...)
+	}
+
+	out := cmd.OutOrStdout()
+	var err error
+	switch format {
+	case "human":
+		writeValidateHuman(out, results)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(results)
+	case "github":
+		writeValidateGitHub(out, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Valid {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d %s failed validation", failed, len(results), plural(len(results), "schema", "schemas"))
+	}
+	return nil
+}
+
+func containsFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// validateArg validates the schemas an argument names: every file matching
+// a glob, a registered schema, or a single file.
+func validateArg(arg string) []*validateResult {
+	if strings.ContainsAny(arg, "*?[") {
+		matches, err := filepath.Glob(arg)
+		if err == nil && len(matches) == 0 {
+			err = fmt.Errorf("no schema files match %q", arg)
+		}
+		if err != nil {
+			return []*validateResult{failedResult(arg, err)}
+		}
+		results := make([]*validateResult, len(matches))
+		for i, path := range matches {
+			results[i] = validateFile(path)
+		}
+		return results
+	}
+
+	if _, err := os.Stat(arg); err != nil {
+		entry, ok := loadRegistry().Get(arg)
+		if !ok {
+			return []*validateResult{failedResult(arg, fmt.Errorf("schema '%s' not found: use a schema name from 'sourcebox list-schemas' or the path to a schema file", arg))}
+		}
+		if entry.Source != registry.BuiltinSource {
+			return []*validateResult{validateFile(entry.Source)}
+		}
+		// Built-ins have no file to point into, so their diagnostics carry
+		// JSON pointers only
+		return []*validateResult{newResult(arg, entry.Schema, schema.Validate(entry.Schema))}
+	}
+	return []*validateResult{validateFile(arg)}
+}
+
+func validateFile(path string) *validateResult {
+	s, list, err := schema.CheckSchemaFile(path)
+	if err != nil {
+		return failedResult(path, err)
+	}
+	return newResult(path, s, list)
+}
+
+// failedResult reports a schema that could not be read at all.
+func failedResult(target string, err error) *validateResult {
+	return newResult(target, nil, schema.ErrorList{{Severity: schema.SeverityError, Err: err}})
+}
+
+func newResult(target string, s *schema.Schema, list schema.ErrorList) *validateResult {
+	r := &validateResult{
+		Target:      target,
+		Errors:      len(list.Errors()),
+		Warnings:    len(list.Warnings()),
+		Diagnostics: []diagnostic{},
+		list:        list,
+	}
+	r.Valid = r.Errors == 0
+	if s != nil {
+		r.Schema = s.Name
+	}
+	for _, e := range list {
+		r.Diagnostics = append(r.Diagnostics, diagnostic{
+			Severity: string(e.Severity),
+			Code:     e.Code,
+			Message:  e.Err.Error(),
+			File:     e.File,
+			Line:     e.Line,
+			Column:   e.Column,
+			Pointer:  e.Pointer,
+		})
+	}
+	return r
+}
+
+// writeValidateHuman prints a status line per schema, the diagnostics of
+// any with problems, and a summary. --quiet leaves only the problems.
+func writeValidateHuman(out io.Writer, results []*validateResult) {
+	pass := color.New(color.FgGreen).SprintFunc()
+	fail := color.New(color.FgRed).SprintFunc()
+
+	valid := 0
+	for _, r := range results {
+		if r.Valid {
+			valid++
+		}
+		if len(r.list) == 0 {
+			if !quiet {
+				fmt.Fprintf(out, "%s %s\n", pass("✓"), r.Target)
+			}
+			continue
+		}
+
+		mark := pass("✓")
+		if !r.Valid {
+			mark = fail("✗")
+		}
+		var counts []string
+		if r.Errors > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", r.Errors, plural(r.Errors, "error", "errors")))
+		}
+		if r.Warnings > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", r.Warnings, plural(r.Warnings, "warning", "warnings")))
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", mark, r.Target, strings.Join(counts, ", "))
+		writeDiagnostics(out, r.list)
+		fmt.Fprintln(out)
+	}
+
+	if !quiet {
+		fmt.Fprintf(out, "Validated %d %s: %d valid, %d with errors\n",
+			len(results), plural(len(results), "schema", "schemas"), valid, len(results)-valid)
+	}
+}
+
+// writeValidateGitHub prints one GitHub Actions workflow command per
+// diagnostic, which the runner turns into an annotation on the file and
+// line.
+func writeValidateGitHub(out io.Writer, results []*validateResult) {
+	for _, r := range results {
+		for _, e := range r.list {
+			level := "error"
+			if e.Severity == schema.SeverityWarning {
+				level = "warning"
+			}
+
+			var props []string
+			if e.File != "" {
+				props = append(props, "file="+githubProperty(e.File))
+			}
+			if e.Line > 0 {
+				props = append(props, "line="+strconv.Itoa(e.Line), "col="+strconv.Itoa(e.Column))
+			}
+			title := r.Target
+			if e.Code != "" {
+				title = e.Code + " " + title
+			}
+			props = append(props, "title="+githubProperty(title))
+
+			fmt.Fprintf(out, "::%s %s::%s\n", level, strings.Join(props, ","), githubMessage(e.Err.Error()))
+		}
+	}
+}
+
+// githubMessage escapes a workflow command's message.
+func githubMessage(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a workflow command's property value.
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().String("format", "human", "output format: "+strings.Join(validateFormats, ", "))
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenSchemaYAML has one error (an unknown type) and one warning (jsonb
+// on MySQL).
+const brokenSchemaYAML = `name: broken
+database_type: [mysql]
+tables:
+  - name: events
+    record_count: 10
+    columns:
+      - {name: id, type: integr, primary_key: true}
+      - {name: payload, type: jsonb}
+`
+
+func writeBrokenSchema(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(brokenSchemaYAML), 0o644))
+	return path
+}
+
+func TestValidateCommand(t *testing.T) {
+	output, _, err := executeCommand(t, "", "validate", exampleSchemaPath, "retail-orders")
+	require.NoError(t, err)
+	assert.Contains(t, output, "✓ "+exampleSchemaPath+"\n")
+	assert.Contains(t, output, "✓ retail-orders\n")
+	assert.Contains(t, output, "Validated 2 schemas: 2 valid, 0 with errors\n")
+
+	broken := writeBrokenSchema(t)
+	output, stderr, err := executeCommand(t, "", "validate", exampleSchemaPath, broken, "no-such-schema")
+	require.Error(t, err)
+	assert.Equal(t, "2 of 3 schemas failed validation", err.Error())
+	assert.Contains(t, stderr, "Error: 2 of 3 schemas failed validation")
+	assert.NotContains(t, stderr, "Usage:", "a failed validation is not a usage error")
+
+	assert.Contains(t, output, "✗ "+broken+": 1 error, 1 warning\n")
+	assert.Contains(t, output, "ERROR [V-C002]: table 0 (events): column 0 (id): invalid data type \"integr\": type not supported")
+	assert.Contains(t, output, "--> "+broken+":7:26 (/tables/0/columns/0/type)")
+	assert.Contains(t, output, "WARNING [V-C002]: table 'events': column 'payload': jsonb is PostgreSQL-specific")
+	assert.Contains(t, output, "ERROR: schema 'no-such-schema' not found")
+	assert.Contains(t, output, "Validated 3 schemas: 1 valid, 2 with errors\n")
+}
+
+func TestValidateCommandGlob(t *testing.T) {
+	output, _, err := executeCommand(t, "", "validate", filepath.Join("..", "..", "..", "schemas", "*.json"))
+	require.NoError(t, err)
+	assert.Contains(t, output, "Validated 3 schemas: 3 valid, 0 with errors\n")
+
+	_, _, err = executeCommand(t, "", "validate", filepath.Join(t.TempDir(), "*.yaml"))
+	require.Error(t, err)
+}
+
+func TestValidateCommandJSON(t *testing.T) {
+	broken := writeBrokenSchema(t)
+	output, _, err := executeCommand(t, "", "validate", "--format=json", exampleSchemaPath, broken)
+	require.Error(t, err)
+
+	var results []validateResult
+	require.NoError(t, json.Unmarshal([]byte(output), &results))
+	require.Len(t, results, 2)
+
+	assert.Equal(t, exampleSchemaPath, results[0].Target)
+	assert.Equal(t, "fintech-loans", results[0].Schema)
+	assert.True(t, results[0].Valid)
+	assert.Empty(t, results[0].Diagnostics)
+
+	assert.False(t, results[1].Valid)
+	assert.Equal(t, 1, results[1].Errors)
+	assert.Equal(t, 1, results[1].Warnings)
+	require.Len(t, results[1].Diagnostics, 2)
+	assert.Equal(t, diagnostic{
+		Severity: "error",
+		Code:     "V-C002",
+		Message:  `table 0 (events): column 0 (id): invalid data type "integr": type not supported`,
+		File:     broken,
+		Line:     7,
+		Column:   26,
+		Pointer:  "/tables/0/columns/0/type",
+	}, results[1].Diagnostics[0])
+	assert.Equal(t, "warning", results[1].Diagnostics[1].Severity)
+}
+
+func TestValidateCommandGitHub(t *testing.T) {
+	broken := writeBrokenSchema(t)
+	output, _, err := executeCommand(t, "", "validate", "--format=github", broken, "fintech-loans")
+	require.Error(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace([]byte(output)), []byte("\n"))
+	require.Len(t, lines, 2, "only diagnostics are printed")
+	file := githubProperty(broken)
+	assert.Equal(t, "::error file="+file+",line=7,col=26,title=V-C002 "+file+`::table 0 (events): column 0 (id): invalid data type "integr": type not supported`, string(lines[0]))
+	assert.Contains(t, string(lines[1]), "::warning file="+file+",line=8,col=")
+}
+
+func TestValidateCommandErrors(t *testing.T) {
+	_, _, err := executeCommand(t, "", "validate")
+	require.Error(t, err)
+
+	_, _, err = executeCommand(t, "", "validate", "--format=xml", exampleSchemaPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format 'xml': must be one of: human, json, github")
+
+	assert.Equal(t, "a%3Ab%2Cc%25%0A", githubProperty("a:b,c%\n"))
+	assert.Equal(t, "50%25 done%0Anext: line", githubMessage("50% done\nnext: line"))
+}
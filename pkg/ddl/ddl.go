@@ -0,0 +1,261 @@
+// Package ddl renders schema definitions as MySQL or PostgreSQL DDL, and
+// imports existing DDL back into schemas (see Import).
+//
+// Schema types use a neutral, MySQL-flavoured vocabulary (int, decimal,
+// varchar, datetime, boolean, json, jsonb, enum...). The dialect maps each
+// one to a native column type, and this package adds what the type alone
+// cannot express:
+//
+//   - auto_increment becomes AUTO_INCREMENT on MySQL and
+//     GENERATED BY DEFAULT AS IDENTITY on PostgreSQL
+//   - enum(...) stays a native ENUM on MySQL and becomes a VARCHAR with a
+//     CHECK constraint on PostgreSQL
+//   - defaults are rendered as literals of the column's type, with
+//     CURRENT_TIMESTAMP and friends passed through as expressions
+//   - foreign keys are named fk_<table>_<column> and can be declared inline
+//     or added afterwards with ALTER TABLE
+//
+// Example usage:
+//
+//	for _, stmt := range ddl.Schema(dialect.Postgres{}, s) {
+//	    fmt.Printf("%s;\n", stmt)
+//	}
+package ddl
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// Options configures CreateTable.
+type Options struct {
+	// InlineForeignKeys declares foreign keys inside CREATE TABLE. Parent
+	// tables must then be created before their children. When false, use
+	// AddForeignKey once every table exists.
+	InlineForeignKeys bool
+
+	// SkipForeignKeys lists columns whose foreign key is left out of an
+	// inline CREATE TABLE, such as the deferred keys of a cycle whose parent
+	// table does not exist yet. Add them with AddForeignKey later.
+	SkipForeignKeys []string
+}
+
+// Schema returns the statements that create every table of s, followed by
+// its indexes and foreign keys. Foreign keys come last so tables can be
+// created in any order. Statements have no trailing semicolon.
+func Schema(d dialect.Dialect, s *schema.Schema) []string {
+	var stmts []string
+	for i := range s.Tables {
+		stmts = append(stmts, CreateTable(d, &s.Tables[i], Options{}))
+	}
+	for _, t := range s.Tables {
+		for _, idx := range t.Indexes {
+			stmts = append(stmts, CreateIndex(d, t.Name, idx))
+		}
+	}
+	for _, t := range s.Tables {
+		for i := range t.Columns {
+			if t.Columns[i].ForeignKey != nil {
+				stmts = append(stmts, AddForeignKey(d, t.Name, &t.Columns[i]))
+			}
+		}
+	}
+	return stmts
+}
+
+// CreateTable returns a CREATE TABLE statement for t with its columns,
+// primary key and, when opts.InlineForeignKeys is set, foreign keys.
+func CreateTable(d dialect.Dialect, t *schema.Table, opts Options) string {
+	var defs []string
+	var pk []string
+
+	for i := range t.Columns {
+		col := &t.Columns[i]
+		defs = append(defs, ColumnDefinition(d, col))
+		if col.PrimaryKey {
+			pk = append(pk, d.QuoteIdent(col.Name))
+		}
+	}
+
+	if len(pk) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(pk, ", ")+")")
+	}
+
+	if opts.InlineForeignKeys {
+		for i := range t.Columns {
+			if t.Columns[i].ForeignKey != nil && !slices.Contains(opts.SkipForeignKeys, t.Columns[i].Name) {
+				defs = append(defs, ForeignKeyConstraint(d, t.Name, &t.Columns[i]))
+			}
+		}
+	}
+
+	return "CREATE TABLE " + d.QuoteIdent(t.Name) + " (\n  " + strings.Join(defs, ",\n  ") + "\n)"
+}
+
+// ColumnDefinition returns the column definition for col as it appears in
+// CREATE TABLE, e.g. `"status" VARCHAR(8) NOT NULL DEFAULT 'active'`.
+func ColumnDefinition(d dialect.Dialect, col *schema.Column) string {
+	def := d.QuoteIdent(col.Name) + " " + d.ColumnType(col.Type)
+
+	if !col.Nullable {
+		def += " NOT NULL"
+	}
+	if col.AutoIncrement {
+		switch d.Name() {
+		case "postgres":
+			def += " GENERATED BY DEFAULT AS IDENTITY"
+		default:
+			def += " AUTO_INCREMENT"
+		}
+	} else if col.Default != nil {
+		def += " DEFAULT " + DefaultValue(d, col)
+	}
+	if col.Unique && !col.PrimaryKey {
+		def += " UNIQUE"
+	}
+
+	// PostgreSQL has no inline enum type, so the allowed values become a
+	// CHECK constraint on the VARCHAR the dialect maps enums to
+	if t := col.DataType(); t.Base == "enum" && d.Name() == "postgres" {
+		values := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			values[i] = d.Literal(v)
+		}
+		def += " CHECK (" + d.QuoteIdent(col.Name) + " IN (" + strings.Join(values, ", ") + "))"
+	}
+
+	return def
+}
+
+// defaultExpressions are defaults passed through as SQL expressions rather
+// than quoted, keyed by their upper-cased spelling.
+var defaultExpressions = map[string]string{
+	"CURRENT_TIMESTAMP": "CURRENT_TIMESTAMP",
+	"CURRENT_DATE":      "CURRENT_DATE",
+	"NOW()":             "CURRENT_TIMESTAMP",
+	"NULL":              "NULL",
+}
+
+// DefaultValue renders col's default as an SQL expression. Numbers are left
+// unquoted for numeric columns, true/false become boolean literals for
+// boolean columns, and everything else is a string literal. On MySQL,
+// defaults of TEXT and JSON columns are wrapped in parentheses, which those
+// types require.
+func DefaultValue(d dialect.Dialect, col *schema.Column) string {
+	if col.Default == nil {
+		return "NULL"
+	}
+	v := strings.TrimSpace(*col.Default)
+	if expr, ok := defaultExpressions[strings.ToUpper(v)]; ok {
+		return expr
+	}
+
+	// An array default is written as a string, such as '{1,2,3}'
+	t := col.DataType()
+	if t.Array {
+		return d.Literal(v)
+	}
+	switch family := t.Family(); {
+	case family == schema.FamilyInteger || family == schema.FamilyNumber:
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return v
+		}
+	case family == schema.FamilyBoolean:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return d.Literal(b)
+		}
+	case t.Base == "text" || family == schema.FamilyJSON:
+		if d.Name() == "mysql" {
+			return "(" + d.Literal(v) + ")"
+		}
+	}
+	return d.Literal(v)
+}
+
+// CreateIndex returns a CREATE INDEX statement for idx on table. The index
+// method goes after the column list on MySQL and before it on PostgreSQL.
+func CreateIndex(d dialect.Dialect, table string, idx schema.Index) string {
+	cols := quoteAll(d, idx.Columns)
+
+	stmt := "CREATE "
+	if idx.Unique {
+		stmt += "UNIQUE "
+	}
+	stmt += "INDEX " + d.QuoteIdent(idx.Name) + " ON " + d.QuoteIdent(table)
+
+	method := strings.ToUpper(idx.Type)
+	switch {
+	case method == "":
+		stmt += " (" + cols + ")"
+	case d.Name() == "postgres":
+		stmt += " USING " + strings.ToLower(method) + " (" + cols + ")"
+	default:
+		stmt += " (" + cols + ") USING " + method
+	}
+	return stmt
+}
+
+// DropTable returns a DROP TABLE statement for table.
+func DropTable(d dialect.Dialect, table string) string {
+	return "DROP TABLE " + d.QuoteIdent(table)
+}
+
+// ForeignKeyName returns the constraint name used for col's foreign key.
+func ForeignKeyName(table, column string) string {
+	return "fk_" + table + "_" + column
+}
+
+// ForeignKeyConstraint returns the table constraint for col's foreign key,
+// e.g. `CONSTRAINT "fk_loans_borrower_id" FOREIGN KEY ("borrower_id")
+// REFERENCES "borrowers" ("id") ON DELETE CASCADE`.
+func ForeignKeyConstraint(d dialect.Dialect, table string, col *schema.Column) string {
+	fk := col.ForeignKey
+	stmt := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.QuoteIdent(ForeignKeyName(table, col.Name)),
+		d.QuoteIdent(col.Name), d.QuoteIdent(fk.Table), d.QuoteIdent(fk.Column))
+	if fk.OnDelete != "" {
+		stmt += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		stmt += " ON UPDATE " + strings.ToUpper(fk.OnUpdate)
+	}
+	return stmt
+}
+
+// AddForeignKey returns an ALTER TABLE statement adding col's foreign key.
+func AddForeignKey(d dialect.Dialect, table string, col *schema.Column) string {
+	return "ALTER TABLE " + d.QuoteIdent(table) + " ADD " + ForeignKeyConstraint(d, table, col)
+}
+
+// ResetIdentity returns a statement that moves t's identity sequence past
+// the largest loaded value, or "" when none is needed. PostgreSQL identity
+// columns do not advance when rows are inserted with explicit values, so
+// without it the next application insert would collide with loaded data.
+// MySQL's AUTO_INCREMENT adjusts itself.
+func ResetIdentity(d dialect.Dialect, t *schema.Table) string {
+	if d.Name() != "postgres" {
+		return ""
+	}
+	for _, col := range t.Columns {
+		if !col.AutoIncrement {
+			continue
+		}
+		return fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			d.Literal(d.QuoteIdent(t.Name)), d.Literal(col.Name), d.QuoteIdent(col.Name), d.QuoteIdent(t.Name))
+	}
+	return ""
+}
+
+// quoteAll quotes and comma-separates a list of column names.
+func quoteAll(d dialect.Dialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = d.QuoteIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,169 @@
+package ddl
+
+import (
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string { return &s }
+
+// loansSchema is a two-table schema exercising every DDL feature.
+func loansSchema() *schema.Schema {
+	return &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql", "postgres"},
+		Tables: []schema.Table{
+			{
+				Name: "borrowers",
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true, AutoIncrement: true},
+					{Name: "email", Type: "varchar(255)", Unique: true},
+					{Name: "created_at", Type: "datetime", Default: strPtr("CURRENT_TIMESTAMP")},
+				},
+				Indexes: []schema.Index{{Name: "idx_email", Columns: []string{"email"}, Type: "BTREE", Unique: true}},
+			},
+			{
+				Name: "loans",
+				Columns: []schema.Column{
+					{Name: "id", Type: "bigint", PrimaryKey: true},
+					{Name: "borrower_id", Type: "int", ForeignKey: &schema.ForeignKey{
+						Table: "borrowers", Column: "id", OnDelete: "cascade", OnUpdate: "restrict",
+					}},
+					{Name: "status", Type: "enum('active','paid_off')", Default: strPtr("active")},
+					{Name: "metadata", Type: "jsonb", Nullable: true},
+				},
+			},
+		},
+	}
+}
+
+func TestCreateTable_MySQL(t *testing.T) {
+	s := loansSchema()
+
+	assert.Equal(t, "CREATE TABLE `borrowers` (\n"+
+		"  `id` int NOT NULL AUTO_INCREMENT,\n"+
+		"  `email` varchar(255) NOT NULL UNIQUE,\n"+
+		"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		")", CreateTable(dialect.MySQL{}, s.Table("borrowers"), Options{}))
+
+	assert.Equal(t, "CREATE TABLE `loans` (\n"+
+		"  `id` bigint NOT NULL,\n"+
+		"  `borrower_id` int NOT NULL,\n"+
+		"  `status` enum('active','paid_off') NOT NULL DEFAULT 'active',\n"+
+		"  `metadata` JSON,\n"+
+		"  PRIMARY KEY (`id`),\n"+
+		"  CONSTRAINT `fk_loans_borrower_id` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE ON UPDATE RESTRICT\n"+
+		")", CreateTable(dialect.MySQL{}, s.Table("loans"), Options{InlineForeignKeys: true}))
+
+	assert.NotContains(t, CreateTable(dialect.MySQL{}, s.Table("loans"), Options{InlineForeignKeys: true, SkipForeignKeys: []string{"borrower_id"}}),
+		"CONSTRAINT", "skipped foreign keys should not be declared inline")
+}
+
+func TestCreateTable_Postgres(t *testing.T) {
+	s := loansSchema()
+
+	assert.Equal(t, `CREATE TABLE "borrowers" (`+"\n"+
+		`  "id" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,`+"\n"+
+		`  "email" VARCHAR(255) NOT NULL UNIQUE,`+"\n"+
+		`  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,`+"\n"+
+		`  PRIMARY KEY ("id")`+"\n"+
+		")", CreateTable(dialect.Postgres{}, s.Table("borrowers"), Options{}))
+
+	assert.Equal(t, `CREATE TABLE "loans" (`+"\n"+
+		`  "id" BIGINT NOT NULL,`+"\n"+
+		`  "borrower_id" INTEGER NOT NULL,`+"\n"+
+		`  "status" VARCHAR(8) NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'paid_off')),`+"\n"+
+		`  "metadata" JSONB,`+"\n"+
+		`  PRIMARY KEY ("id")`+"\n"+
+		")", CreateTable(dialect.Postgres{}, s.Table("loans"), Options{}))
+}
+
+func TestCreateTable_CompositePrimaryKey(t *testing.T) {
+	table := &schema.Table{
+		Name: "enrollments",
+		Columns: []schema.Column{
+			{Name: "student_id", Type: "int", PrimaryKey: true},
+			{Name: "course_id", Type: "int", PrimaryKey: true},
+		},
+	}
+
+	assert.Contains(t, CreateTable(dialect.Postgres{}, table, Options{}), `PRIMARY KEY ("student_id", "course_id")`)
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		columnType string
+		value      string
+		mysql      string
+		postgres   string
+	}{
+		{"integer", "int", "0", "0", "0"},
+		{"decimal", "decimal(10,2)", "9.99", "9.99", "9.99"},
+		{"non-numeric on numeric column", "int", "abc", "'abc'", "'abc'"},
+		{"boolean", "boolean", "false", "FALSE", "FALSE"},
+		{"string", "varchar(20)", "O'Brien", `'O\'Brien'`, "'O''Brien'"},
+		{"numeric string", "varchar(20)", "42", "'42'", "'42'"},
+		{"timestamp expression", "timestamp", "current_timestamp", "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"now", "datetime", "NOW()", "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"null", "varchar(20)", "NULL", "NULL", "NULL"},
+		{"json", "json", "{}", "('{}')", "'{}'"},
+		{"text", "text", "none", "('none')", "'none'"},
+		{"unsigned integer", "int unsigned", "7", "7", "7"},
+		{"array", "int[]", "{1,2}", "'{1,2}'", "'{1,2}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := &schema.Column{Name: "c", Type: tt.columnType, Default: strPtr(tt.value)}
+			assert.Equal(t, tt.mysql, DefaultValue(dialect.MySQL{}, col))
+			assert.Equal(t, tt.postgres, DefaultValue(dialect.Postgres{}, col))
+		})
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	unique := schema.Index{Name: "idx_email", Columns: []string{"email"}, Type: "BTREE", Unique: true}
+	assert.Equal(t, "CREATE UNIQUE INDEX `idx_email` ON `borrowers` (`email`) USING BTREE", CreateIndex(dialect.MySQL{}, "borrowers", unique))
+	assert.Equal(t, `CREATE UNIQUE INDEX "idx_email" ON "borrowers" USING btree ("email")`, CreateIndex(dialect.Postgres{}, "borrowers", unique))
+
+	plain := schema.Index{Name: "idx_a_b", Columns: []string{"a", "b"}}
+	assert.Equal(t, "CREATE INDEX `idx_a_b` ON `t` (`a`, `b`)", CreateIndex(dialect.MySQL{}, "t", plain))
+	assert.Equal(t, `CREATE INDEX "idx_a_b" ON "t" ("a", "b")`, CreateIndex(dialect.Postgres{}, "t", plain))
+}
+
+func TestAddForeignKey(t *testing.T) {
+	s := loansSchema()
+	col := s.Table("loans").Column("borrower_id")
+
+	assert.Equal(t, `ALTER TABLE "loans" ADD CONSTRAINT "fk_loans_borrower_id" FOREIGN KEY ("borrower_id") REFERENCES "borrowers" ("id") ON DELETE CASCADE ON UPDATE RESTRICT`,
+		AddForeignKey(dialect.Postgres{}, "loans", col))
+
+	bare := &schema.Column{Name: "owner_id", ForeignKey: &schema.ForeignKey{Table: "users", Column: "id"}}
+	assert.Equal(t, "ALTER TABLE `pets` ADD CONSTRAINT `fk_pets_owner_id` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`)",
+		AddForeignKey(dialect.MySQL{}, "pets", bare))
+}
+
+func TestResetIdentity(t *testing.T) {
+	s := loansSchema()
+
+	assert.Equal(t, `SELECT setval(pg_get_serial_sequence('"borrowers"', 'id'), COALESCE(MAX("id"), 0) + 1, false) FROM "borrowers"`,
+		ResetIdentity(dialect.Postgres{}, s.Table("borrowers")))
+	assert.Empty(t, ResetIdentity(dialect.Postgres{}, s.Table("loans")), "tables without identity columns need no reset")
+	assert.Empty(t, ResetIdentity(dialect.MySQL{}, s.Table("borrowers")), "MySQL AUTO_INCREMENT adjusts itself")
+}
+
+func TestSchema(t *testing.T) {
+	stmts := Schema(dialect.MySQL{}, loansSchema())
+
+	assert.Len(t, stmts, 4)
+	assert.Contains(t, stmts[0], "CREATE TABLE `borrowers`")
+	assert.Contains(t, stmts[1], "CREATE TABLE `loans`")
+	assert.NotContains(t, stmts[1], "FOREIGN KEY", "foreign keys should be added after every table exists")
+	assert.Contains(t, stmts[2], "CREATE UNIQUE INDEX `idx_email`")
+	assert.Contains(t, stmts[3], "ALTER TABLE `loans` ADD CONSTRAINT `fk_loans_borrower_id`")
+}
@@ -0,0 +1,1687 @@
+package ddl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// DefaultRecordCount is the record_count Import gives every table unless
+// ImportOptions says otherwise.
+const DefaultRecordCount = 100
+
+// Script is one DDL file to import.
+type Script struct {
+	// Name identifies the script in diagnostics, usually its path.
+	Name string
+	Text string
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// Dialect is the SQL dialect the scripts are written in. When nil it
+	// is detected from dialect-specific syntax, such as backquotes or
+	// SERIAL columns; scripts with none target both databases.
+	Dialect dialect.Dialect
+
+	// Name is the schema name. Defaults to "imported".
+	Name string
+
+	// RecordCount is the record_count of every table. Zero means
+	// DefaultRecordCount.
+	RecordCount int
+}
+
+// Import builds a schema from MySQL or PostgreSQL DDL scripts, read in
+// order, so later scripts can alter tables created by earlier ones. It
+// understands CREATE TABLE, CREATE INDEX, ALTER TABLE (adding columns,
+// keys, constraints and defaults), CREATE TYPE ... AS ENUM and COMMENT ON;
+// other statements, such as INSERT or CREATE VIEW, are skipped. This
+// covers the output of mysqldump --no-data and pg_dump --schema-only.
+//
+// Native types are mapped to schema types, so INTEGER becomes int and
+// TIMESTAMPTZ becomes timestamp, and a VARCHAR with a CHECK (col IN (...))
+// constraint becomes an enum, as package ddl writes them. Generators are
+// chosen from column names and types: email, phone, created_at and so on.
+// Foreign keys without actions get RESTRICT, and generation_order is
+// derived from the foreign keys.
+//
+// The schema is not validated. Anything Import had to approximate or
+// leave out, such as a composite primary key or an unsupported column
+// type, is reported as a warning located in the script. A script that
+// cannot be tokenized or parsed returns an *schema.Error.
+func Import(scripts []Script, opts ImportOptions) (*schema.Schema, schema.ErrorList, error) {
+	im := &importer{
+		tables: make(map[string]*tableDef),
+		enums:  make(map[string][]string),
+	}
+
+	var databases []string
+	switch {
+	case opts.Dialect != nil:
+		databases = []string{opts.Dialect.Name()}
+	default:
+		var text strings.Builder
+		for _, sc := range scripts {
+			text.WriteString(sc.Text)
+			text.WriteByte('\n')
+		}
+		if name := detectDialect(text.String()); name != "" {
+			databases = []string{name}
+		} else {
+			databases = append([]string{}, dialect.Names...)
+		}
+	}
+	if len(databases) == 1 {
+		im.dialect = databases[0]
+	}
+
+	for _, sc := range scripts {
+		if err := im.script(sc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	recordCount := opts.RecordCount
+	if recordCount <= 0 {
+		recordCount = DefaultRecordCount
+	}
+	name := opts.Name
+	if name == "" {
+		name = "imported"
+	}
+
+	im.finish()
+
+	s := &schema.Schema{
+		SchemaVersion: "1.0",
+		Name:          name,
+		Version:       "1.0.0",
+		DatabaseType:  databases,
+		Tables:        []schema.Table{},
+	}
+	for _, t := range im.order {
+		t.table.RecordCount = recordCount
+		s.Tables = append(s.Tables, t.table)
+		s.Metadata.TotalRecords += recordCount
+	}
+	if order, err := schema.DependencyOrder(s.Tables); err == nil {
+		s.GenerationOrder = order
+	}
+	return s, im.warnings, nil
+}
+
+// dialectHints are constructs only one of the two dialects accepts. MySQL
+// reads double quotes as strings, so a double-quoted table name is a
+// PostgreSQL hint.
+var dialectHints = map[string]*regexp.Regexp{
+	"mysql":    regexp.MustCompile("(?i)`|\\bAUTO_INCREMENT\\b|\\bENGINE\\s*=|\\bUNSIGNED\\b|\\b(TINY|MEDIUM|LONG)TEXT\\b|\\bDATETIME\\b|\\bCHARSET\\b"),
+	"postgres": regexp.MustCompile(`(?i)\b(BIG|SMALL)?SERIAL\b|::|\bAS\s+IDENTITY\b|\bTIMESTAMPTZ\b|\bJSONB\b|\bCREATE\s+TYPE\b|\bBYTEA\b|\bAS\s+ENUM\b|\[\]|\bCOMMENT\s+ON\b|\bOWNER\s+TO\b|\b(TABLE|ON|REFERENCES)\s+"`),
+}
+
+// detectDialect returns the database whose syntax the script uses, or ""
+// if it uses neither's or both's.
+func detectDialect(text string) string {
+	mysql := len(dialectHints["mysql"].FindAllStringIndex(text, -1))
+	postgres := len(dialectHints["postgres"].FindAllStringIndex(text, -1))
+	switch {
+	case mysql > postgres:
+		return "mysql"
+	case postgres > mysql:
+		return "postgres"
+	}
+	return ""
+}
+
+// importer accumulates tables across scripts.
+type importer struct {
+	// dialect is "mysql", "postgres", or "" for scripts targeting both
+	dialect string
+
+	tables map[string]*tableDef // by key(name)
+	order  []*tableDef          // in declaration order
+	enums  map[string][]string  // CREATE TYPE ... AS ENUM values, by key(name)
+
+	warnings schema.ErrorList
+
+	// The script being read
+	file  string
+	lines []string
+}
+
+// tableDef is a table under construction.
+type tableDef struct {
+	table schema.Table
+	at    position
+
+	// primaryKey lists the columns of the declared primary key
+	primaryKey []string
+	pkAt       position
+
+	// refs are the foreign keys, resolved once every table is known
+	refs []*reference
+
+	// uniques lists the single-column unique constraints and indexes
+	uniques map[string]bool
+}
+
+// reference is a foreign key as declared. column is empty when the
+// declaration names only the parent table, which means its primary key.
+type reference struct {
+	child, table, column string
+	onDelete, onUpdate   string
+	at                   position
+}
+
+// position locates a statement or clause in a script.
+type position struct {
+	file      string
+	line, col int
+	source    string
+}
+
+// key normalizes a name for lookups; table and column names match
+// regardless of case.
+func key(name string) string {
+	return strings.ToLower(name)
+}
+
+func (im *importer) script(sc Script) error {
+	im.file = sc.Name
+	im.lines = strings.Split(sc.Text, "\n")
+
+	stmts, err := tokenize(sc.Text, im.dialect == "mysql")
+	if err != nil {
+		return im.located(err)
+	}
+	for _, stmt := range stmts {
+		p := &parser{im: im, toks: stmt}
+		if err := p.statement(); err != nil {
+			return im.located(err)
+		}
+	}
+	return nil
+}
+
+// position returns where tok is.
+func (im *importer) position(tok token) position {
+	pos := position{file: im.file, line: tok.line, col: tok.col}
+	if tok.line >= 1 && tok.line <= len(im.lines) {
+		pos.source = strings.TrimRight(im.lines[tok.line-1], "\r")
+	}
+	return pos
+}
+
+// located turns a syntax error into an *schema.Error pointing into the
+// current script.
+func (im *importer) located(err error) error {
+	se, ok := err.(*syntaxError)
+	if !ok {
+		return err
+	}
+	pos := im.position(token{line: se.line, col: se.col})
+	return &schema.Error{
+		Severity: schema.SeverityError,
+		File:     pos.file,
+		Line:     pos.line,
+		Column:   pos.col,
+		Source:   pos.source,
+		Err:      se.err,
+	}
+}
+
+// warn records something Import approximated or left out.
+func (im *importer) warn(at position, format string, args ...interface{}) {
+	im.warnings = append(im.warnings, &schema.Error{
+		Severity: schema.SeverityWarning,
+		File:     at.file,
+		Line:     at.line,
+		Column:   at.col,
+		Source:   at.source,
+		Err:      fmt.Errorf(format, args...),
+	})
+}
+
+// table returns the named table, warning when it was never created.
+func (im *importer) table(name string, at position) *tableDef {
+	t := im.tables[key(name)]
+	if t == nil {
+		im.warn(at, "table '%s' is not created by the script; statement skipped", name)
+	}
+	return t
+}
+
+// parser reads one statement.
+type parser struct {
+	im   *importer
+	toks []token
+	pos  int
+}
+
+// peek returns the next token without consuming it. At the end of the
+// statement it returns an empty symbol positioned after the last token.
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset < len(p.toks) {
+		return p.toks[p.pos+offset]
+	}
+	end := token{kind: tokSymbol}
+	if n := len(p.toks); n > 0 {
+		last := p.toks[n-1]
+		end.line, end.col = last.line, last.col+len(last.text)
+	}
+	return end
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if p.pos < len(p.toks) {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+// accept consumes the keywords words if the statement continues with all
+// of them.
+func (p *parser) accept(words ...string) bool {
+	for i, w := range words {
+		if !p.peekAt(i).is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) acceptSymbol(s string) bool {
+	if p.peek().isSymbol(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &syntaxError{line: tok.line, col: tok.col, err: fmt.Errorf(format, args...)}
+}
+
+// expected reports the next token as unexpected.
+func (p *parser) expected(what string) error {
+	tok := p.peek()
+	if p.done() {
+		return p.errorf(tok, "expected %s, found end of statement", what)
+	}
+	return p.errorf(tok, "expected %s, found %q", what, tok.text)
+}
+
+func (p *parser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return p.expected(strconv.Quote(s))
+	}
+	return nil
+}
+
+// name reads an identifier. Unquoted PostgreSQL identifiers fold to lower
+// case, as the server does.
+func (p *parser) name() (string, error) {
+	tok := p.peek()
+	if !tok.name() {
+		return "", p.expected("a name")
+	}
+	p.pos++
+	if tok.kind == tokWord && p.im.dialect == "postgres" {
+		return strings.ToLower(tok.text), nil
+	}
+	return tok.text, nil
+}
+
+// qualifiedName reads a possibly qualified name, such as public.users, and
+// returns its parts.
+func (p *parser) qualifiedName() ([]string, error) {
+	var parts []string
+	for {
+		part, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		if !p.acceptSymbol(".") {
+			return parts, nil
+		}
+	}
+}
+
+// tableName reads a possibly schema-qualified table name and drops the
+// schema.
+func (p *parser) tableName() (string, error) {
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return "", err
+	}
+	return parts[len(parts)-1], nil
+}
+
+// group reads a parenthesized list and returns the tokens between the
+// parentheses.
+func (p *parser) group() ([]token, error) {
+	open := p.peek()
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	start, depth := p.pos, 1
+	for !p.done() {
+		tok := p.next()
+		switch {
+		case tok.isSymbol("("):
+			depth++
+		case tok.isSymbol(")"):
+			depth--
+			if depth == 0 {
+				return p.toks[start : p.pos-1], nil
+			}
+		}
+	}
+	return nil, p.errorf(open, "unbalanced parenthesis")
+}
+
+// skipGroup skips a parenthesized list if one comes next.
+func (p *parser) skipGroup() error {
+	if p.peek().isSymbol("(") {
+		_, err := p.group()
+		return err
+	}
+	return nil
+}
+
+// split splits tokens at top-level commas.
+func split(toks []token) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+	for i, tok := range toks {
+		switch {
+		case tok.isSymbol("(") || tok.isSymbol("["):
+			depth++
+		case tok.isSymbol(")") || tok.isSymbol("]"):
+			depth--
+		case tok.isSymbol(",") && depth == 0:
+			parts = append(parts, toks[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
+
+// sub returns a parser for a slice of the statement.
+func (p *parser) sub(toks []token) *parser {
+	return &parser{im: p.im, toks: toks}
+}
+
+// statement dispatches on the statement's leading keywords.
+func (p *parser) statement() error {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for _, modifier := range []string{"GLOBAL", "LOCAL", "TEMPORARY", "TEMP", "UNLOGGED"} {
+			p.accept(modifier)
+		}
+		switch {
+		case p.accept("TABLE"):
+			return p.createTable()
+		case p.peek().is("INDEX") || p.peek().is("UNIQUE") || p.peek().is("FULLTEXT") || p.peek().is("SPATIAL"):
+			return p.createIndex()
+		case p.accept("TYPE"):
+			return p.createType()
+		}
+	case p.accept("ALTER", "TABLE"):
+		return p.alterTable()
+	case p.accept("COMMENT", "ON"):
+		return p.comment()
+	}
+	return nil
+}
+
+func (p *parser) createTable() error {
+	start := p.peek()
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	at := p.im.position(start)
+
+	if !p.peek().isSymbol("(") {
+		p.im.warn(at, "table '%s' is not defined by a column list (CREATE TABLE ... AS, LIKE or PARTITION OF); skipped", name)
+		return nil
+	}
+	if p.im.tables[key(name)] != nil {
+		p.im.warn(at, "table '%s' is created twice; the second definition is skipped", name)
+		return nil
+	}
+
+	t := &tableDef{table: schema.Table{Name: name}, at: at, uniques: make(map[string]bool)}
+	body, err := p.group()
+	if err != nil {
+		return err
+	}
+	for _, elem := range split(body) {
+		if len(elem) == 0 {
+			continue
+		}
+		if err := p.sub(elem).tableElement(t); err != nil {
+			return err
+		}
+	}
+
+	// MySQL table options: only the comment is kept
+	for !p.done() {
+		if p.accept("COMMENT") {
+			p.acceptSymbol("=")
+			if tok := p.next(); tok.kind == tokString {
+				t.table.Description = tok.text
+			}
+			continue
+		}
+		p.next()
+	}
+
+	p.im.tables[key(name)] = t
+	p.im.order = append(p.im.order, t)
+	return nil
+}
+
+// tableElement reads a column definition or a table constraint.
+func (p *parser) tableElement(t *tableDef) error {
+	switch {
+	case p.peek().is("CONSTRAINT"), p.peek().is("PRIMARY"), p.peek().is("FOREIGN"), p.peek().is("CHECK"),
+		p.peek().is("UNIQUE"), p.peek().is("INDEX"), p.peek().is("KEY"), p.peek().is("FULLTEXT"),
+		p.peek().is("SPATIAL"), p.peek().is("EXCLUDE"):
+		return p.constraint(t)
+	case p.peek().is("LIKE") || p.peek().is("PERIOD"):
+		p.im.warn(p.im.position(p.peek()), "table '%s': %s clause ignored", t.table.Name, strings.ToUpper(p.peek().text))
+		return nil
+	}
+
+	col, err := p.column(t)
+	if err != nil || col == nil {
+		return err
+	}
+	if t.column(col.Name) != nil {
+		p.im.warn(p.im.position(p.toks[0]), "table '%s': column '%s' is defined twice; the second definition is skipped", t.table.Name, col.Name)
+		return nil
+	}
+	t.table.Columns = append(t.table.Columns, *col)
+	return nil
+}
+
+// column returns the table's column with the given name, or nil.
+func (t *tableDef) column(name string) *schema.Column {
+	for i := range t.table.Columns {
+		if key(t.table.Columns[i].Name) == key(name) {
+			return &t.table.Columns[i]
+		}
+	}
+	return nil
+}
+
+// constraint reads a table constraint or, in MySQL, an index definition.
+func (p *parser) constraint(t *tableDef) error {
+	start := p.peek()
+	var name string
+	if p.accept("CONSTRAINT") {
+		if !p.peek().is("PRIMARY") && !p.peek().is("UNIQUE") && !p.peek().is("FOREIGN") && !p.peek().is("CHECK") {
+			var err error
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+	}
+	at := p.im.position(start)
+
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		p.indexMethod()
+		cols, err := p.columnList(t)
+		if err != nil || cols == nil {
+			return err
+		}
+		t.primaryKey, t.pkAt = cols, at
+
+	case p.accept("UNIQUE"):
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		if p.peek().name() && !p.peek().is("USING") {
+			var err error
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		method := p.indexMethod()
+		cols, err := p.columnList(t)
+		if err != nil || cols == nil {
+			return err
+		}
+		if m := p.indexMethod(); m != "" {
+			method = m
+		}
+		p.addIndex(t, name, cols, method, true)
+
+	case p.accept("FOREIGN", "KEY"):
+		if p.peek().name() {
+			// MySQL allows an index name here
+			if _, err := p.name(); err != nil {
+				return err
+			}
+		}
+		cols, err := p.columnList(t)
+		if err != nil || cols == nil {
+			return err
+		}
+		ref, err := p.references(t, cols, at)
+		if err != nil || ref == nil {
+			return err
+		}
+		t.refs = append(t.refs, ref)
+
+	case p.accept("CHECK"):
+		check, err := p.group()
+		if err != nil {
+			return err
+		}
+		p.checkEnum(t, "", check)
+
+	case p.accept("EXCLUDE"):
+		p.im.warn(at, "table '%s': EXCLUDE constraint ignored", t.table.Name)
+
+	case p.peek().is("FULLTEXT") || p.peek().is("SPATIAL"):
+		kind := strings.ToUpper(p.next().text)
+		p.im.warn(at, "table '%s': %s index ignored", t.table.Name, kind)
+
+	case p.accept("INDEX") || p.accept("KEY"):
+		if p.peek().name() && !p.peek().is("USING") {
+			var err error
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		method := p.indexMethod()
+		cols, err := p.columnList(t)
+		if err != nil || cols == nil {
+			return err
+		}
+		if m := p.indexMethod(); m != "" {
+			method = m
+		}
+		p.addIndex(t, name, cols, method, false)
+
+	default:
+		return p.expected("a column or table constraint")
+	}
+	return nil
+}
+
+// indexMethod reads an optional USING clause and returns the method in
+// upper case, such as "BTREE".
+func (p *parser) indexMethod() string {
+	if p.accept("USING") && p.peek().name() {
+		return strings.ToUpper(p.next().text)
+	}
+	return ""
+}
+
+// columnList reads a parenthesized list of key columns. Lengths, sort
+// orders and operator classes are dropped. It returns nil, with a warning,
+// when an entry is an expression rather than a column.
+func (p *parser) columnList(t *tableDef) ([]string, error) {
+	start := p.peek()
+	body, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	var cols []string
+	for _, elem := range split(body) {
+		sp := p.sub(elem)
+		if len(elem) == 0 || !elem[0].name() {
+			p.im.warn(p.im.position(start), "table '%s': key on an expression ignored", t.table.Name)
+			return nil, nil
+		}
+		name, _ := sp.name()
+		// name(10) is a MySQL prefix length; lower(name) a function
+		if sp.peek().isSymbol("(") && t.column(name) == nil {
+			p.im.warn(p.im.position(start), "table '%s': key on an expression ignored", t.table.Name)
+			return nil, nil
+		}
+		if col := t.column(name); col != nil {
+			name = col.Name
+		}
+		cols = append(cols, name)
+	}
+	if len(cols) == 0 {
+		return nil, p.errorf(start, "empty column list")
+	}
+	return cols, nil
+}
+
+// addIndex records an index. A unique index or constraint on a single
+// column also makes the column unique, so generated values are.
+func (p *parser) addIndex(t *tableDef, name string, cols []string, method string, unique bool) {
+	if name == "" {
+		suffix := "idx"
+		if unique {
+			suffix = "key"
+		}
+		name = t.table.Name + "_" + strings.Join(cols, "_") + "_" + suffix
+	}
+	if unique && len(cols) == 1 {
+		t.uniques[key(cols[0])] = true
+	}
+	t.table.Indexes = append(t.table.Indexes, schema.Index{Name: name, Columns: cols, Type: method, Unique: unique})
+}
+
+// references reads a REFERENCES clause for the columns cols. Multi-column
+// foreign keys are not supported and return nil with a warning.
+func (p *parser) references(t *tableDef, cols []string, at position) (*reference, error) {
+	if !p.accept("REFERENCES") {
+		return nil, p.expected("REFERENCES")
+	}
+	parent, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	var parentCols []string
+	if p.peek().isSymbol("(") {
+		body, err := p.group()
+		if err != nil {
+			return nil, err
+		}
+		for _, elem := range split(body) {
+			if len(elem) > 0 && elem[0].name() {
+				name, _ := p.sub(elem).name()
+				parentCols = append(parentCols, name)
+			}
+		}
+	}
+
+	ref := &reference{child: cols[0], table: parent, at: at}
+	if len(parentCols) > 0 {
+		ref.column = parentCols[0]
+	}
+	for more := true; more && !p.done(); {
+		switch {
+		case p.accept("ON", "DELETE"):
+			ref.onDelete = p.referentialAction()
+		case p.accept("ON", "UPDATE"):
+			ref.onUpdate = p.referentialAction()
+		case p.accept("MATCH"), p.accept("INITIALLY"):
+			p.next()
+		case p.accept("NOT", "DEFERRABLE"), p.accept("DEFERRABLE"):
+		default:
+			more = false
+		}
+	}
+	if len(cols) > 1 || len(parentCols) > 1 {
+		p.im.warn(at, "table '%s': multi-column foreign key (%s) ignored", t.table.Name, strings.Join(cols, ", "))
+		return nil, nil
+	}
+	return ref, nil
+}
+
+// referentialAction reads an action such as CASCADE or SET NULL.
+func (p *parser) referentialAction() string {
+	switch {
+	case p.accept("SET", "NULL"):
+		return "SET NULL"
+	case p.accept("SET", "DEFAULT"):
+		return "SET DEFAULT"
+	case p.accept("NO", "ACTION"):
+		return "NO ACTION"
+	}
+	return strings.ToUpper(p.next().text)
+}
+
+// checkEnum turns CHECK (col IN ('a', 'b')), and the
+// col = ANY (ARRAY[...]) form pg_dump writes, on a string column into an
+// enum type, which is how package ddl renders enums for PostgreSQL. colName
+// names the column of an inline check; other checks are ignored.
+func (p *parser) checkEnum(t *tableDef, colName string, check []token) {
+	var values []string
+	listed := false
+	for _, tok := range check {
+		switch {
+		case tok.kind == tokString:
+			values = append(values, tok.text)
+		case tok.is("IN") || tok.is("ANY"):
+			listed = true
+		case tok.is("AND") || tok.is("OR") || tok.is("NOT") || tok.isSymbol("<") || tok.isSymbol(">") ||
+			tok.isSymbol("<=") || tok.isSymbol(">=") || tok.isSymbol("<>") || tok.isSymbol("!="):
+			return
+		case tok.name():
+			name := tok.text
+			if tok.kind == tokWord && p.im.dialect == "postgres" {
+				name = strings.ToLower(name)
+			}
+			if t.column(name) == nil {
+				continue // a cast such as ::text
+			}
+			if colName != "" && key(name) != key(colName) {
+				return
+			}
+			colName = name
+		}
+	}
+	if !listed || colName == "" || len(values) == 0 {
+		return
+	}
+
+	col := t.column(colName)
+	if col == nil || col.DataType().Family() != schema.FamilyString || col.DataType().Array {
+		return
+	}
+	col.Type = enumType(values)
+}
+
+// enumType renders an enum type with the given values.
+func enumType(values []string) string {
+	quoted := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			quoted = append(quoted, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+		}
+	}
+	return "enum(" + strings.Join(quoted, ",") + ")"
+}
+
+// column reads a column definition and its inline constraints. It
+// returns nil for generated columns, whose values the database computes.
+func (p *parser) column(t *tableDef) (*schema.Column, error) {
+	start := p.peek()
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	at := p.im.position(start)
+
+	ts, err := p.dataType()
+	if err != nil {
+		return nil, err
+	}
+	col := &schema.Column{Name: name, Nullable: true}
+	col.Type, col.AutoIncrement = p.im.schemaType(ts, t.table.Name, name, at)
+
+	var check []token
+	computed := false
+	for !p.done() {
+		tok := p.peek()
+		switch {
+		case p.accept("NOT", "NULL"):
+			col.Nullable = false
+		case p.accept("NULL"):
+			col.Nullable = true
+		case p.accept("DEFAULT"):
+			if err := p.defaultValue(col, t, at); err != nil {
+				return nil, err
+			}
+		case p.accept("PRIMARY", "KEY"):
+			t.primaryKey, t.pkAt = []string{name}, at
+			col.Nullable = false
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			t.uniques[key(name)] = true
+		case p.accept("KEY"):
+			// MySQL: KEY alone in a column definition means PRIMARY KEY
+			t.primaryKey, t.pkAt = []string{name}, at
+			col.Nullable = false
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"):
+			col.AutoIncrement = true
+		case p.accept("GENERATED"):
+			p.accept("ALWAYS")
+			p.accept("BY", "DEFAULT")
+			p.accept("ON", "NULL")
+			if !p.accept("AS") {
+				return nil, p.expected("AS")
+			}
+			if p.accept("IDENTITY") {
+				col.AutoIncrement = true
+				if err := p.skipGroup(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			computed = true
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		case p.accept("AS"):
+			// MySQL generated column: AS (expr) [VIRTUAL | STORED]
+			computed = true
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		case p.accept("REFERENCES"):
+			p.pos--
+			ref, err := p.references(t, []string{name}, at)
+			if err != nil {
+				return nil, err
+			}
+			if ref != nil {
+				t.refs = append(t.refs, ref)
+			}
+		case p.accept("CHECK"):
+			if check, err = p.group(); err != nil {
+				return nil, err
+			}
+		case p.accept("COMMENT"):
+			if tok := p.next(); tok.kind == tokString {
+				col.Description = tok.text
+			}
+		case p.accept("CONSTRAINT"):
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+		case p.accept("ON", "UPDATE"):
+			// MySQL: ON UPDATE CURRENT_TIMESTAMP
+			p.next()
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		case p.accept("COLLATE"), p.accept("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLUMN_FORMAT"),
+			p.accept("STORAGE"), p.accept("COMPRESSION"), p.accept("SRID"), p.accept("ENGINE_ATTRIBUTE"):
+			p.acceptSymbol("=")
+			if _, err := p.qualifiedName(); err != nil && !p.done() {
+				p.next()
+			}
+		case tok.kind == tokWord:
+			// Anything else (VISIBLE, DEFERRABLE, STORED, ...) is a
+			// keyword with no bearing on the schema
+			p.next()
+			if err := p.skipGroup(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.expected("a column constraint")
+		}
+	}
+
+	if computed {
+		p.im.warn(at, "table '%s': generated column '%s' left out: the database computes its values", t.table.Name, name)
+		return nil, nil
+	}
+	if len(check) > 0 {
+		// Checked after the loop so the column exists for checkEnum
+		probe := &tableDef{table: schema.Table{Name: t.table.Name, Columns: []schema.Column{*col}}}
+		p.checkEnum(probe, name, check)
+		col.Type = probe.table.Columns[0].Type
+	}
+	return col, nil
+}
+
+// typeSpec is a native column type as written.
+type typeSpec struct {
+	name     string   // lowercased words, such as "character varying"
+	args     []string // arguments; strings keep their quotes
+	unsigned bool
+	array    bool
+	text     string // as written, for messages
+}
+
+// typeWords can continue a multi-word type name.
+var typeWords = map[string]bool{"varying": true, "precision": true, "unsigned": true, "signed": true, "zerofill": true}
+
+// dataType reads a column type, such as VARCHAR(255), double precision,
+// timestamp(3) with time zone, int unsigned or integer[].
+func (p *parser) dataType() (typeSpec, error) {
+	start := p.pos
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return typeSpec{}, err
+	}
+	ts := typeSpec{name: strings.ToLower(parts[len(parts)-1])}
+
+	for more := true; more && !p.done(); {
+		tok := p.peek()
+		word := strings.ToLower(tok.text)
+		switch {
+		case tok.isSymbol("(") && ts.args == nil:
+			body, err := p.group()
+			if err != nil {
+				return typeSpec{}, err
+			}
+			for _, arg := range split(body) {
+				var b strings.Builder
+				for _, t := range arg {
+					if t.kind == tokString {
+						b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+					} else {
+						b.WriteString(t.text)
+					}
+				}
+				ts.args = append(ts.args, b.String())
+			}
+			if ts.args == nil {
+				ts.args = []string{}
+			}
+		case tok.isSymbol("["):
+			p.next()
+			if p.peek().kind == tokNumber {
+				p.next()
+			}
+			if err := p.expectSymbol("]"); err != nil {
+				return typeSpec{}, err
+			}
+			ts.array = true
+		case tok.is("ARRAY"):
+			p.next()
+			ts.array = true
+		case tok.kind == tokWord && (word == "with" || word == "without") && p.peekAt(1).is("TIME") && p.peekAt(2).is("ZONE"):
+			p.pos += 3
+			if word == "with" {
+				ts.name += " with time zone"
+			}
+		case tok.kind == tokWord && typeWords[word]:
+			p.next()
+			switch word {
+			case "unsigned":
+				ts.unsigned = true
+			case "varying", "precision":
+				ts.name += " " + word
+			}
+		case tok.kind == tokWord && (ts.name == "national" || (ts.name == "long" && word == "varchar")):
+			p.next()
+			ts.name += " " + word
+		default:
+			more = false
+		}
+	}
+	var b strings.Builder
+	for i, t := range p.toks[start:p.pos] {
+		if i > 0 && t.kind != tokSymbol && p.toks[start+i-1].kind != tokSymbol {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	ts.text = b.String()
+	return ts, nil
+}
+
+// nativeTypes maps native type names to schema base types. Types that take
+// their arguments along are marked; the others drop them (display widths,
+// for instance).
+var nativeTypes = map[string]struct {
+	base     string
+	keepArgs bool
+}{
+	"int": {"int", false}, "integer": {"int", false}, "int4": {"int", false}, "mediumint": {"int", false},
+	"bigint": {"bigint", false}, "int8": {"bigint", false},
+	"smallint": {"smallint", false}, "int2": {"smallint", false},
+	"tinyint": {"tinyint", false},
+	"decimal": {"decimal", true}, "numeric": {"decimal", true}, "dec": {"decimal", true}, "fixed": {"decimal", true},
+	"float": {"float", true}, "real": {"float", false}, "float4": {"float", false},
+	"double": {"double", false}, "double precision": {"double", false}, "float8": {"double", false},
+	"varchar": {"varchar", true}, "character varying": {"varchar", true}, "char varying": {"varchar", true},
+	"nvarchar": {"varchar", true}, "national varchar": {"varchar", true}, "national character varying": {"varchar", true},
+	"char": {"char", true}, "character": {"char", true}, "nchar": {"char", true}, "bpchar": {"char", true}, "national char": {"char", true},
+	"text": {"text", false}, "tinytext": {"text", false}, "mediumtext": {"text", false}, "longtext": {"text", false},
+	"citext": {"text", false}, "long varchar": {"text", false},
+	"date":     {"date", false},
+	"datetime": {"datetime", true},
+	"boolean":  {"boolean", false}, "bool": {"boolean", false},
+	"bit":  {"bit", true},
+	"json": {"json", false}, "jsonb": {"jsonb", false},
+	"enum": {"enum", true},
+}
+
+// schemaType maps a native type to a schema type, and reports whether the
+// type implies an auto-incrementing column (SERIAL). Types with no schema
+// equivalent become text, with a warning.
+func (im *importer) schemaType(ts typeSpec, table, column string, at position) (string, bool) {
+	autoIncrement := false
+	base, args := "", ts.args
+
+	switch name := ts.name; {
+	case name == "serial" || name == "serial4":
+		base, autoIncrement = "int", true
+	case name == "bigserial" || name == "serial8":
+		base, autoIncrement = "bigint", true
+	case name == "smallserial" || name == "serial2":
+		base, autoIncrement = "smallint", true
+	case name == "tinyint" && len(args) == 1 && args[0] == "1" && im.dialect != "postgres":
+		// MySQL's BOOLEAN is TINYINT(1)
+		base, args = "boolean", nil
+	case name == "timestamp":
+		// PostgreSQL's timestamp has no time zone, like schema datetime;
+		// schema timestamp is TIMESTAMPTZ there
+		base = "timestamp"
+		if im.dialect == "postgres" {
+			base = "datetime"
+		}
+	case name == "timestamptz" || name == "timestamp with time zone":
+		base = "timestamp"
+	case name == "uuid":
+		base, args = "char", []string{"36"}
+	case name == "money":
+		base, args = "decimal", []string{"19", "2"}
+	case name == "year":
+		base = "smallint"
+	case im.enums[key(name)] != nil:
+		return enumType(im.enums[key(name)]), false
+	default:
+		native, ok := nativeTypes[name]
+		if !ok {
+			im.warn(at, "table '%s': column '%s': type %s has no schema equivalent and was imported as text; give the column a generator that produces valid values",
+				table, column, ts.text)
+			return "text", false
+		}
+		base = native.base
+		if !native.keepArgs {
+			args = nil
+		}
+	}
+
+	// Timestamps keep their fractional-second precision
+	if (base == "timestamp" || base == "datetime") && len(ts.args) == 1 {
+		args = ts.args
+	}
+	if base == "varchar" && len(args) == 0 {
+		base = "text"
+	}
+
+	typ := base
+	if len(args) > 0 {
+		typ += "(" + strings.Join(args, ",") + ")"
+	}
+	if ts.unsigned {
+		typ += " unsigned"
+	}
+	if ts.array {
+		typ += "[]"
+	}
+	parsed, err := schema.ParseDataType(typ)
+	if err != nil {
+		im.warn(at, "table '%s': column '%s': type %s was imported as text: %v", table, column, ts.text, err)
+		return "text", autoIncrement
+	}
+	return parsed.String(), autoIncrement
+}
+
+// defaultExpressionsIn are the SQL expressions kept as defaults, keyed by
+// their upper-cased function name.
+var defaultExpressionsIn = map[string]string{
+	"CURRENT_TIMESTAMP": "CURRENT_TIMESTAMP",
+	"LOCALTIMESTAMP":    "CURRENT_TIMESTAMP",
+	"NOW":               "CURRENT_TIMESTAMP",
+	"CURRENT_DATE":      "CURRENT_DATE",
+}
+
+// defaultValue reads a DEFAULT expression. Literals become the column's
+// default; nextval() makes the column auto-increment and UUID functions
+// give it the uuid generator. Other expressions are dropped with a
+// warning, since generation could not reproduce them.
+func (p *parser) defaultValue(col *schema.Column, t *tableDef, at position) error {
+	start := p.pos
+	negative := p.acceptSymbol("-")
+	p.acceptSymbol("+")
+
+	first := p.next()
+	if first.isSymbol("(") {
+		p.pos--
+		inner, err := p.group()
+		if err != nil {
+			return err
+		}
+		// DEFAULT ('x') or DEFAULT (now()): look inside
+		if len(inner) > 0 {
+			sp := p.sub(inner)
+			if err := sp.defaultValue(col, t, at); err != nil {
+				return err
+			}
+		}
+		p.skipCasts()
+		return nil
+	}
+	call := false
+	if first.kind == tokWord && p.peek().isSymbol("(") {
+		call = true
+		if err := p.skipGroup(); err != nil {
+			return err
+		}
+	}
+	p.skipCasts()
+	if !p.done() && !p.atConstraint() {
+		// An operator expression, such as now() + interval '1 day'
+		for !p.done() && !p.atConstraint() {
+			p.next()
+		}
+		first = token{}
+	}
+
+	var value string
+	switch {
+	case first.kind == tokString && !negative:
+		value = first.text
+	case first.kind == tokNumber:
+		value = first.text
+		if negative {
+			value = "-" + value
+		}
+	case first.is("TRUE") || first.is("FALSE"):
+		value = strings.ToLower(first.text)
+	case first.is("NULL"):
+		col.Default = nil
+		return nil
+	case first.kind == tokWord && defaultExpressionsIn[strings.ToUpper(first.text)] != "":
+		value = defaultExpressionsIn[strings.ToUpper(first.text)]
+	case first.is("nextval") && call:
+		col.AutoIncrement = true
+		return nil
+	case (first.is("gen_random_uuid") || first.is("uuid_generate_v4") || first.is("uuid")) && call:
+		col.Generator = "uuid"
+		return nil
+	default:
+		p.im.warn(at, "table '%s': column '%s': default %s is not a literal and was left out", t.table.Name, col.Name, tokensText(p.toks[start:p.pos]))
+		return nil
+	}
+
+	// Booleans are written as true/false
+	if col.DataType().Family() == schema.FamilyBoolean {
+		if b, err := strconv.ParseBool(value); err == nil {
+			value = strconv.FormatBool(b)
+		}
+	}
+	if !fitsType(value, col.DataType()) {
+		p.im.warn(at, "table '%s': column '%s': default '%s' is not a valid %s and was left out", t.table.Name, col.Name, value, col.Type)
+		return nil
+	}
+	col.Default = &value
+	return nil
+}
+
+// fitsType reports whether a default can be read as a value of type t, as
+// generation needs, so MySQL zero dates and the like are dropped.
+func fitsType(value string, t schema.DataType) bool {
+	if t.Array {
+		return true
+	}
+	switch t.Family() {
+	case schema.FamilyInteger:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case schema.FamilyNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case schema.FamilyBoolean:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case schema.FamilyDate, schema.FamilyDatetime:
+		if value == "CURRENT_TIMESTAMP" || value == "CURRENT_DATE" {
+			return true
+		}
+		for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// skipCasts skips PostgreSQL casts, such as ::character varying.
+func (p *parser) skipCasts() {
+	for p.acceptSymbol("::") {
+		_, _ = p.dataType()
+	}
+}
+
+// atConstraint reports whether a column constraint keyword comes next,
+// which ends a DEFAULT expression.
+func (p *parser) atConstraint() bool {
+	for _, word := range []string{"NOT", "NULL", "PRIMARY", "UNIQUE", "KEY", "REFERENCES", "CHECK", "CONSTRAINT",
+		"COMMENT", "AUTO_INCREMENT", "GENERATED", "COLLATE", "ON", "CHARACTER", "CHARSET", "DEFAULT"} {
+		if p.peek().is(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokensText renders tokens roughly as written, for messages.
+func tokensText(toks []token) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 && t.kind != tokSymbol && toks[i-1].kind != tokSymbol {
+			b.WriteByte(' ')
+		}
+		if t.kind == tokString {
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		} else {
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+func (p *parser) createIndex() error {
+	start := p.peek()
+	at := p.im.position(start)
+	unique := p.accept("UNIQUE")
+	if p.peek().is("FULLTEXT") || p.peek().is("SPATIAL") {
+		p.im.warn(at, "%s index ignored", strings.ToUpper(p.next().text))
+		return nil
+	}
+	if !p.accept("INDEX") {
+		return p.expected("INDEX")
+	}
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+
+	var name string
+	if !p.peek().is("ON") {
+		var err error
+		if name, err = p.tableName(); err != nil {
+			return err
+		}
+	}
+	method := p.indexMethod()
+	if !p.accept("ON") {
+		return p.expected("ON")
+	}
+	p.accept("ONLY")
+	table, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if m := p.indexMethod(); m != "" {
+		method = m
+	}
+
+	t := p.im.table(table, at)
+	if t == nil {
+		return nil
+	}
+	cols, err := p.columnList(t)
+	if err != nil || cols == nil {
+		return err
+	}
+	if m := p.indexMethod(); m != "" {
+		method = m
+	}
+	for !p.done() {
+		if p.next().is("WHERE") {
+			p.im.warn(at, "table '%s': partial index '%s' imported without its WHERE clause", t.table.Name, name)
+			break
+		}
+	}
+	p.addIndex(t, name, cols, method, unique)
+	return nil
+}
+
+func (p *parser) createType() error {
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("AS", "ENUM") {
+		return nil
+	}
+	body, err := p.group()
+	if err != nil {
+		return err
+	}
+	var values []string
+	for _, tok := range body {
+		if tok.kind == tokString {
+			values = append(values, tok.text)
+		}
+	}
+	if len(values) > 0 {
+		p.im.enums[key(name)] = values
+	}
+	return nil
+}
+
+func (p *parser) alterTable() error {
+	start := p.peek()
+	at := p.im.position(start)
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	t := p.im.table(name, at)
+	if t == nil {
+		return nil
+	}
+	for _, action := range split(p.toks[p.pos:]) {
+		if len(action) == 0 {
+			continue
+		}
+		if err := p.sub(action).alterAction(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alterAction applies one ALTER TABLE action.
+func (p *parser) alterAction(t *tableDef) error {
+	start := p.peek()
+	at := p.im.position(start)
+
+	switch {
+	case p.accept("ADD"):
+		switch {
+		case p.peek().is("CONSTRAINT"), p.peek().is("PRIMARY"), p.peek().is("FOREIGN"), p.peek().is("CHECK"),
+			p.peek().is("UNIQUE"), p.peek().is("INDEX"), p.peek().is("KEY"), p.peek().is("FULLTEXT"),
+			p.peek().is("SPATIAL"), p.peek().is("EXCLUDE"):
+			return p.constraint(t)
+		}
+		p.accept("COLUMN")
+		p.accept("IF", "NOT", "EXISTS")
+		col, err := p.column(t)
+		if err != nil || col == nil {
+			return err
+		}
+		if t.column(col.Name) != nil {
+			p.im.warn(at, "table '%s': column '%s' already exists; ADD COLUMN skipped", t.table.Name, col.Name)
+			return nil
+		}
+		t.table.Columns = append(t.table.Columns, *col)
+
+	case p.accept("ALTER"):
+		p.accept("COLUMN")
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		col := t.column(name)
+		if col == nil {
+			p.im.warn(at, "table '%s': column '%s' does not exist; ALTER COLUMN skipped", t.table.Name, name)
+			return nil
+		}
+		switch {
+		case p.accept("SET", "DEFAULT"):
+			return p.defaultValue(col, t, at)
+		case p.accept("DROP", "DEFAULT"):
+			col.Default = nil
+		case p.accept("SET", "NOT", "NULL"):
+			col.Nullable = false
+		case p.accept("DROP", "NOT", "NULL"):
+			col.Nullable = true
+		case p.accept("ADD", "GENERATED"):
+			col.AutoIncrement = true
+		case p.accept("SET", "DATA", "TYPE"), p.accept("TYPE"):
+			ts, err := p.dataType()
+			if err != nil {
+				return err
+			}
+			col.Type, _ = p.im.schemaType(ts, t.table.Name, col.Name, at)
+		}
+
+	case p.accept("MODIFY"), p.accept("CHANGE"):
+		changed := p.toks[0].is("CHANGE")
+		p.accept("COLUMN")
+		old := ""
+		if changed {
+			var err error
+			if old, err = p.name(); err != nil {
+				return err
+			}
+		}
+		col, err := p.column(t)
+		if err != nil || col == nil {
+			return err
+		}
+		if old == "" {
+			old = col.Name
+		}
+		existing := t.column(old)
+		if existing == nil {
+			p.im.warn(at, "table '%s': column '%s' does not exist; %s skipped", t.table.Name, old, strings.ToUpper(p.toks[0].text))
+			return nil
+		}
+		p.renameColumn(t, existing.Name, col.Name)
+		*existing = *col
+
+	case p.accept("DROP"):
+		if p.peek().is("CONSTRAINT") || p.peek().is("INDEX") || p.peek().is("KEY") || p.peek().is("PRIMARY") || p.peek().is("FOREIGN") {
+			p.im.warn(at, "table '%s': DROP %s ignored", t.table.Name, strings.ToUpper(p.peek().text))
+			return nil
+		}
+		p.accept("COLUMN")
+		p.accept("IF", "EXISTS")
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		for i := range t.table.Columns {
+			if key(t.table.Columns[i].Name) == key(name) {
+				t.table.Columns = append(t.table.Columns[:i], t.table.Columns[i+1:]...)
+				break
+			}
+		}
+
+	case p.accept("RENAME", "COLUMN"):
+		from, err := p.name()
+		if err != nil {
+			return err
+		}
+		if !p.accept("TO") {
+			return p.expected("TO")
+		}
+		to, err := p.name()
+		if err != nil {
+			return err
+		}
+		if col := t.column(from); col != nil {
+			p.renameColumn(t, col.Name, to)
+			col.Name = to
+		}
+	}
+	// Everything else (OWNER TO, ENABLE TRIGGER, ENGINE=...) has no bearing
+	// on the schema
+	return nil
+}
+
+// renameColumn updates the keys and foreign keys that name a column.
+func (p *parser) renameColumn(t *tableDef, from, to string) {
+	if key(from) == key(to) {
+		return
+	}
+	rename := func(names []string) {
+		for i := range names {
+			if key(names[i]) == key(from) {
+				names[i] = to
+			}
+		}
+	}
+	rename(t.primaryKey)
+	for i := range t.table.Indexes {
+		rename(t.table.Indexes[i].Columns)
+	}
+	for _, ref := range t.refs {
+		if key(ref.child) == key(from) {
+			ref.child = to
+		}
+	}
+	if t.uniques[key(from)] {
+		delete(t.uniques, key(from))
+		t.uniques[key(to)] = true
+	}
+}
+
+func (p *parser) comment() error {
+	var target string
+	switch {
+	case p.accept("TABLE"):
+		target = "table"
+	case p.accept("COLUMN"):
+		target = "column"
+	default:
+		return nil
+	}
+	start := p.peek()
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("IS") {
+		return p.expected("IS")
+	}
+	text := p.next()
+	if text.kind != tokString {
+		return nil
+	}
+
+	at := p.im.position(start)
+	if target == "table" {
+		if t := p.im.table(parts[len(parts)-1], at); t != nil {
+			t.table.Description = text.text
+		}
+		return nil
+	}
+	if len(parts) < 2 {
+		return p.errorf(start, "COMMENT ON COLUMN needs a table-qualified column name")
+	}
+	if t := p.im.table(parts[len(parts)-2], at); t != nil {
+		if col := t.column(parts[len(parts)-1]); col != nil {
+			col.Description = text.text
+		}
+	}
+	return nil
+}
+
+// finish settles what needs every table: primary keys, unique columns,
+// foreign keys and generators.
+func (im *importer) finish() {
+	for _, t := range im.order {
+		im.primaryKey(t)
+		for k := range t.uniques {
+			if col := t.column(k); col != nil && !col.PrimaryKey {
+				col.Unique = true
+			}
+		}
+	}
+	for _, t := range im.order {
+		for _, ref := range t.refs {
+			im.foreignKey(t, ref)
+		}
+	}
+	for _, t := range im.order {
+		for i := range t.table.Columns {
+			col := &t.table.Columns[i]
+			if col.Generator == "" {
+				col.Generator, col.GeneratorParams = schema.SuggestGenerator(col)
+			}
+		}
+	}
+}
+
+// primaryKey marks the table's primary key column. Schemas have a single
+// primary key column, so a composite key keeps its first column as the
+// primary key and the whole key as a unique index.
+func (im *importer) primaryKey(t *tableDef) {
+	if len(t.primaryKey) == 0 {
+		im.warn(t.at, "table '%s' has no primary key; add one to the table or the schema before using it", t.table.Name)
+		return
+	}
+
+	col := t.column(t.primaryKey[0])
+	if col == nil {
+		im.warn(t.pkAt, "table '%s': primary key column '%s' does not exist", t.table.Name, t.primaryKey[0])
+		return
+	}
+	col.PrimaryKey = true
+	col.Nullable = false
+
+	if len(t.primaryKey) > 1 {
+		im.warn(t.pkAt, "table '%s': composite primary key (%s): '%s' is the schema's primary key and the full key became unique index '%s_pkey', which generated data may not always satisfy",
+			t.table.Name, strings.Join(t.primaryKey, ", "), col.Name, t.table.Name)
+		t.table.Indexes = append(t.table.Indexes, schema.Index{Name: t.table.Name + "_pkey", Columns: t.primaryKey, Unique: true})
+		for _, name := range t.primaryKey[1:] {
+			if c := t.column(name); c != nil {
+				c.Nullable = false
+			}
+		}
+	}
+}
+
+// foreignKey attaches a foreign key to its column, pointing at the
+// parent's primary key when no column was named.
+func (im *importer) foreignKey(t *tableDef, ref *reference) {
+	col := t.column(ref.child)
+	if col == nil {
+		im.warn(ref.at, "table '%s': foreign key column '%s' does not exist", t.table.Name, ref.child)
+		return
+	}
+	parent := im.tables[key(ref.table)]
+	if parent == nil {
+		im.warn(ref.at, "table '%s': foreign key on '%s' references table '%s', which the script does not create; foreign key left out",
+			t.table.Name, col.Name, ref.table)
+		return
+	}
+
+	var target *schema.Column
+	if ref.column != "" {
+		target = parent.column(ref.column)
+	} else if pk := parent.table.PrimaryKey(); pk != nil {
+		target = pk
+	}
+	if target == nil {
+		im.warn(ref.at, "table '%s': foreign key on '%s' references a column of '%s' that does not exist; foreign key left out",
+			t.table.Name, col.Name, parent.table.Name)
+		return
+	}
+
+	fk := &schema.ForeignKey{
+		Table:    parent.table.Name,
+		Column:   target.Name,
+		OnDelete: im.action(ref.onDelete, col, t, ref.at),
+		OnUpdate: im.action(ref.onUpdate, col, t, ref.at),
+	}
+
+	// PostgreSQL lets an int reference a bigint; schemas need the types to
+	// match exactly, so the child takes the parent's integer type
+	child, parentType := col.DataType(), target.DataType()
+	if child.Family() == schema.FamilyInteger && parentType.Family() == schema.FamilyInteger &&
+		(child.Base != parentType.Base || child.Unsigned != parentType.Unsigned) {
+		im.warn(ref.at, "table '%s': column '%s' changed from %s to %s to match '%s.%s'",
+			t.table.Name, col.Name, col.Type, target.Type, parent.table.Name, target.Name)
+		col.Type = target.Type
+	}
+	col.ForeignKey = fk
+}
+
+// action normalizes a referential action to one a schema accepts.
+func (im *importer) action(action string, col *schema.Column, t *tableDef, at position) string {
+	switch action {
+	case "CASCADE", "RESTRICT":
+		return action
+	case "SET NULL":
+		if col.Nullable {
+			return action
+		}
+		im.warn(at, "table '%s': column '%s': SET NULL on a NOT NULL column replaced by RESTRICT", t.table.Name, col.Name)
+	case "SET DEFAULT":
+		im.warn(at, "table '%s': column '%s': SET DEFAULT is not supported by schemas and was replaced by RESTRICT", t.table.Name, col.Name)
+	}
+	// NO ACTION, the SQL default, behaves like RESTRICT
+	return "RESTRICT"
+}
@@ -0,0 +1,284 @@
+package ddl
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlDump is trimmed mysqldump --no-data output.
+const mysqlDump = "-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"DROP TABLE IF EXISTS `users`;\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(191) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+	"  `phone_number` varchar(32) DEFAULT NULL,\n" +
+	"  `is_admin` tinyint(1) NOT NULL DEFAULT '0',\n" +
+	"  `role` enum('admin','member') NOT NULL DEFAULT 'member',\n" +
+	"  `note` varchar(20) DEFAULT 'it\\'s',\n" +
+	"  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `users_email_unique` (`email`),\n" +
+	"  KEY `idx_created` (`created_at`) USING BTREE\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4 COMMENT='App users';\n" +
+	"CREATE TABLE `posts` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `user_id` int unsigned DEFAULT NULL,\n" +
+	"  `title` varchar(255) NOT NULL COMMENT 'Headline',\n" +
+	"  `price` decimal(5,2) DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  CONSTRAINT `posts_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `posts` VALUES (1,1,'a;b',1.00);\n"
+
+// pgDump is trimmed pg_dump --schema-only output, with keys added by
+// ALTER TABLE after the tables.
+const pgDump = `SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE TYPE public.order_status AS ENUM (
+    'pending',
+    'shipped'
+);
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+  NEW.updated_at = now(); -- CREATE TABLE nope (x int);
+  RETURN NEW;
+END;
+$$;
+
+CREATE TABLE public.customers (
+    id integer NOT NULL,
+    email character varying(255) NOT NULL,
+    external_ref uuid DEFAULT gen_random_uuid() NOT NULL,
+    tier character varying(10) DEFAULT 'basic'::character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT customers_tier_check CHECK (((tier)::text = ANY ((ARRAY['basic'::character varying, 'gold'::character varying])::text[])))
+);
+
+COMMENT ON COLUMN public.customers.email IS 'Login address';
+
+CREATE TABLE public.orders (
+    id bigint NOT NULL,
+    customer_id bigint NOT NULL,
+    status public.order_status DEFAULT 'pending'::public.order_status NOT NULL,
+    placed_at timestamp without time zone,
+    ship_by date DEFAULT (CURRENT_DATE + 7)
+);
+
+CREATE TABLE public.order_items (
+    order_id bigint NOT NULL,
+    line_no integer NOT NULL,
+    quantity integer DEFAULT 1 NOT NULL
+);
+
+ALTER TABLE ONLY public.customers ALTER COLUMN id SET DEFAULT nextval('public.customers_id_seq'::regclass);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_email_key UNIQUE (email);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.order_items
+    ADD CONSTRAINT order_items_pkey PRIMARY KEY (order_id, line_no);
+ALTER TABLE ONLY public.order_items
+    ADD CONSTRAINT order_items_order_id_fkey FOREIGN KEY (order_id) REFERENCES public.orders(id);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE CASCADE;
+`
+
+func importScript(t *testing.T, text string, opts ImportOptions) (*schema.Schema, schema.ErrorList) {
+	t.Helper()
+	s, warnings, err := Import([]Script{{Name: "dump.sql", Text: text}}, opts)
+	require.NoError(t, err)
+	require.NoError(t, schema.ValidateSchema(s), "imported schemas should validate")
+	return s, warnings
+}
+
+func TestImport_MySQL(t *testing.T) {
+	s, warnings := importScript(t, mysqlDump, ImportOptions{Name: "app", RecordCount: 10})
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, "app", s.Name)
+	assert.Equal(t, []string{"mysql"}, s.DatabaseType, "backquotes mark the script as MySQL")
+	assert.Equal(t, []string{"users", "posts"}, s.GenerationOrder)
+	assert.Equal(t, 20, s.Metadata.TotalRecords)
+
+	users := s.Table("users")
+	require.NotNil(t, users)
+	assert.Equal(t, "App users", users.Description)
+	assert.Equal(t, 10, users.RecordCount)
+	assert.Equal(t, schema.Column{Name: "id", Type: "int unsigned", PrimaryKey: true, AutoIncrement: true}, *users.Column("id"))
+	assert.Equal(t, schema.Column{Name: "email", Type: "varchar(191)", Unique: true, Generator: "email"}, *users.Column("email"))
+	assert.Equal(t, schema.Column{Name: "phone_number", Type: "varchar(32)", Nullable: true, Generator: "phone"}, *users.Column("phone_number"))
+	assert.Equal(t, schema.Column{Name: "is_admin", Type: "boolean", Default: strPtr("false"), Generator: "boolean"}, *users.Column("is_admin"))
+	assert.Equal(t, "enum('admin','member')", users.Column("role").Type)
+	assert.Equal(t, "it's", *users.Column("note").Default, "backslash escapes are MySQL's")
+	assert.Equal(t, "CURRENT_TIMESTAMP", *users.Column("created_at").Default)
+	assert.Equal(t, "timestamp_past", users.Column("created_at").Generator)
+	assert.Equal(t, []schema.Index{
+		{Name: "users_email_unique", Columns: []string{"email"}, Unique: true},
+		{Name: "idx_created", Columns: []string{"created_at"}, Type: "BTREE"},
+	}, users.Indexes)
+
+	posts := s.Table("posts")
+	require.NotNil(t, posts)
+	assert.Equal(t, &schema.ForeignKey{Table: "users", Column: "id", OnDelete: "SET NULL", OnUpdate: "RESTRICT"}, posts.Column("user_id").ForeignKey)
+	assert.Empty(t, posts.Column("user_id").Generator, "foreign keys pick parent rows")
+	assert.Equal(t, "Headline", posts.Column("title").Description)
+	assert.Equal(t, "decimal_range", posts.Column("price").Generator)
+	assert.Equal(t, 999.99, posts.Column("price").GeneratorParams["max"], "ranges fit the column")
+}
+
+func TestImport_Postgres(t *testing.T) {
+	s, warnings := importScript(t, pgDump, ImportOptions{})
+	assert.Equal(t, "imported", s.Name)
+	assert.Equal(t, []string{"postgres"}, s.DatabaseType)
+	assert.Equal(t, []string{"customers", "orders", "order_items"}, s.GenerationOrder)
+
+	customers := s.Table("customers")
+	require.NotNil(t, customers)
+	id := customers.Column("id")
+	assert.True(t, id.PrimaryKey && id.AutoIncrement, "nextval defaults are auto-increment")
+	assert.Nil(t, id.Default)
+	assert.Equal(t, "Login address", customers.Column("email").Description)
+	assert.True(t, customers.Column("email").Unique)
+	assert.Equal(t, schema.Column{Name: "external_ref", Type: "char(36)", Generator: "uuid"}, *customers.Column("external_ref"))
+	assert.Equal(t, "enum('basic','gold')", customers.Column("tier").Type, "a CHECK constraint listing values makes an enum")
+	assert.Equal(t, "basic", *customers.Column("tier").Default)
+	assert.Equal(t, "timestamp", customers.Column("created_at").Type)
+	assert.Equal(t, "CURRENT_TIMESTAMP", *customers.Column("created_at").Default)
+
+	orders := s.Table("orders")
+	require.NotNil(t, orders)
+	assert.Equal(t, "enum('pending','shipped')", orders.Column("status").Type)
+	assert.Equal(t, "datetime", orders.Column("placed_at").Type)
+	assert.Nil(t, orders.Column("ship_by").Default)
+	assert.Equal(t, &schema.ForeignKey{Table: "customers", Column: "id", OnDelete: "CASCADE", OnUpdate: "RESTRICT"}, orders.Column("customer_id").ForeignKey)
+	assert.Equal(t, "int", orders.Column("customer_id").Type, "foreign keys take their parent's integer type")
+
+	items := s.Table("order_items")
+	require.NotNil(t, items)
+	assert.True(t, items.Column("order_id").PrimaryKey)
+	assert.False(t, items.Column("line_no").PrimaryKey)
+	assert.Equal(t, []schema.Index{{Name: "order_items_pkey", Columns: []string{"order_id", "line_no"}, Unique: true}}, items.Indexes)
+	assert.Equal(t, map[string]interface{}{"min": 1.0, "max": 10.0}, items.Column("quantity").GeneratorParams)
+
+	var messages []string
+	for _, w := range warnings {
+		assert.Equal(t, schema.SeverityWarning, w.Severity)
+		assert.Equal(t, "dump.sql", w.File)
+		messages = append(messages, w.Err.Error())
+	}
+	require.Len(t, messages, 3)
+	assert.Contains(t, messages[0], "column 'ship_by': default CURRENT_DATE+7 is not a literal")
+	assert.Contains(t, messages[1], "composite primary key (order_id, line_no)")
+	assert.Contains(t, messages[2], "column 'customer_id' changed from bigint to int")
+	assert.Equal(t, 34, warnings[0].Line)
+}
+
+// TestImport_RoundTrip imports the DDL of every bundled schema and checks
+// that the tables come back with the same keys and types.
+func TestImport_RoundTrip(t *testing.T) {
+	paths, err := filepath.Glob("../../schemas/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		original, err := schema.LoadSchema(path)
+		require.NoError(t, err)
+
+		for _, database := range original.DatabaseType {
+			d, err := dialect.For(database)
+			require.NoError(t, err)
+			t.Run(original.Name+"/"+database, func(t *testing.T) {
+				script := strings.Join(Schema(d, original), ";\n") + ";\n"
+				s, warnings := importScript(t, script, ImportOptions{})
+				assert.Empty(t, warnings)
+				assert.Equal(t, []string{database}, s.DatabaseType)
+				assert.Equal(t, original.GenerationOrder, s.GenerationOrder)
+
+				for _, want := range original.Tables {
+					got := s.Table(want.Name)
+					require.NotNil(t, got)
+					require.Len(t, got.Columns, len(want.Columns))
+					for i, col := range want.Columns {
+						imported := got.Columns[i]
+						wantType := col.Type
+						if database == "postgres" && strings.HasPrefix(col.Type, "tinyint") {
+							wantType = "smallint" // PostgreSQL has no tinyint
+						}
+						assert.Equal(t, wantType, imported.Type, col.Name)
+						assert.Equal(t, col.PrimaryKey, imported.PrimaryKey, col.Name)
+						assert.Equal(t, col.Nullable, imported.Nullable, col.Name)
+						assert.Equal(t, col.Unique, imported.Unique, col.Name)
+						assert.Equal(t, col.Default, imported.Default, col.Name)
+						assert.Equal(t, col.ForeignKey == nil, imported.ForeignKey == nil, col.Name)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestImport_Dialect(t *testing.T) {
+	portable := "CREATE TABLE t (id INT PRIMARY KEY, name VARCHAR(20));"
+	s, _ := importScript(t, portable, ImportOptions{})
+	assert.Equal(t, []string{"mysql", "postgres"}, s.DatabaseType, "portable DDL targets both databases")
+
+	s, _ = importScript(t, portable, ImportOptions{Dialect: dialect.Postgres{}})
+	assert.Equal(t, []string{"postgres"}, s.DatabaseType)
+
+	s, _ = importScript(t, "CREATE TABLE t (id SERIAL PRIMARY KEY, flag TINYINT(1));", ImportOptions{Dialect: dialect.MySQL{}})
+	assert.Equal(t, "boolean", s.Tables[0].Columns[1].Type)
+}
+
+func TestImport_Scripts(t *testing.T) {
+	tables := Script{Name: "tables.sql", Text: "CREATE TABLE a (id INT PRIMARY KEY);\nCREATE TABLE b (id INT PRIMARY KEY, a_id INT NOT NULL);\n"}
+	keys := Script{Name: "keys.sql", Text: "ALTER TABLE b ADD CONSTRAINT fk FOREIGN KEY (a_id) REFERENCES a;\nCREATE INDEX b_a ON missing (a_id);\n"}
+
+	s, warnings, err := Import([]Script{tables, keys}, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &schema.ForeignKey{Table: "a", Column: "id", OnDelete: "RESTRICT", OnUpdate: "RESTRICT"}, s.Table("b").Column("a_id").ForeignKey)
+
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "keys.sql", warnings[0].File)
+	assert.Equal(t, 2, warnings[0].Line)
+	assert.Contains(t, warnings[0].Error(), "table 'missing' is not created by the script")
+}
+
+func TestImport_SyntaxError(t *testing.T) {
+	tests := []struct {
+		name, script string
+		line, col    int
+		message      string
+	}{
+		{"unterminated string", "CREATE TABLE t (\n  id int PRIMARY KEY,\n  name varchar(10) DEFAULT 'x\n);", 3, 28, "unterminated string"},
+		{"missing name", "CREATE TABLE t (\n  id int PRIMARY KEY,\n  FOREIGN KEY (id) REFERENCES\n);", 3, 30, "expected a name, found end of statement"},
+		{"unbalanced", "CREATE TABLE t (\n  id int,\n  name varchar(10\n);", 1, 16, "unbalanced parenthesis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Import([]Script{{Name: "bad.sql", Text: tt.script}}, ImportOptions{})
+			require.Error(t, err)
+
+			var located *schema.Error
+			require.True(t, errors.As(err, &located))
+			assert.Equal(t, "bad.sql", located.File)
+			assert.Equal(t, tt.line, located.Line)
+			assert.Equal(t, tt.col, located.Column)
+			assert.Contains(t, located.Error(), tt.message)
+			assert.Contains(t, located.Excerpt(), "bad.sql:")
+		})
+	}
+}
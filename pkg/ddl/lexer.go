@@ -0,0 +1,280 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind classifies a DDL token.
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // keyword or bare identifier
+	tokIdent                   // quoted identifier: "name" or `name`
+	tokString                  // string literal, unquoted in text
+	tokNumber                  // numeric literal
+	tokSymbol                  // punctuation or operator
+)
+
+// token is one lexical element of a DDL script. line and col are 1-based.
+type token struct {
+	kind      tokenKind
+	text      string
+	line, col int
+}
+
+// is reports whether t is the keyword word, ignoring case. Quoted
+// identifiers never match, so "order" can name a column.
+func (t token) is(word string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, word)
+}
+
+// isSymbol reports whether t is the punctuation s.
+func (t token) isSymbol(s string) bool {
+	return t.kind == tokSymbol && t.text == s
+}
+
+// name reports whether t can name a table, column or index.
+func (t token) name() bool {
+	return t.kind == tokWord || t.kind == tokIdent
+}
+
+// lexer splits a script into statements of tokens. Comments, including
+// MySQL's /*! ... */ version comments, are dropped.
+type lexer struct {
+	src       string
+	pos       int
+	line, col int
+
+	// backslashEscapes enables MySQL's backslash escapes in strings
+	backslashEscapes bool
+}
+
+// tokenize returns the statements of src, split at top-level semicolons.
+// Empty statements are dropped.
+func tokenize(src string, backslashEscapes bool) ([][]token, error) {
+	lx := &lexer{src: src, line: 1, col: 1, backslashEscapes: backslashEscapes}
+
+	var stmts [][]token
+	var stmt []token
+	for {
+		tok, ok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if tok.isSymbol(";") {
+			if len(stmt) > 0 {
+				stmts = append(stmts, stmt)
+			}
+			stmt = nil
+			continue
+		}
+		stmt = append(stmt, tok)
+	}
+	if len(stmt) > 0 {
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+// errorf returns a syntax error at the given position.
+func (lx *lexer) errorf(line, col int, format string, args ...interface{}) error {
+	return &syntaxError{line: line, col: col, err: fmt.Errorf(format, args...)}
+}
+
+// advance moves past n bytes, tracking lines and columns.
+func (lx *lexer) advance(n int) {
+	for i := 0; i < n && lx.pos < len(lx.src); i++ {
+		if lx.src[lx.pos] == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+		lx.pos++
+	}
+}
+
+func (lx *lexer) peekByte(offset int) byte {
+	if lx.pos+offset < len(lx.src) {
+		return lx.src[lx.pos+offset]
+	}
+	return 0
+}
+
+// next returns the next token, or ok=false at the end of the script.
+func (lx *lexer) next() (tok token, ok bool, err error) {
+	if err := lx.skipSpace(); err != nil {
+		return token{}, false, err
+	}
+	if lx.pos >= len(lx.src) {
+		return token{}, false, nil
+	}
+
+	line, col := lx.line, lx.col
+	c := lx.src[lx.pos]
+	switch {
+	case c == '\'':
+		text, err := lx.quoted('\'', lx.backslashEscapes)
+		return token{kind: tokString, text: text, line: line, col: col}, true, err
+
+	case c == '"' || c == '`':
+		text, err := lx.quoted(c, false)
+		return token{kind: tokIdent, text: text, line: line, col: col}, true, err
+
+	case c == '$' && lx.dollarTag() != "":
+		text, err := lx.dollarQuoted()
+		return token{kind: tokString, text: text, line: line, col: col}, true, err
+
+	case isDigit(c) || (c == '.' && isDigit(lx.peekByte(1))):
+		start := lx.pos
+		for lx.pos < len(lx.src) && (isDigit(lx.src[lx.pos]) || lx.src[lx.pos] == '.' ||
+			lx.src[lx.pos] == 'e' || lx.src[lx.pos] == 'E' ||
+			((lx.src[lx.pos] == '-' || lx.src[lx.pos] == '+') && (lx.src[lx.pos-1] == 'e' || lx.src[lx.pos-1] == 'E'))) {
+			lx.advance(1)
+		}
+		return token{kind: tokNumber, text: lx.src[start:lx.pos], line: line, col: col}, true, nil
+
+	case isWordStart(c):
+		// Prefixed strings: E'...' escapes, N'...' national, B'...' and
+		// X'...' bit and hex literals
+		if strings.ContainsRune("eEnNbBxX", rune(c)) && lx.peekByte(1) == '\'' {
+			lx.advance(1)
+			escapes := lx.backslashEscapes || c == 'e' || c == 'E'
+			text, err := lx.quoted('\'', escapes)
+			return token{kind: tokString, text: text, line: line, col: col}, true, err
+		}
+		start := lx.pos
+		for lx.pos < len(lx.src) && (isWordStart(lx.src[lx.pos]) || isDigit(lx.src[lx.pos]) || lx.src[lx.pos] == '$') {
+			lx.advance(1)
+		}
+		return token{kind: tokWord, text: lx.src[start:lx.pos], line: line, col: col}, true, nil
+	}
+
+	// Two-character operators the parser cares about
+	if lx.pos+1 < len(lx.src) {
+		switch op := lx.src[lx.pos : lx.pos+2]; op {
+		case "::", "<>", "!=", "<=", ">=", "||":
+			lx.advance(2)
+			return token{kind: tokSymbol, text: op, line: line, col: col}, true, nil
+		}
+	}
+	lx.advance(1)
+	return token{kind: tokSymbol, text: string(c), line: line, col: col}, true, nil
+}
+
+// skipSpace skips whitespace and comments.
+func (lx *lexer) skipSpace() error {
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			lx.advance(1)
+		case c == '-' && lx.peekByte(1) == '-', c == '#':
+			for lx.pos < len(lx.src) && lx.src[lx.pos] != '\n' {
+				lx.advance(1)
+			}
+		case c == '/' && lx.peekByte(1) == '*':
+			line, col := lx.line, lx.col
+			end := strings.Index(lx.src[lx.pos+2:], "*/")
+			if end < 0 {
+				return lx.errorf(line, col, "unterminated comment")
+			}
+			lx.advance(end + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// quoted reads a literal enclosed in quote, where a doubled quote stands
+// for itself.
+func (lx *lexer) quoted(quote byte, backslashEscapes bool) (string, error) {
+	line, col := lx.line, lx.col
+	lx.advance(1)
+
+	var b strings.Builder
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == quote && lx.peekByte(1) == quote:
+			b.WriteByte(quote)
+			lx.advance(2)
+		case c == quote:
+			lx.advance(1)
+			return b.String(), nil
+		case c == '\\' && backslashEscapes && lx.pos+1 < len(lx.src):
+			b.WriteString(unescape(lx.src[lx.pos+1]))
+			lx.advance(2)
+		default:
+			b.WriteByte(c)
+			lx.advance(1)
+		}
+	}
+	return "", lx.errorf(line, col, "unterminated %s", map[byte]string{'\'': "string", '"': "quoted identifier", '`': "quoted identifier"}[quote])
+}
+
+// unescape returns the character a backslash escape stands for.
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case '0':
+		return "\x00"
+	case 'Z':
+		return "\x1a"
+	}
+	return string(c)
+}
+
+// dollarTag returns the PostgreSQL dollar-quote delimiter starting at the
+// current position, such as "$$" or "$body$", or "" if there is none.
+func (lx *lexer) dollarTag() string {
+	rest := lx.src[lx.pos:]
+	for i := 1; i < len(rest); i++ {
+		c := rest[i]
+		if c == '$' {
+			return rest[:i+1]
+		}
+		if !isWordStart(c) && !(i > 1 && isDigit(c)) {
+			return ""
+		}
+	}
+	return ""
+}
+
+// dollarQuoted reads a dollar-quoted string, such as a function body.
+func (lx *lexer) dollarQuoted() (string, error) {
+	line, col := lx.line, lx.col
+	tag := lx.dollarTag()
+	lx.advance(len(tag))
+	end := strings.Index(lx.src[lx.pos:], tag)
+	if end < 0 {
+		return "", lx.errorf(line, col, "unterminated dollar-quoted string")
+	}
+	text := lx.src[lx.pos : lx.pos+end]
+	lx.advance(end + len(tag))
+	return text, nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// syntaxError is a problem at a position in the current script.
+type syntaxError struct {
+	line, col int
+	err       error
+}
+
+func (e *syntaxError) Error() string { return e.err.Error() }
@@ -0,0 +1,331 @@
+// Package diagram renders a schema as an entity-relationship diagram in
+// Mermaid (erDiagram), Graphviz DOT or PlantUML text.
+//
+// Each table becomes an entity listing its key columns (primary, foreign
+// and unique keys), or every column with Options.AllColumns. Each
+// relationship from schema.AllRelationships becomes an edge from the
+// parent to the child table, drawn in crow's-foot notation: the parent end
+// is exactly one (zero or one when the foreign key is nullable) and the
+// child end is zero or more, or zero or one for one_to_one relationships.
+//
+// Example usage:
+//
+//	err := diagram.Write(os.Stdout, s, diagram.Options{Format: diagram.Mermaid})
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// Format is a diagram language.
+type Format string
+
+const (
+	Mermaid  Format = "mermaid"
+	DOT      Format = "dot"
+	PlantUML Format = "plantuml"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Mermaid, DOT, PlantUML}
+
+// Options controls what Write draws.
+type Options struct {
+	Format Format
+
+	// AllColumns lists every column of each table instead of only its
+	// key columns.
+	AllColumns bool
+}
+
+// Write renders s as a diagram in opts.Format.
+func Write(w io.Writer, s *schema.Schema, opts Options) error {
+	var render func(*bufio.Writer, *model)
+	switch opts.Format {
+	case Mermaid:
+		render = writeMermaid
+	case DOT:
+		render = writeDOT
+	case PlantUML:
+		render = writePlantUML
+	default:
+		return fmt.Errorf("unsupported diagram format '%s': must be one of: mermaid, dot, plantuml", opts.Format)
+	}
+
+	m, err := build(s, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	render(bw, m)
+	return bw.Flush()
+}
+
+// model is the diagram independent of its language.
+type model struct {
+	name     string
+	entities []entity
+	edges    []edge
+}
+
+type entity struct {
+	name    string
+	columns []attribute
+}
+
+type attribute struct {
+	name     string
+	typ      string // simplified type for Mermaid
+	fullType string // type as declared
+	pk, fk   bool
+	unique   bool
+	nullable bool
+}
+
+// keys returns the attribute's key markers, e.g. ["PK", "FK"].
+func (a attribute) keys() []string {
+	var keys []string
+	if a.pk {
+		keys = append(keys, "PK")
+	}
+	if a.fk {
+		keys = append(keys, "FK")
+	}
+	if a.unique && !a.pk {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+// edge is a relationship drawn from the parent (referenced) table to the
+// child table holding the foreign key.
+type edge struct {
+	parent, parentColumn string
+	child, childColumn   string
+
+	// optionalParent is set when the foreign key is nullable, so a child
+	// row may have no parent.
+	optionalParent bool
+
+	// singleChild is set for one_to_one relationships; manyParents for
+	// many_to_many ones.
+	singleChild, manyParents bool
+}
+
+// build collects the tables in generation order and normalises every
+// relationship to parent -> child.
+func build(s *schema.Schema, opts Options) (*model, error) {
+	order, err := s.TableOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &model{name: s.Name}
+	for _, name := range order {
+		t := s.Table(name)
+		if t == nil {
+			continue
+		}
+		e := entity{name: t.Name}
+		for _, col := range t.Columns {
+			a := attribute{
+				name:     col.Name,
+				typ:      simpleType(col),
+				fullType: col.Type,
+				pk:       col.PrimaryKey,
+				fk:       col.ForeignKey != nil,
+				unique:   col.Unique,
+				nullable: col.Nullable,
+			}
+			if opts.AllColumns || a.pk || a.fk || a.unique {
+				e.columns = append(e.columns, a)
+			}
+		}
+		m.entities = append(m.entities, e)
+	}
+
+	for _, rel := range s.AllRelationships() {
+		child := schema.ColumnRef{Table: rel.FromTable, Column: rel.FromColumn}
+		parent := schema.ColumnRef{Table: rel.ToTable, Column: rel.ToColumn}
+		if !references(s, child, parent) {
+			child, parent = parent, child
+		}
+
+		e := edge{
+			parent: parent.Table, parentColumn: parent.Column,
+			child: child.Table, childColumn: child.Column,
+			singleChild: rel.RelationshipType == schema.OneToOne,
+			manyParents: rel.RelationshipType == schema.ManyToMany,
+		}
+		if t := s.Table(child.Table); t != nil {
+			if col := t.Column(child.Column); col != nil {
+				e.optionalParent = col.Nullable
+			}
+		}
+		m.edges = append(m.edges, e)
+	}
+	return m, nil
+}
+
+// references reports whether child has a foreign key to parent.
+func references(s *schema.Schema, child, parent schema.ColumnRef) bool {
+	t := s.Table(child.Table)
+	if t == nil {
+		return false
+	}
+	col := t.Column(child.Column)
+	return col != nil && col.ForeignKey != nil &&
+		col.ForeignKey.Table == parent.Table && col.ForeignKey.Column == parent.Column
+}
+
+// simpleType is a column's base type, e.g. "varchar" for varchar(255),
+// since Mermaid attribute types cannot hold parentheses or commas.
+func simpleType(col schema.Column) string {
+	t := col.DataType()
+	if t.Array {
+		return t.Base + "[]"
+	}
+	return t.Base
+}
+
+func writeMermaid(w *bufio.Writer, m *model) {
+	fmt.Fprintln(w, "erDiagram")
+	for _, e := range m.entities {
+		fmt.Fprintf(w, "    %s {\n", e.name)
+		for _, a := range e.columns {
+			line := fmt.Sprintf("        %s %s", a.typ, a.name)
+			if keys := a.keys(); len(keys) > 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w, "    }")
+	}
+
+	for _, e := range m.edges {
+		// Left markers describe the parent, right markers the child
+		parent, child := "||", "o{"
+		if e.optionalParent {
+			parent = "|o"
+		}
+		if e.manyParents {
+			parent = "}o"
+		}
+		if e.singleChild {
+			child = "o|"
+		}
+		fmt.Fprintf(w, "    %s %s--%s %s : %s\n", e.parent, parent, child, e.child, strconv.Quote(e.childColumn))
+	}
+}
+
+func writePlantUML(w *bufio.Writer, m *model) {
+	fmt.Fprintln(w, "@startuml")
+	fmt.Fprintf(w, "title %s\n", m.name)
+	fmt.Fprintln(w, "hide circle")
+	fmt.Fprintln(w, "skinparam linetype ortho")
+
+	for _, e := range m.entities {
+		fmt.Fprintf(w, "\nentity %s {\n", e.name)
+		// Primary keys sit above the separator, as IE notation expects
+		var keys, rest []attribute
+		for _, a := range e.columns {
+			if a.pk {
+				keys = append(keys, a)
+			} else {
+				rest = append(rest, a)
+			}
+		}
+		for _, a := range keys {
+			fmt.Fprintln(w, "  "+plantUMLAttribute(a))
+		}
+		fmt.Fprintln(w, "  --")
+		for _, a := range rest {
+			fmt.Fprintln(w, "  "+plantUMLAttribute(a))
+		}
+		fmt.Fprintln(w, "}")
+	}
+
+	if len(m.edges) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, e := range m.edges {
+		parent, child := "||", "o{"
+		if e.optionalParent {
+			parent = "|o"
+		}
+		if e.manyParents {
+			parent = "}o"
+		}
+		if e.singleChild {
+			child = "o|"
+		}
+		fmt.Fprintf(w, "%s %s--%s %s : %s\n", e.parent, parent, child, e.child, e.childColumn)
+	}
+	fmt.Fprintln(w, "@enduml")
+}
+
+// plantUMLAttribute renders a column; "*" marks NOT NULL columns.
+func plantUMLAttribute(a attribute) string {
+	line := a.name + " : " + a.fullType
+	if !a.nullable {
+		line = "* " + line
+	}
+	for _, key := range a.keys() {
+		line += " <<" + key + ">>"
+	}
+	return line
+}
+
+func writeDOT(w *bufio.Writer, m *model) {
+	fmt.Fprintf(w, "digraph %s {\n", strconv.Quote(m.name))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=plaintext, fontname="Helvetica"];`)
+	fmt.Fprintln(w, `  edge [fontname="Helvetica", fontsize=10, dir=both];`)
+
+	for _, e := range m.entities {
+		fmt.Fprintf(w, "\n  %s [label=<\n", strconv.Quote(e.name))
+		fmt.Fprintln(w, `    <table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+		fmt.Fprintf(w, "      <tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(e.name))
+		for _, a := range e.columns {
+			text := html.EscapeString(a.name + ": " + a.fullType)
+			if keys := a.keys(); len(keys) > 0 {
+				text += " (" + strings.Join(keys, ", ") + ")"
+			}
+			if a.pk {
+				text = "<u>" + text + "</u>"
+			}
+			fmt.Fprintf(w, "      <tr><td port=%s align=\"left\">%s</td></tr>\n", strconv.Quote(a.name), text)
+		}
+		fmt.Fprintln(w, "    </table>>];")
+	}
+
+	if len(m.edges) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, e := range m.edges {
+		// Edges run child -> parent so arrowtail marks the child end and
+		// arrowhead the parent end; the first shape of each touches the
+		// table
+		head, tail := "teetee", "crowodot"
+		if e.optionalParent {
+			head = "teeodot"
+		}
+		if e.manyParents {
+			head = "crowodot"
+		}
+		if e.singleChild {
+			tail = "teeodot"
+		}
+		fmt.Fprintf(w, "  %s:%s -> %s:%s [arrowhead=%s, arrowtail=%s, label=%s];\n",
+			strconv.Quote(e.child), strconv.Quote(e.childColumn), strconv.Quote(e.parent), strconv.Quote(e.parentColumn),
+			head, tail, strconv.Quote(e.childColumn))
+	}
+	fmt.Fprintln(w, "}")
+}
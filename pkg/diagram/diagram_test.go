@@ -0,0 +1,114 @@
+package diagram
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchema has a required many-to-one foreign key, an optional one and a
+// one-to-one relationship declared from the parent's side.
+func testSchema() *schema.Schema {
+	return &schema.Schema{
+		Name:         "shop",
+		DatabaseType: []string{"postgres"},
+		Tables: []schema.Table{
+			{
+				Name: "orders",
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "customer_id", Type: "int", ForeignKey: &schema.ForeignKey{Table: "customers", Column: "id"}},
+					{Name: "referrer_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{Table: "customers", Column: "id"}},
+					{Name: "total", Type: "decimal(10,2)"},
+				},
+			},
+			{
+				Name: "customers",
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "email", Type: "varchar(255)", Unique: true},
+					{Name: "tags", Type: "text[]"},
+				},
+			},
+			{
+				Name: "profiles",
+				Columns: []schema.Column{
+					{Name: "customer_id", Type: "int", PrimaryKey: true, ForeignKey: &schema.ForeignKey{Table: "customers", Column: "id"}},
+				},
+			},
+		},
+		Relationships: []schema.Relationship{
+			{FromTable: "customers", FromColumn: "id", ToTable: "profiles", ToColumn: "customer_id", RelationshipType: schema.OneToOne},
+		},
+	}
+}
+
+func render(t *testing.T, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testSchema(), opts))
+	return buf.String()
+}
+
+func TestWrite_Mermaid(t *testing.T) {
+	out := render(t, Options{Format: Mermaid})
+
+	assert.Equal(t, `erDiagram
+    customers {
+        int id PK
+        varchar email UK
+    }
+    orders {
+        int id PK
+        int customer_id FK
+        int referrer_id FK
+    }
+    profiles {
+        int customer_id PK, FK
+    }
+    customers ||--o| profiles : "customer_id"
+    customers ||--o{ orders : "customer_id"
+    customers |o--o{ orders : "referrer_id"
+`, out)
+
+	all := render(t, Options{Format: Mermaid, AllColumns: true})
+	assert.Contains(t, all, "        decimal total\n")
+	assert.Contains(t, all, "        text[] tags\n")
+}
+
+func TestWrite_DOT(t *testing.T) {
+	out := render(t, Options{Format: DOT, AllColumns: true})
+
+	assert.Contains(t, out, "digraph \"shop\" {\n")
+	assert.Contains(t, out, `<tr><td port="id" align="left"><u>id: int (PK)</u></td></tr>`)
+	assert.Contains(t, out, `<tr><td port="total" align="left">total: decimal(10,2)</td></tr>`)
+	assert.Contains(t, out, `"orders":"customer_id" -> "customers":"id" [arrowhead=teetee, arrowtail=crowodot, label="customer_id"];`)
+	assert.Contains(t, out, `"orders":"referrer_id" -> "customers":"id" [arrowhead=teeodot, arrowtail=crowodot, label="referrer_id"];`)
+	assert.Contains(t, out, `"profiles":"customer_id" -> "customers":"id" [arrowhead=teetee, arrowtail=teeodot, label="customer_id"];`)
+	assert.Equal(t, "}\n", out[len(out)-2:])
+}
+
+func TestWrite_PlantUML(t *testing.T) {
+	out := render(t, Options{Format: PlantUML})
+
+	assert.Contains(t, out, "@startuml\ntitle shop\n")
+	assert.Contains(t, out, "entity orders {\n  * id : int <<PK>>\n  --\n  * customer_id : int <<FK>>\n  referrer_id : int <<FK>>\n}\n")
+	assert.Contains(t, out, "customers ||--o{ orders : customer_id\n")
+	assert.Contains(t, out, "customers |o--o{ orders : referrer_id\n")
+	assert.Contains(t, out, "customers ||--o| profiles : customer_id\n")
+	assert.Contains(t, out, "@enduml\n")
+}
+
+func TestWrite_Errors(t *testing.T) {
+	err := Write(&bytes.Buffer{}, testSchema(), Options{Format: "svg"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported diagram format 'svg'")
+
+	cyclic := testSchema()
+	cyclic.Tables[1].Columns[0].ForeignKey = &schema.ForeignKey{Table: "orders", Column: "id"}
+	err = Write(&bytes.Buffer{}, cyclic, Options{Format: Mermaid})
+	require.Error(t, err)
+}
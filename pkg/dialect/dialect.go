@@ -0,0 +1,352 @@
+// Package dialect captures the SQL differences between the databases
+// SourceBox can seed: identifier quoting, bind placeholders, literal
+// escaping, native column types, connection strings and default ports.
+//
+// Example usage:
+//
+//	d, err := dialect.For("postgres")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	db, err := sql.Open(d.DriverName(), d.DSN(dialect.ConnConfig{
+//	    Host:     "localhost",
+//	    User:     "postgres",
+//	    Database: "demo",
+//	}))
+package dialect
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// ConnConfig holds the connection settings for a database server.
+// A zero Port means the dialect's default port.
+type ConnConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+}
+
+// Dialect describes how to talk to one database engine.
+type Dialect interface {
+	// Name returns the canonical database name ("mysql" or "postgres").
+	Name() string
+
+	// DriverName returns the database/sql driver name.
+	DriverName() string
+
+	// DefaultPort returns the port used when ConnConfig.Port is zero.
+	DefaultPort() int
+
+	// DSN builds a driver connection string.
+	DSN(cfg ConnConfig) string
+
+	// QuoteIdent quotes a table or column name.
+	QuoteIdent(name string) string
+
+	// Placeholder returns the bind parameter for the n-th argument (1-based).
+	Placeholder(n int) string
+
+	// ColumnType maps a schema column type to the native column type.
+	ColumnType(schemaType string) string
+
+	// MaxParams is the largest number of bind parameters in one statement.
+	MaxParams() int
+
+	// Literal renders a generated value (nil, int64, float64, string, bool
+	// or time.Time) as an SQL literal, escaped for this database.
+	Literal(v interface{}) string
+}
+
+// Names lists the supported database names.
+var Names = []string{"mysql", "postgres"}
+
+// For returns the dialect for a database name. "postgresql" is accepted as
+// an alias for "postgres". Names are case-insensitive.
+func For(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "mysql":
+		return MySQL{}, nil
+	case "postgres", "postgresql":
+		return Postgres{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database '%s': must be one of: %s", name, strings.Join(Names, ", "))
+	}
+}
+
+// Port returns cfg.Port, or the dialect's default port when it is zero.
+func Port(d Dialect, cfg ConnConfig) int {
+	if cfg.Port != 0 {
+		return cfg.Port
+	}
+	return d.DefaultPort()
+}
+
+// literal renders the values whose SQL form is the same in every dialect,
+// quoting strings with quote. Values of other types are formatted with %v
+// and quoted.
+func literal(v interface{}, quote func(string) string) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return quote(formatTime(x))
+	case string:
+		return quote(x)
+	default:
+		return quote(fmt.Sprint(x))
+	}
+}
+
+// formatTime renders a time as a DATE literal when it falls on midnight and
+// as a TIMESTAMP literal otherwise. Both forms load into either column type.
+func formatTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	if t.Nanosecond() != 0 {
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// MySQL is the dialect for MySQL and MariaDB.
+type MySQL struct{}
+
+// Name implements Dialect.
+func (MySQL) Name() string { return "mysql" }
+
+// DriverName implements Dialect.
+func (MySQL) DriverName() string { return "mysql" }
+
+// DefaultPort implements Dialect.
+func (MySQL) DefaultPort() int { return 3306 }
+
+// DSN implements Dialect. Times are parsed into time.Time and stored as UTC.
+func (d MySQL) DSN(cfg ConnConfig) string {
+	c := mysql.NewConfig()
+	c.User = cfg.User
+	c.Passwd = cfg.Password
+	c.Net = "tcp"
+	c.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(Port(d, cfg)))
+	c.DBName = cfg.Database
+	c.ParseTime = true
+	return c.FormatDSN()
+}
+
+// QuoteIdent implements Dialect using backticks.
+func (MySQL) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Placeholder implements Dialect. MySQL uses positional "?" markers.
+func (MySQL) Placeholder(int) string { return "?" }
+
+// ColumnType implements Dialect. Schema types are MySQL types already, so
+// only the few non-MySQL spellings are translated.
+func (MySQL) ColumnType(schemaType string) string {
+	if t, err := schema.ParseDataType(schemaType); err == nil && t.Base == "jsonb" {
+		return "JSON"
+	}
+	return strings.TrimSpace(schemaType)
+}
+
+// MaxParams implements Dialect.
+func (MySQL) MaxParams() int { return 65535 }
+
+// mysqlEscaper escapes string literals the way mysqldump does, which is
+// correct under MySQL's default sql_mode where backslash is an escape.
+var mysqlEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"'", `\'`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+// Literal implements Dialect.
+func (MySQL) Literal(v interface{}) string {
+	return literal(v, func(s string) string { return "'" + mysqlEscaper.Replace(s) + "'" })
+}
+
+// Postgres is the dialect for PostgreSQL.
+type Postgres struct{}
+
+// Name implements Dialect.
+func (Postgres) Name() string { return "postgres" }
+
+// DriverName implements Dialect.
+func (Postgres) DriverName() string { return "postgres" }
+
+// DefaultPort implements Dialect.
+func (Postgres) DefaultPort() int { return 5432 }
+
+// DSN implements Dialect. TLS is disabled for local servers, which rarely
+// have it configured, and left to the driver's default elsewhere.
+func (d Postgres) DSN(cfg ConnConfig) string {
+	u := url.URL{
+		Scheme: "postgres",
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(Port(d, cfg))),
+		Path:   "/" + cfg.Database,
+	}
+	if cfg.Password != "" {
+		u.User = url.UserPassword(cfg.User, cfg.Password)
+	} else {
+		u.User = url.User(cfg.User)
+	}
+	if isLocalHost(cfg.Host) {
+		u.RawQuery = "sslmode=disable"
+	}
+	return u.String()
+}
+
+// QuoteIdent implements Dialect using double quotes.
+func (Postgres) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Placeholder implements Dialect. PostgreSQL uses numbered "$n" markers.
+func (Postgres) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// ColumnType implements Dialect.
+func (Postgres) ColumnType(schemaType string) string {
+	t, err := schema.ParseDataType(schemaType)
+	if err != nil {
+		return strings.ToUpper(strings.TrimSpace(schemaType))
+	}
+	if t.Array {
+		return postgresType(t) + "[]"
+	}
+	return postgresType(t)
+}
+
+// postgresType maps the element type of t to its PostgreSQL spelling.
+func postgresType(t schema.DataType) string {
+	// withArgs appends the declared size, if any
+	withArgs := func(name string, args ...int) string {
+		if len(args) == 0 || args[0] == 0 {
+			return name
+		}
+		parts := make([]string, len(args))
+		for i, a := range args {
+			parts[i] = strconv.Itoa(a)
+		}
+		return name + "(" + strings.Join(parts, ",") + ")"
+	}
+
+	switch t.Base {
+	case "int":
+		// PostgreSQL has no unsigned types; the next size up holds every
+		// unsigned value
+		if t.Unsigned {
+			return "BIGINT"
+		}
+		return "INTEGER"
+	case "bigint":
+		if t.Unsigned {
+			return "NUMERIC(20)"
+		}
+		return "BIGINT"
+	case "smallint":
+		if t.Unsigned {
+			return "INTEGER"
+		}
+		return "SMALLINT"
+	case "tinyint":
+		return "SMALLINT"
+	case "decimal":
+		return withArgs("NUMERIC", t.Precision, t.Scale)
+	case "float":
+		// MySQL stores float(25) to float(53) as double precision
+		if t.Precision > 24 {
+			return "DOUBLE PRECISION"
+		}
+		return "REAL"
+	case "double":
+		return "DOUBLE PRECISION"
+	case "varchar":
+		return withArgs("VARCHAR", t.Length)
+	case "char":
+		return withArgs("CHAR", t.Length)
+	case "text":
+		return "TEXT"
+	case "date":
+		return "DATE"
+	case "datetime":
+		return withArgs("TIMESTAMP", t.Precision)
+	case "timestamp":
+		return withArgs("TIMESTAMPTZ", t.Precision)
+	case "boolean":
+		return "BOOLEAN"
+	case "bit":
+		if t.Length > 1 {
+			return withArgs("BIT", t.Length)
+		}
+		return "BOOLEAN"
+	case "json":
+		return "JSON"
+	case "jsonb":
+		return "JSONB"
+	case "enum":
+		// PostgreSQL enums are separate types; a wide enough VARCHAR holds
+		// every value and package ddl adds a CHECK for the allowed ones
+		width := 1
+		for _, v := range t.Values {
+			width = max(width, utf8.RuneCountInString(v))
+		}
+		return fmt.Sprintf("VARCHAR(%d)", width)
+	default:
+		return strings.ToUpper(t.Base)
+	}
+}
+
+// MaxParams implements Dialect.
+func (Postgres) MaxParams() int { return 65535 }
+
+// Literal implements Dialect. Strings are standard SQL literals, which
+// assumes standard_conforming_strings (the default since PostgreSQL 9.1).
+// NUL bytes cannot be stored in PostgreSQL text and are dropped.
+func (Postgres) Literal(v interface{}) string {
+	return literal(v, func(s string) string {
+		s = strings.ReplaceAll(s, "\x00", "")
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	})
+}
+
+// isLocalHost reports whether host refers to the local machine.
+func isLocalHost(host string) bool {
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	default:
+		return false
+	}
+}
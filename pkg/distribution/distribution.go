@@ -0,0 +1,639 @@
+// Package distribution interprets the statistical distribution blocks found in
+// schema generator_params and turns them into samplers.
+//
+// Two equivalent forms are accepted. The nested form used by the bundled
+// schemas:
+//
+//	"distribution": {
+//	    "type": "normal",
+//	    "params": {"mean": 680, "std_dev": 80, "min": 300, "max": 850}
+//	}
+//
+// and the flat form used throughout the schema specification, where the
+// distribution parameters sit next to the generator's own parameters:
+//
+//	"distribution": "normal", "mean": 680, "std_dev": 80, "min": 300, "max": 850
+//
+// Supported types are uniform, normal, lognormal, ranges, exponential,
+// poisson, zipf and beta. Whenever min or max is known the sampler is
+// truncated to [min, max] by conditioning on the interval rather than by
+// clamping, so the shape of the distribution inside the bounds is preserved.
+//
+// Example usage:
+//
+//	s, err := distribution.FromParams(col.GeneratorParams)
+//	if err != nil {
+//	    return err
+//	}
+//	if s == nil {
+//	    s = distribution.Uniform{Min: 0, Max: 1}
+//	}
+//	v := s.Sample(rng)
+package distribution
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Supported distribution type names.
+const (
+	TypeUniform     = "uniform"
+	TypeNormal      = "normal"
+	TypeLogNormal   = "lognormal"
+	TypeRanges      = "ranges"
+	TypeExponential = "exponential"
+	TypePoisson     = "poisson"
+	TypeZipf        = "zipf"
+	TypeBeta        = "beta"
+)
+
+// Types lists every supported distribution type name.
+var Types = []string{
+	TypeUniform, TypeNormal, TypeLogNormal, TypeRanges,
+	TypeExponential, TypePoisson, TypeZipf, TypeBeta,
+}
+
+// ParamNames lists every parameter a distribution may read, across all
+// types, including the min and max bounds. In the flat form these sit next to
+// a generator's own parameters.
+var ParamNames = []string{
+	"min", "max", "mean", "std_dev", "median", "mu", "sigma",
+	"ranges", "rate", "lambda", "s", "v", "alpha", "beta",
+}
+
+// maxRejections bounds rejection sampling for distributions without a
+// closed-form quantile before falling back to clamping.
+const maxRejections = 1000
+
+// Sampler draws values from a probability distribution.
+type Sampler interface {
+	Sample(r *rand.Rand) float64
+}
+
+// Quantiler is implemented by continuous distributions with a closed-form
+// CDF and inverse CDF. Truncation uses it to sample exactly and efficiently
+// from the conditional distribution on [min, max].
+type Quantiler interface {
+	CDF(x float64) float64
+	Quantile(p float64) float64
+}
+
+// FromParams parses the distribution described by a column's generator_params.
+// Returns (nil, nil) when no distribution is declared, in which case callers
+// fall back to their own default (usually uniform over min/max).
+func FromParams(params map[string]interface{}) (Sampler, error) {
+	raw, ok := params["distribution"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	var kind string
+	var distParams map[string]interface{}
+
+	switch v := raw.(type) {
+	case string:
+		// Flat form: parameters live alongside the generator parameters
+		kind = v
+		distParams = params
+	case map[string]interface{}:
+		t, ok := v["type"].(string)
+		if !ok || t == "" {
+			return nil, fmt.Errorf("distribution: 'type' is required")
+		}
+		kind = t
+		distParams = make(map[string]interface{})
+		if inner, ok := v["params"]; ok && inner != nil {
+			m, ok := inner.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("distribution: 'params' must be an object")
+			}
+			for k, val := range m {
+				distParams[k] = val
+			}
+		}
+		// Generator-level bounds apply unless the distribution sets its own
+		for _, key := range []string{"min", "max"} {
+			if _, ok := distParams[key]; !ok {
+				if val, ok := params[key]; ok {
+					distParams[key] = val
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("distribution: must be a type name or an object, got %T", raw)
+	}
+
+	s, err := Parse(kind, distParams)
+	if err != nil {
+		return nil, fmt.Errorf("distribution %q: %w", kind, err)
+	}
+	return s, nil
+}
+
+// Parse builds a sampler of the given type from its parameters, truncated to
+// the optional min/max parameters.
+func Parse(kind string, params map[string]interface{}) (Sampler, error) {
+	lo, hasMin, err := number(params, "min")
+	if err != nil {
+		return nil, err
+	}
+	hi, hasMax, err := number(params, "max")
+	if err != nil {
+		return nil, err
+	}
+	if hasMin && hasMax && lo > hi {
+		return nil, fmt.Errorf("min (%v) must not be greater than max (%v)", lo, hi)
+	}
+	if !hasMin {
+		lo = math.Inf(-1)
+	}
+	if !hasMax {
+		hi = math.Inf(1)
+	}
+
+	var s Sampler
+	switch strings.ToLower(kind) {
+	case TypeUniform:
+		if !hasMin || !hasMax {
+			return nil, fmt.Errorf("min and max are required")
+		}
+		return Uniform{Min: lo, Max: hi}, nil
+
+	case TypeNormal:
+		mean, err := required(params, "mean")
+		if err != nil {
+			return nil, err
+		}
+		sd, err := required(params, "std_dev")
+		if err != nil {
+			return nil, err
+		}
+		if sd <= 0 {
+			return nil, fmt.Errorf("std_dev must be positive")
+		}
+		s = Normal{Mean: mean, StdDev: sd}
+
+	case TypeLogNormal:
+		s, err = parseLogNormal(params, hi)
+		if err != nil {
+			return nil, err
+		}
+
+	case TypeRanges:
+		// Buckets carry their own bounds, so no further truncation applies
+		return parseRanges(params)
+
+	case TypeExponential:
+		rate, hasRate, err := number(params, "rate")
+		if err != nil {
+			return nil, err
+		}
+		if !hasRate {
+			mean, err := required(params, "mean")
+			if err != nil {
+				return nil, fmt.Errorf("rate or mean is required")
+			}
+			if mean <= 0 {
+				return nil, fmt.Errorf("mean must be positive")
+			}
+			rate = 1 / mean
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate must be positive")
+		}
+		s = Exponential{Rate: rate}
+
+	case TypePoisson:
+		lambda, err := required(params, "lambda")
+		if err != nil {
+			return nil, err
+		}
+		if lambda <= 0 {
+			return nil, fmt.Errorf("lambda must be positive")
+		}
+		s = Poisson{Lambda: lambda}
+
+	case TypeZipf:
+		return parseZipf(params, lo, hi, hasMin, hasMax)
+
+	case TypeBeta:
+		alpha, err := required(params, "alpha")
+		if err != nil {
+			return nil, err
+		}
+		beta, err := required(params, "beta")
+		if err != nil {
+			return nil, err
+		}
+		if alpha <= 0 || beta <= 0 {
+			return nil, fmt.Errorf("alpha and beta must be positive")
+		}
+		// Beta is defined on [0, 1]; min/max rescale rather than truncate
+		b := Beta{Alpha: alpha, Beta: beta, Min: 0, Max: 1}
+		if hasMin {
+			b.Min = lo
+		}
+		if hasMax {
+			b.Max = hi
+		}
+		if b.Min >= b.Max {
+			return nil, fmt.Errorf("min (%v) must be less than max (%v)", b.Min, b.Max)
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("unknown distribution type: must be one of: %s", strings.Join(Types, ", "))
+	}
+
+	if hasMin || hasMax {
+		return Truncated{Dist: s, Min: lo, Max: hi}, nil
+	}
+	return s, nil
+}
+
+// parseLogNormal reads a lognormal distribution described by its median
+// (or mu) and an optional sigma. Without sigma, the spread is chosen so
+// that max sits three standard deviations above the median on the log scale.
+func parseLogNormal(params map[string]interface{}, hi float64) (Sampler, error) {
+	mu, hasMu, err := number(params, "mu")
+	if err != nil {
+		return nil, err
+	}
+	median, hasMedian, err := number(params, "median")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case hasMedian:
+		if median <= 0 {
+			return nil, fmt.Errorf("median must be positive")
+		}
+		mu = math.Log(median)
+	case !hasMu:
+		return nil, fmt.Errorf("median is required")
+	}
+
+	sigma, hasSigma, err := number(params, "sigma")
+	if err != nil {
+		return nil, err
+	}
+	if !hasSigma {
+		sigma = 0.5
+		if !math.IsInf(hi, 1) && math.Log(hi) > mu {
+			sigma = (math.Log(hi) - mu) / 3
+		}
+	}
+	if sigma <= 0 {
+		return nil, fmt.Errorf("sigma must be positive")
+	}
+	return LogNormal{Mu: mu, Sigma: sigma}, nil
+}
+
+// parseRanges reads a list of weighted {min, max, weight} buckets.
+func parseRanges(params map[string]interface{}) (Sampler, error) {
+	raw, ok := params["ranges"]
+	if !ok || raw == nil {
+		return nil, fmt.Errorf("ranges is required")
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("ranges must be a non-empty array")
+	}
+
+	buckets := make([]Range, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("ranges[%d] must be an object", i)
+		}
+		var err error
+		if buckets[i].Min, err = required(obj, "min"); err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		if buckets[i].Max, err = required(obj, "max"); err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		if buckets[i].Weight, err = required(obj, "weight"); err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		if buckets[i].Min > buckets[i].Max {
+			return nil, fmt.Errorf("ranges[%d]: min (%v) must not be greater than max (%v)", i, buckets[i].Min, buckets[i].Max)
+		}
+		if buckets[i].Weight <= 0 {
+			return nil, fmt.Errorf("ranges[%d]: weight must be positive", i)
+		}
+	}
+	return NewRanges(buckets), nil
+}
+
+// parseZipf reads a Zipf distribution over the integers [min, max].
+// The exponent s must be greater than 1; v (default 1) flattens the head.
+func parseZipf(params map[string]interface{}, lo, hi float64, hasMin, hasMax bool) (Sampler, error) {
+	s, err := required(params, "s")
+	if err != nil {
+		return nil, err
+	}
+	if s <= 1 {
+		return nil, fmt.Errorf("s must be greater than 1")
+	}
+	v, hasV, err := number(params, "v")
+	if err != nil {
+		return nil, err
+	}
+	if !hasV {
+		v = 1
+	}
+	if v < 1 {
+		return nil, fmt.Errorf("v must be at least 1")
+	}
+	if !hasMax {
+		return nil, fmt.Errorf("max is required")
+	}
+	if !hasMin {
+		lo = 0
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("min (%v) must not be greater than max (%v)", lo, hi)
+	}
+	return &Zipf{S: s, V: v, Min: math.Ceil(lo), N: uint64(math.Floor(hi) - math.Ceil(lo))}, nil
+}
+
+// number reads an optional numeric parameter.
+func number(params map[string]interface{}, key string) (float64, bool, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return 0, false, nil
+	}
+	switch v := raw.(type) {
+	case float64:
+		return v, true, nil
+	case int:
+		return float64(v), true, nil
+	case int64:
+		return float64(v), true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be a number, got %T", key, raw)
+	}
+}
+
+// required reads a mandatory numeric parameter.
+func required(params map[string]interface{}, key string) (float64, error) {
+	v, ok, err := number(params, key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%s is required", key)
+	}
+	return v, nil
+}
+
+// Uniform samples evenly from [Min, Max).
+type Uniform struct {
+	Min, Max float64
+}
+
+// Sample implements Sampler.
+func (u Uniform) Sample(r *rand.Rand) float64 {
+	return u.Min + r.Float64()*(u.Max-u.Min)
+}
+
+// CDF implements Quantiler.
+func (u Uniform) CDF(x float64) float64 {
+	switch {
+	case x <= u.Min:
+		return 0
+	case x >= u.Max:
+		return 1
+	default:
+		return (x - u.Min) / (u.Max - u.Min)
+	}
+}
+
+// Quantile implements Quantiler.
+func (u Uniform) Quantile(p float64) float64 {
+	return u.Min + p*(u.Max-u.Min)
+}
+
+// Normal is the Gaussian distribution.
+type Normal struct {
+	Mean, StdDev float64
+}
+
+// Sample implements Sampler.
+func (n Normal) Sample(r *rand.Rand) float64 {
+	return n.Mean + n.StdDev*r.NormFloat64()
+}
+
+// CDF implements Quantiler.
+func (n Normal) CDF(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-n.Mean)/(n.StdDev*math.Sqrt2))
+}
+
+// Quantile implements Quantiler.
+func (n Normal) Quantile(p float64) float64 {
+	return n.Mean + n.StdDev*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+// LogNormal is the distribution of exp(X) for X ~ Normal(Mu, Sigma).
+// Its median is exp(Mu).
+type LogNormal struct {
+	Mu, Sigma float64
+}
+
+// Sample implements Sampler.
+func (l LogNormal) Sample(r *rand.Rand) float64 {
+	return math.Exp(l.Mu + l.Sigma*r.NormFloat64())
+}
+
+// CDF implements Quantiler.
+func (l LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal{Mean: l.Mu, StdDev: l.Sigma}.CDF(math.Log(x))
+}
+
+// Quantile implements Quantiler.
+func (l LogNormal) Quantile(p float64) float64 {
+	return math.Exp(Normal{Mean: l.Mu, StdDev: l.Sigma}.Quantile(p))
+}
+
+// Exponential models waiting times with the given rate (1/mean).
+type Exponential struct {
+	Rate float64
+}
+
+// Sample implements Sampler.
+func (e Exponential) Sample(r *rand.Rand) float64 {
+	return r.ExpFloat64() / e.Rate
+}
+
+// CDF implements Quantiler.
+func (e Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return 1 - math.Exp(-e.Rate*x)
+}
+
+// Quantile implements Quantiler.
+func (e Exponential) Quantile(p float64) float64 {
+	return -math.Log(1-p) / e.Rate
+}
+
+// Poisson counts events with mean Lambda. Samples are whole numbers.
+type Poisson struct {
+	Lambda float64
+}
+
+// Sample implements Sampler.
+func (p Poisson) Sample(r *rand.Rand) float64 {
+	if p.Lambda >= 30 {
+		// The normal approximation is accurate for large lambda and avoids
+		// the O(lambda) cost of Knuth's method
+		return math.Max(0, math.Round(p.Lambda+math.Sqrt(p.Lambda)*r.NormFloat64()))
+	}
+
+	limit := math.Exp(-p.Lambda)
+	k := 0.0
+	prod := r.Float64()
+	for prod > limit {
+		k++
+		prod *= r.Float64()
+	}
+	return k
+}
+
+// Zipf samples integers in [Min, Min+N] where Min is the most frequent value
+// and the probability of Min+k is proportional to (V+k)^-S.
+//
+// Setting up a rand.Zipf is costly, so a Zipf keeps the one it built for the
+// stream it last sampled from. Like the stream itself, a Zipf must not be
+// used from several goroutines at once.
+type Zipf struct {
+	S, V float64
+	Min  float64
+	N    uint64
+
+	r    *rand.Rand
+	zipf *rand.Zipf
+}
+
+// Sample implements Sampler.
+func (z *Zipf) Sample(r *rand.Rand) float64 {
+	if z.zipf == nil || z.r != r {
+		z.r, z.zipf = r, rand.NewZipf(r, z.S, z.V, z.N)
+	}
+	return z.Min + float64(z.zipf.Uint64())
+}
+
+// Beta is the beta distribution rescaled from [0, 1] to [Min, Max].
+type Beta struct {
+	Alpha, Beta float64
+	Min, Max    float64
+}
+
+// Sample implements Sampler.
+func (b Beta) Sample(r *rand.Rand) float64 {
+	x := gamma(r, b.Alpha)
+	y := gamma(r, b.Beta)
+	return b.Min + (x/(x+y))*(b.Max-b.Min)
+}
+
+// gamma samples Gamma(shape, 1) using the Marsaglia-Tsang method.
+func gamma(r *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Boost to shape+1 and scale back, per Marsaglia and Tsang
+		return gamma(r, shape+1) * math.Pow(r.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+// Range is one weighted bucket of a Ranges distribution.
+type Range struct {
+	Min, Max, Weight float64
+}
+
+// Ranges picks a bucket proportionally to its weight, then samples
+// uniformly within it. Weights need not sum to exactly 1.
+type Ranges struct {
+	buckets    []Range
+	cumulative []float64
+}
+
+// NewRanges builds a Ranges distribution from weighted buckets.
+func NewRanges(buckets []Range) Ranges {
+	cumulative := make([]float64, len(buckets))
+	total := 0.0
+	for i, b := range buckets {
+		total += b.Weight
+		cumulative[i] = total
+	}
+	return Ranges{buckets: buckets, cumulative: cumulative}
+}
+
+// Buckets returns the distribution's weighted buckets.
+func (rg Ranges) Buckets() []Range {
+	return rg.buckets
+}
+
+// Sample implements Sampler.
+func (rg Ranges) Sample(r *rand.Rand) float64 {
+	total := rg.cumulative[len(rg.cumulative)-1]
+	i := sort.SearchFloat64s(rg.cumulative, r.Float64()*total)
+	if i >= len(rg.buckets) {
+		i = len(rg.buckets) - 1
+	}
+	b := rg.buckets[i]
+	return b.Min + r.Float64()*(b.Max-b.Min)
+}
+
+// Truncated restricts a distribution to [Min, Max] by conditioning on the
+// interval. Either bound may be infinite.
+type Truncated struct {
+	Dist     Sampler
+	Min, Max float64
+}
+
+// Sample implements Sampler.
+func (t Truncated) Sample(r *rand.Rand) float64 {
+	if q, ok := t.Dist.(Quantiler); ok {
+		// Inverse transform sampling over [F(min), F(max)] draws from the
+		// exact truncated distribution without rejections
+		lo, hi := q.CDF(t.Min), q.CDF(t.Max)
+		if hi > lo {
+			return t.clamp(q.Quantile(lo + r.Float64()*(hi-lo)))
+		}
+	}
+
+	for i := 0; i < maxRejections; i++ {
+		if x := t.Dist.Sample(r); x >= t.Min && x <= t.Max {
+			return x
+		}
+	}
+	// The interval holds almost no probability mass; clamping is the best
+	// remaining option
+	return t.clamp(t.Dist.Sample(r))
+}
+
+func (t Truncated) clamp(x float64) float64 {
+	return math.Min(t.Max, math.Max(t.Min, x))
+}
@@ -0,0 +1,291 @@
+package distribution
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// draw takes n samples from s with a fixed seed.
+func draw(s Sampler, n int) []float64 {
+	r := rand.New(rand.NewSource(1))
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = s.Sample(r)
+	}
+	return out
+}
+
+func mean(xs []float64) float64 {
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func median(xs []float64) float64 {
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	return sorted[len(sorted)/2]
+}
+
+func TestFromParams_NoDistribution(t *testing.T) {
+	s, err := FromParams(map[string]interface{}{"min": 1.0, "max": 2.0})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestFromParams_NestedNormal(t *testing.T) {
+	// Mirrors credit_score in the fintech example schema
+	params := map[string]interface{}{
+		"min": 300.0,
+		"max": 850.0,
+		"distribution": map[string]interface{}{
+			"type": "normal",
+			"params": map[string]interface{}{
+				"mean":    680.0,
+				"std_dev": 80.0,
+				"min":     300.0,
+				"max":     850.0,
+			},
+		},
+	}
+
+	s, err := FromParams(params)
+	require.NoError(t, err)
+	require.IsType(t, Truncated{}, s)
+
+	values := draw(s, 20000)
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 300.0)
+		assert.LessOrEqual(t, v, 850.0)
+	}
+	assert.InDelta(t, 680, mean(values), 5, "truncation should barely move a well-centred mean")
+}
+
+func TestFromParams_FlatForm(t *testing.T) {
+	params := map[string]interface{}{
+		"min":          18.0,
+		"max":          80.0,
+		"distribution": "normal",
+		"mean":         42.0,
+		"std_dev":      12.0,
+	}
+
+	s, err := FromParams(params)
+	require.NoError(t, err)
+
+	values := draw(s, 20000)
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 18.0)
+		assert.LessOrEqual(t, v, 80.0)
+	}
+	assert.InDelta(t, 42, median(values), 1)
+}
+
+func TestFromParams_NestedInheritsGeneratorBounds(t *testing.T) {
+	params := map[string]interface{}{
+		"min": 0.0,
+		"max": 10.0,
+		"distribution": map[string]interface{}{
+			"type":   "exponential",
+			"params": map[string]interface{}{"mean": 100.0},
+		},
+	}
+
+	s, err := FromParams(params)
+	require.NoError(t, err)
+	for _, v := range draw(s, 1000) {
+		assert.LessOrEqual(t, v, 10.0)
+	}
+}
+
+func TestParse_LogNormal(t *testing.T) {
+	// Mirrors loan_amount in the fintech example schema
+	s, err := Parse(TypeLogNormal, map[string]interface{}{"median": 15000.0, "min": 1000.0, "max": 50000.0})
+	require.NoError(t, err)
+
+	values := draw(s, 20000)
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 1000.0)
+		assert.LessOrEqual(t, v, 50000.0)
+	}
+	assert.InDelta(t, 15000, median(values), 750)
+	assert.Greater(t, mean(values), median(values), "lognormal should be right-skewed")
+}
+
+func TestParse_Ranges(t *testing.T) {
+	// Mirrors interest_rate in the fintech example schema
+	s, err := Parse(TypeRanges, map[string]interface{}{
+		"ranges": []interface{}{
+			map[string]interface{}{"min": 3.5, "max": 6.0, "weight": 0.30},
+			map[string]interface{}{"min": 6.0, "max": 9.0, "weight": 0.45},
+			map[string]interface{}{"min": 9.0, "max": 15.0, "weight": 0.20},
+			map[string]interface{}{"min": 15.0, "max": 25.0, "weight": 0.05},
+		},
+	})
+	require.NoError(t, err)
+
+	values := draw(s, 20000)
+	inSecond := 0
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 3.5)
+		assert.Less(t, v, 25.0)
+		if v >= 6.0 && v < 9.0 {
+			inSecond++
+		}
+	}
+	assert.InDelta(t, 0.45, float64(inSecond)/float64(len(values)), 0.02)
+}
+
+func TestParse_Uniform(t *testing.T) {
+	s, err := Parse(TypeUniform, map[string]interface{}{"min": 10.0, "max": 20.0})
+	require.NoError(t, err)
+	values := draw(s, 10000)
+	assert.InDelta(t, 15, mean(values), 0.2)
+}
+
+func TestParse_Exponential(t *testing.T) {
+	s, err := Parse(TypeExponential, map[string]interface{}{"rate": 0.5})
+	require.NoError(t, err)
+	assert.InDelta(t, 2, mean(draw(s, 20000)), 0.1)
+
+	s, err = Parse(TypeExponential, map[string]interface{}{"mean": 3.0})
+	require.NoError(t, err)
+	assert.InDelta(t, 3, mean(draw(s, 20000)), 0.15)
+}
+
+func TestParse_Poisson(t *testing.T) {
+	for _, lambda := range []float64{4, 60} {
+		s, err := Parse(TypePoisson, map[string]interface{}{"lambda": lambda})
+		require.NoError(t, err)
+		values := draw(s, 20000)
+		for _, v := range values[:100] {
+			assert.Equal(t, math.Trunc(v), v, "poisson samples should be whole numbers")
+			assert.GreaterOrEqual(t, v, 0.0)
+		}
+		assert.InDelta(t, lambda, mean(values), lambda*0.03)
+	}
+}
+
+func TestParse_Zipf(t *testing.T) {
+	s, err := Parse(TypeZipf, map[string]interface{}{"s": 2.0, "min": 1.0, "max": 100.0})
+	require.NoError(t, err)
+
+	counts := make(map[float64]int)
+	for _, v := range draw(s, 10000) {
+		assert.GreaterOrEqual(t, v, 1.0)
+		assert.LessOrEqual(t, v, 100.0)
+		counts[v]++
+	}
+	assert.Greater(t, counts[1], counts[2], "the minimum should be the most frequent value")
+	assert.Greater(t, counts[2], counts[10])
+}
+
+func TestZipf_Streams(t *testing.T) {
+	z := &Zipf{S: 1.5, V: 1, Min: 1, N: 50}
+	a, b := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(2))
+	wantA, wantB := rand.NewZipf(rand.New(rand.NewSource(1)), 1.5, 1, 50), rand.NewZipf(rand.New(rand.NewSource(2)), 1.5, 1, 50)
+
+	// Alternating streams must give each one the sequence it would get alone
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 1+float64(wantA.Uint64()), z.Sample(a))
+		assert.Equal(t, 1+float64(wantB.Uint64()), z.Sample(b))
+	}
+}
+
+func TestParse_Beta(t *testing.T) {
+	s, err := Parse(TypeBeta, map[string]interface{}{"alpha": 2.0, "beta": 5.0, "min": 0.0, "max": 100.0})
+	require.NoError(t, err)
+
+	values := draw(s, 20000)
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 0.0)
+		assert.LessOrEqual(t, v, 100.0)
+	}
+	// Mean of Beta(2,5) is 2/7
+	assert.InDelta(t, 100*2.0/7.0, mean(values), 1)
+
+	// Shapes below 1 exercise the boosted gamma sampler
+	s, err = Parse(TypeBeta, map[string]interface{}{"alpha": 0.5, "beta": 0.5})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, mean(draw(s, 20000)), 0.02)
+}
+
+func TestTruncated_PreservesShape(t *testing.T) {
+	// Truncating N(0,1) to [0, inf) gives the half-normal, whose mean is
+	// sqrt(2/pi). Clamping would instead pile half the mass onto 0.
+	s := Truncated{Dist: Normal{Mean: 0, StdDev: 1}, Min: 0, Max: math.Inf(1)}
+	values := draw(s, 50000)
+
+	zeros := 0
+	for _, v := range values {
+		assert.GreaterOrEqual(t, v, 0.0)
+		if v == 0 {
+			zeros++
+		}
+	}
+	assert.Less(t, zeros, 10)
+	assert.InDelta(t, math.Sqrt(2/math.Pi), mean(values), 0.02)
+}
+
+func TestTruncated_RejectionForDiscrete(t *testing.T) {
+	s := Truncated{Dist: Poisson{Lambda: 5}, Min: 3, Max: 6}
+	for _, v := range draw(s, 1000) {
+		assert.Contains(t, []float64{3, 4, 5, 6}, v)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		params map[string]interface{}
+		errMsg string
+	}{
+		{"unknown type", "gaussian", map[string]interface{}{}, "unknown distribution type"},
+		{"normal missing mean", TypeNormal, map[string]interface{}{"std_dev": 1.0}, "mean is required"},
+		{"normal non-positive std_dev", TypeNormal, map[string]interface{}{"mean": 1.0, "std_dev": 0.0}, "std_dev must be positive"},
+		{"lognormal missing median", TypeLogNormal, map[string]interface{}{}, "median is required"},
+		{"lognormal negative median", TypeLogNormal, map[string]interface{}{"median": -1.0}, "median must be positive"},
+		{"uniform missing bounds", TypeUniform, map[string]interface{}{"min": 1.0}, "min and max are required"},
+		{"min above max", TypeNormal, map[string]interface{}{"mean": 1.0, "std_dev": 1.0, "min": 5.0, "max": 1.0}, "must not be greater than max"},
+		{"ranges empty", TypeRanges, map[string]interface{}{"ranges": []interface{}{}}, "non-empty array"},
+		{"ranges bad bucket", TypeRanges, map[string]interface{}{"ranges": []interface{}{
+			map[string]interface{}{"min": 5.0, "max": 1.0, "weight": 1.0},
+		}}, "ranges[0]"},
+		{"exponential missing rate", TypeExponential, map[string]interface{}{}, "rate or mean is required"},
+		{"poisson non-positive lambda", TypePoisson, map[string]interface{}{"lambda": 0.0}, "lambda must be positive"},
+		{"zipf exponent too small", TypeZipf, map[string]interface{}{"s": 1.0, "max": 10.0}, "s must be greater than 1"},
+		{"zipf missing max", TypeZipf, map[string]interface{}{"s": 2.0}, "max is required"},
+		{"beta missing alpha", TypeBeta, map[string]interface{}{"beta": 1.0}, "alpha is required"},
+		{"non-numeric parameter", TypeNormal, map[string]interface{}{"mean": "high", "std_dev": 1.0}, "mean must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.kind, tt.params)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFromParams_Errors(t *testing.T) {
+	_, err := FromParams(map[string]interface{}{"distribution": map[string]interface{}{"params": map[string]interface{}{}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'type' is required")
+
+	_, err = FromParams(map[string]interface{}{"distribution": 42.0})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be a type name or an object")
+
+	_, err = FromParams(map[string]interface{}{"distribution": "normal", "mean": 1.0})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `distribution "normal": std_dev is required`)
+}
@@ -0,0 +1,256 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchema is a two-table schema with an index and a foreign key.
+func testSchema() *schema.Schema {
+	return &schema.Schema{
+		Name:         "test",
+		Version:      "1.0.0",
+		DatabaseType: []string{"mysql", "postgres"},
+		Tables: []schema.Table{
+			{
+				Name:        "borrowers",
+				RecordCount: 3,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true, AutoIncrement: true},
+					{Name: "name", Type: "varchar(50)"},
+				},
+				Indexes: []schema.Index{{Name: "idx_name", Columns: []string{"name"}, Type: "BTREE", Unique: true}},
+			},
+			{
+				Name:        "loans",
+				RecordCount: 2,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "borrower_id", Type: "int", ForeignKey: &schema.ForeignKey{
+						Table: "borrowers", Column: "id", OnDelete: "CASCADE", OnUpdate: "RESTRICT",
+					}},
+				},
+			},
+		},
+		GenerationOrder: []string{"borrowers", "loans"},
+	}
+}
+
+// testDataset holds fixed rows for testSchema, including values that need
+// escaping.
+func testDataset() *generator.Dataset {
+	return &generator.Dataset{Tables: []*generator.TableData{
+		{
+			Name:    "borrowers",
+			Columns: []string{"id", "name"},
+			Rows: [][]interface{}{
+				{int64(1), "O'Brien"},
+				{int64(2), `back\slash`},
+				{int64(3), nil},
+			},
+		},
+		{
+			Name:    "loans",
+			Columns: []string{"id", "borrower_id"},
+			Rows: [][]interface{}{
+				{int64(1), int64(3)},
+				{int64(2), int64(1)},
+			},
+		},
+	}}
+}
+
+func TestWrite_MySQL(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, dialect.MySQL{}, testSchema(), testDataset(), Options{BatchSize: 2, Seed: 42}))
+
+	expected := "-- SourceBox SQL export\n" +
+		"-- Schema: test 1.0.0\n" +
+		"-- Database: mysql\n" +
+		"-- Seed: 42\n" +
+		"-- Rows: borrowers=3 loans=2\n" +
+		"\n" +
+		"SET NAMES utf8mb4;\n" +
+		"\n" +
+		"CREATE TABLE `borrowers` (\n" +
+		"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(50) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE `loans` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `borrower_id` int NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");\n" +
+		"\n" +
+		"START TRANSACTION;\n" +
+		"\n" +
+		"INSERT INTO `borrowers` (`id`, `name`) VALUES\n" +
+		"  (1, 'O\\'Brien'),\n" +
+		"  (2, 'back\\\\slash');\n" +
+		"INSERT INTO `borrowers` (`id`, `name`) VALUES\n" +
+		"  (3, NULL);\n" +
+		"\n" +
+		"INSERT INTO `loans` (`id`, `borrower_id`) VALUES\n" +
+		"  (1, 3),\n" +
+		"  (2, 1);\n" +
+		"\n" +
+		"COMMIT;\n" +
+		"\n" +
+		"CREATE UNIQUE INDEX `idx_name` ON `borrowers` (`name`) USING BTREE;\n" +
+		"\n" +
+		"ALTER TABLE `loans` ADD CONSTRAINT `fk_loans_borrower_id` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE ON UPDATE RESTRICT;\n"
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWrite_Postgres(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, dialect.Postgres{}, testSchema(), testDataset(), Options{}))
+	out := buf.String()
+
+	// The whole script is one transaction
+	assert.True(t, strings.Contains(out, "SET standard_conforming_strings = on;\nSET TIME ZONE 'UTC';\nBEGIN;\n"))
+	assert.True(t, strings.HasSuffix(out, "\nCOMMIT;\n"))
+	assert.NotContains(t, out, "START TRANSACTION")
+
+	assert.Contains(t, out, "CREATE TABLE \"borrowers\" (\n  \"id\" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n  \"name\" VARCHAR(50) NOT NULL,\n  PRIMARY KEY (\"id\")\n);")
+	assert.Contains(t, out, "INSERT INTO \"borrowers\" (\"id\", \"name\") VALUES\n  (1, 'O''Brien'),\n  (2, 'back\\slash'),\n  (3, NULL);\n")
+	assert.Contains(t, out, "(3, NULL);\n\nSELECT setval(pg_get_serial_sequence('\"borrowers\"', 'id'), COALESCE(MAX(\"id\"), 0) + 1, false) FROM \"borrowers\";\n",
+		"identity sequences should move past the loaded ids")
+	assert.Contains(t, out, `CREATE UNIQUE INDEX "idx_name" ON "borrowers" USING btree ("name");`)
+	assert.Contains(t, out, `ALTER TABLE "loans" ADD CONSTRAINT "fk_loans_borrower_id" FOREIGN KEY ("borrower_id") REFERENCES "borrowers" ("id") ON DELETE CASCADE ON UPDATE RESTRICT;`)
+
+	// Sections come in load order
+	create := strings.Index(out, "CREATE TABLE")
+	insert := strings.Index(out, "INSERT INTO")
+	index := strings.Index(out, "CREATE UNIQUE INDEX")
+	fk := strings.Index(out, "ALTER TABLE")
+	assert.True(t, create < insert && insert < index && index < fk, "sections should be ordered tables, data, indexes, foreign keys")
+}
+
+func TestWrite_PostgresBooleanInIntegerColumn(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql", "postgres"},
+		Tables: []schema.Table{{
+			Name:        "accounts",
+			RecordCount: 20,
+			Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "flag", Type: "tinyint", Generator: "boolean"},
+				{Name: "active", Type: "boolean", Generator: "boolean"},
+			},
+		}},
+		GenerationOrder: []string{"accounts"},
+	}
+	require.NoError(t, schema.ValidateSchema(s))
+	ds, err := generator.Generate(s, generator.Options{Seed: 1})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, dialect.Postgres{}, s, ds, Options{}))
+	out := buf.String()
+
+	assert.Contains(t, out, `"flag" SMALLINT NOT NULL`)
+	assert.Regexp(t, `\(1, [01], (TRUE|FALSE)\)`, out, "an integer flag takes 1 or 0, a boolean column TRUE or FALSE")
+	assert.NotRegexp(t, `\(\d+, (TRUE|FALSE), `, out)
+}
+
+func TestWrite_BackfillsDeferredForeignKeys(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		Version:      "1.0.0",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{{
+			Name:        "employees",
+			RecordCount: 3,
+			Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "manager_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{
+					Table: "employees", Column: "id", OnDelete: "SET NULL", OnUpdate: "CASCADE",
+				}},
+			},
+		}},
+	}
+	ds := &generator.Dataset{Tables: []*generator.TableData{{
+		Name:     "employees",
+		Columns:  []string{"id", "manager_id"},
+		Rows:     [][]interface{}{{int64(1), nil}, {int64(2), int64(1)}, {int64(3), int64(1)}},
+		Deferred: []string{"manager_id"},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, dialect.MySQL{}, s, ds, Options{}))
+
+	assert.Contains(t, buf.String(), "INSERT INTO `employees` (`id`, `manager_id`) VALUES\n"+
+		"  (1, NULL),\n"+
+		"  (2, NULL),\n"+
+		"  (3, NULL);\n"+
+		"\n"+
+		"UPDATE `employees` SET `manager_id` = 1 WHERE `id` = 2;\n"+
+		"UPDATE `employees` SET `manager_id` = 1 WHERE `id` = 3;\n"+
+		"\n"+
+		"COMMIT;\n", "deferred keys should be inserted as NULL and set before the data is committed")
+}
+
+func TestWrite_BackfillRequiresPrimaryKey(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{{
+			Name:        "employees",
+			RecordCount: 2,
+			Columns: []schema.Column{
+				{Name: "code", Type: "varchar(10)"},
+				{Name: "manager_id", Type: "int", Nullable: true},
+			},
+		}},
+	}
+	ds := &generator.Dataset{Tables: []*generator.TableData{{
+		Name:     "employees",
+		Columns:  []string{"code", "manager_id"},
+		Rows:     [][]interface{}{{"a", nil}, {"b", int64(1)}},
+		Deferred: []string{"manager_id"},
+	}}}
+
+	var buf bytes.Buffer
+	err := Write(&buf, dialect.MySQL{}, s, ds, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'employees': deferred foreign keys require a primary key")
+	assert.Empty(t, buf.String(), "nothing should be written")
+}
+
+func TestWrite_Deterministic(t *testing.T) {
+	s := testSchema()
+	s.Tables[0].Columns[1].Generator = "full_name"
+
+	render := func() string {
+		ds, err := generator.Generate(s, generator.Options{Seed: 7})
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, dialect.MySQL{}, s, ds, Options{Seed: 7}))
+		return buf.String()
+	}
+
+	assert.Equal(t, render(), render(), "same schema and seed should produce a byte-identical script")
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, assert.AnError }
+
+func TestWrite_PropagatesWriteErrors(t *testing.T) {
+	err := Write(failingWriter{}, dialect.MySQL{}, testSchema(), testDataset(), Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write SQL export")
+}
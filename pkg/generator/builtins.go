@@ -0,0 +1,784 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jbeausoleil/sourcebox/pkg/distribution"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// ValueFunc produces the value of one column for row r.
+type ValueFunc func(f *gofakeit.Faker, r int) interface{}
+
+// Builder constructs a ValueFunc for a column, reading and validating the
+// column's generator_params once up front.
+type Builder func(col *schema.Column, env *Env) (ValueFunc, error)
+
+// Env carries run-wide settings that builders may depend on.
+type Env struct {
+	// Now is the reference time for relative date generators.
+	Now time.Time
+}
+
+// builtins maps generator names (Column.Generator) to their builders.
+var builtins map[string]Builder
+
+func init() {
+	builtins = map[string]Builder{
+		// Personal data
+		"first_name":    stringBuilder(func(f *gofakeit.Faker) string { return f.FirstName() }),
+		"last_name":     stringBuilder(func(f *gofakeit.Faker) string { return f.LastName() }),
+		"full_name":     stringBuilder(func(f *gofakeit.Faker) string { return f.FirstName() + " " + f.LastName() }),
+		"email":         buildEmail,
+		"phone":         buildPhone,
+		"address":       stringBuilder(streetAddress),
+		"ssn":           stringBuilder(ssn),
+		"date_of_birth": buildDateOfBirth,
+
+		// Company data
+		"company_name":  stringBuilder(func(f *gofakeit.Faker) string { return f.Company() }),
+		"job_title":     buildJobTitle,
+		"company_email": buildCompanyEmail,
+		"domain":        stringBuilder(companyDomain),
+
+		// Date/time
+		"timestamp_past":   buildTimestampPast,
+		"timestamp_future": buildTimestampFuture,
+		"date_between":     buildDateBetween,
+
+		// Numeric
+		"int_range":     buildIntRange,
+		"float_range":   buildFloatRange,
+		"decimal_range": buildDecimalRange,
+
+		// Categorical and structured
+		"weighted":    buildWeighted,
+		"enum":        buildEnum,
+		"boolean":     buildBoolean,
+		"uuid":        stringBuilder(func(f *gofakeit.Faker) string { return f.UUID() }),
+		"json_object": buildJSONObject,
+	}
+}
+
+// Names returns the names of all built-in generators, sorted alphabetically.
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stringBuilder adapts a parameterless string generator to a Builder.
+func stringBuilder(fn func(f *gofakeit.Faker) string) Builder {
+	return func(_ *schema.Column, _ *Env) (ValueFunc, error) {
+		return func(f *gofakeit.Faker, _ int) interface{} { return fn(f) }, nil
+	}
+}
+
+// emailDomains are the fixed, non-routable domains used for personal emails.
+var emailDomains = []string{"example.com", "testmail.org", "demo.net", "sample.io"}
+
+// corporateTLDs are the top-level domains used for company domains.
+var corporateTLDs = []string{"com", "io", "net", "org", "co"}
+
+func buildEmail(_ *schema.Column, _ *Env) (ValueFunc, error) {
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		return personalEmail(f, emailDomains[f.Rand.Intn(len(emailDomains))])
+	}, nil
+}
+
+// personalEmail formats a name-based address using one of the common patterns:
+// first.last, f.last, first_last or firstlast.
+func personalEmail(f *gofakeit.Faker, domain string) string {
+	first := slug(f.FirstName())
+	last := slug(f.LastName())
+
+	var local string
+	switch f.Rand.Intn(4) {
+	case 0:
+		local = first + "." + last
+	case 1:
+		local = first[:1] + "." + last
+	case 2:
+		local = first + "_" + last
+	default:
+		local = first + last
+	}
+	return local + "@" + domain
+}
+
+func buildPhone(col *schema.Column, _ *Env) (ValueFunc, error) {
+	format, _, err := paramString(col.GeneratorParams, "format")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", "us":
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			area, prefix, line := phoneParts(f)
+			return fmt.Sprintf("(%s) %s-%s", area, prefix, line)
+		}, nil
+	case "international":
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			area, prefix, line := phoneParts(f)
+			return fmt.Sprintf("+1-%s-%s-%s", area, prefix, line)
+		}, nil
+	case "digits":
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			area, prefix, line := phoneParts(f)
+			return area + prefix + line
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid format %q: must be one of: us, international, digits", format)
+	}
+}
+
+// phoneParts returns the area code, exchange and line number of a US number.
+// Area codes and exchanges never start with 0 or 1, as in the NANP.
+func phoneParts(f *gofakeit.Faker) (string, string, string) {
+	area := fmt.Sprintf("%d%02d", 2+f.Rand.Intn(8), f.Rand.Intn(100))
+	prefix := fmt.Sprintf("%d%02d", 2+f.Rand.Intn(8), f.Rand.Intn(100))
+	line := fmt.Sprintf("%04d", f.Rand.Intn(10000))
+	return area, prefix, line
+}
+
+// unitTypes are appended to roughly 30% of street addresses.
+var unitTypes = []string{"Apt", "Suite", "Unit"}
+
+func streetAddress(f *gofakeit.Faker) string {
+	addr := f.Street()
+	if f.Rand.Float64() < 0.3 {
+		addr += fmt.Sprintf(", %s %d", unitTypes[f.Rand.Intn(len(unitTypes))], 1+f.Rand.Intn(999))
+	}
+	return addr
+}
+
+func ssn(f *gofakeit.Faker) string {
+	// Area numbers 000, 666 and 900-999 are never issued
+	area := 1 + f.Rand.Intn(665)
+	return fmt.Sprintf("%03d-%02d-%04d", area, 1+f.Rand.Intn(99), 1+f.Rand.Intn(9999))
+}
+
+func buildDateOfBirth(col *schema.Column, env *Env) (ValueFunc, error) {
+	minAge, ok, err := paramInt(col.GeneratorParams, "min_age")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		minAge = 18
+	}
+	maxAge, ok, err := paramInt(col.GeneratorParams, "max_age")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		maxAge = 80
+	}
+	if minAge < 0 || minAge > maxAge {
+		return nil, fmt.Errorf("min_age (%d) must be between 0 and max_age (%d)", minAge, maxAge)
+	}
+
+	// Ages are drawn in fractional years so that a birthday anywhere in the
+	// year is possible, up to (but excluding) the day of turning maxAge+1
+	ages, err := offsetSampler(col.GeneratorParams, float64(minAge), float64(maxAge+1))
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(env.Now)
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		age := ages.Sample(f.Rand)
+		years := int(age)
+		days := int((age - float64(years)) * 365.25)
+		dob := today.AddDate(-years, 0, -days)
+		// Never older than maxAge on the reference date
+		if oldest := today.AddDate(-maxAge-1, 0, 1); dob.Before(oldest) {
+			dob = oldest
+		}
+		return dob
+	}, nil
+}
+
+// jobLevelPrefixes maps job_title's level parameter to seniority prefixes.
+var jobLevelPrefixes = map[string][]string{
+	"entry":  {"Junior", "Associate", "Assistant", "Coordinator,"},
+	"mid":    {"Senior", "Lead", "Principal", "Manager,"},
+	"senior": {"Director of", "VP of", "Head of", "Chief"},
+}
+
+func buildJobTitle(col *schema.Column, _ *Env) (ValueFunc, error) {
+	level, _, err := paramString(col.GeneratorParams, "level")
+	if err != nil {
+		return nil, err
+	}
+	if level == "" {
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			return f.JobDescriptor() + " " + f.JobLevel() + " " + f.JobTitle()
+		}, nil
+	}
+
+	prefixes, ok := jobLevelPrefixes[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid level %q: must be one of: entry, mid, senior", level)
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		prefix := prefixes[f.Rand.Intn(len(prefixes))]
+		if strings.HasSuffix(prefix, " of") || prefix == "Chief" {
+			return prefix + " " + f.JobLevel()
+		}
+		return prefix + " " + f.JobLevel() + " " + f.JobTitle()
+	}, nil
+}
+
+func buildCompanyEmail(col *schema.Column, _ *Env) (ValueFunc, error) {
+	domain, _, err := paramString(col.GeneratorParams, "domain")
+	if err != nil {
+		return nil, err
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		d := domain
+		if d == "" {
+			d = companyDomain(f)
+		}
+		return slug(f.FirstName()) + "." + slug(f.LastName()) + "@" + d
+	}, nil
+}
+
+func companyDomain(f *gofakeit.Faker) string {
+	return slug(f.Company()) + "." + corporateTLDs[f.Rand.Intn(len(corporateTLDs))]
+}
+
+func buildTimestampPast(col *schema.Column, env *Env) (ValueFunc, error) {
+	lo, hi, err := dayWindow(col.GeneratorParams, "min_days_ago", "max_days_ago")
+	if err != nil {
+		return nil, err
+	}
+	return relativeTime(col, env.Now, -1, lo, hi)
+}
+
+func buildTimestampFuture(col *schema.Column, env *Env) (ValueFunc, error) {
+	lo, hi, err := dayWindow(col.GeneratorParams, "min_days_ahead", "max_days_ahead")
+	if err != nil {
+		return nil, err
+	}
+	return relativeTime(col, env.Now, 1, lo, hi)
+}
+
+// relativeTime returns times between lo and hi days from now, in the past
+// (direction -1) or future (direction 1). A declared distribution shapes the
+// day offsets; otherwise offsets are uniform.
+func relativeTime(col *schema.Column, now time.Time, direction, lo, hi int) (ValueFunc, error) {
+	if _, ok := col.GeneratorParams["distribution"]; !ok {
+		start, end := now.AddDate(0, 0, direction*lo), now.AddDate(0, 0, direction*hi)
+		if direction < 0 {
+			start, end = end, start
+		}
+		return timeBetween(col, start, end), nil
+	}
+
+	offsets, err := offsetSampler(col.GeneratorParams, float64(lo), float64(hi))
+	if err != nil {
+		return nil, err
+	}
+	dateOnly := col.DataType().Family() == schema.FamilyDate
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		seconds := offsets.Sample(f.Rand) * 24 * 60 * 60
+		t := now.Add(time.Duration(direction) * time.Duration(seconds) * time.Second).UTC()
+		if dateOnly {
+			return truncateDay(t)
+		}
+		return t.Truncate(time.Second)
+	}, nil
+}
+
+// dayWindow reads a min/max pair of day offsets. The maximum defaults to 365.
+func dayWindow(params map[string]interface{}, minKey, maxKey string) (int, int, error) {
+	lo, _, err := paramInt(params, minKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	hi, ok, err := paramInt(params, maxKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		hi = 365
+	}
+	if lo < 0 || lo > hi {
+		return 0, 0, fmt.Errorf("%s (%d) must be between 0 and %s (%d)", minKey, lo, maxKey, hi)
+	}
+	return lo, hi, nil
+}
+
+func buildDateBetween(col *schema.Column, _ *Env) (ValueFunc, error) {
+	start, ok, err := paramDate(col.GeneratorParams, "start_date")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("parameter 'start_date' is required")
+	}
+	end, ok, err := paramDate(col.GeneratorParams, "end_date")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("parameter 'end_date' is required")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end_date (%s) must not be before start_date (%s)",
+			end.Format(dateLayout), start.Format(dateLayout))
+	}
+	// end_date is inclusive, so allow any time on that day
+	return timeBetween(col, start, end.AddDate(0, 0, 1).Add(-time.Second)), nil
+}
+
+// timeBetween returns a ValueFunc producing times in [start, end], truncated
+// to whole days for date columns and whole seconds otherwise.
+func timeBetween(col *schema.Column, start, end time.Time) ValueFunc {
+	dateOnly := col.DataType().Family() == schema.FamilyDate
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		t := randomTime(f, start, end)
+		if dateOnly {
+			return truncateDay(t)
+		}
+		return t
+	}
+}
+
+// randomTime returns a uniformly distributed UTC time in [start, end] with
+// one-second resolution.
+func randomTime(f *gofakeit.Faker, start, end time.Time) time.Time {
+	span := end.Unix() - start.Unix()
+	if span <= 0 {
+		return start.UTC().Truncate(time.Second)
+	}
+	return time.Unix(start.Unix()+f.Rand.Int63n(span+1), 0).UTC()
+}
+
+// truncateDay drops the time-of-day component, returning midnight UTC.
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func buildIntRange(col *schema.Column, _ *Env) (ValueFunc, error) {
+	lo, hi, err := floatRange(col.GeneratorParams, 0, 1000)
+	if err != nil {
+		return nil, err
+	}
+	dist, err := distribution.FromParams(col.GeneratorParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if dist == nil {
+		minInt := int64(math.Ceil(lo))
+		maxInt := int64(math.Floor(hi))
+		if minInt > maxInt {
+			return nil, fmt.Errorf("range [%v, %v] contains no integers", lo, hi)
+		}
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			return minInt + f.Rand.Int63n(maxInt-minInt+1)
+		}, nil
+	}
+
+	// Explicit bounds still apply after rounding; absent bounds leave the
+	// distribution unconstrained
+	lower, upper := explicitBounds(col.GeneratorParams)
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		v := math.Round(dist.Sample(f.Rand))
+		v = math.Max(math.Ceil(lower), math.Min(math.Floor(upper), v))
+		return int64(v)
+	}, nil
+}
+
+func buildFloatRange(col *schema.Column, _ *Env) (ValueFunc, error) {
+	dist, err := numericSampler(col.GeneratorParams, 0, 100)
+	if err != nil {
+		return nil, err
+	}
+	precision, ok, err := paramInt(col.GeneratorParams, "precision")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		precision = 2
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		return round(dist.Sample(f.Rand), precision)
+	}, nil
+}
+
+func buildDecimalRange(col *schema.Column, _ *Env) (ValueFunc, error) {
+	dist, err := numericSampler(col.GeneratorParams, 0, 1000)
+	if err != nil {
+		return nil, err
+	}
+	scale, ok, err := paramInt(col.GeneratorParams, "scale")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		scale = decimalScale(col.DataType())
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		return round(dist.Sample(f.Rand), scale)
+	}, nil
+}
+
+// numericSampler returns the distribution declared in params, or a uniform
+// distribution over min/max (using the given defaults) when none is declared.
+func numericSampler(params map[string]interface{}, defMin, defMax float64) (distribution.Sampler, error) {
+	dist, err := distribution.FromParams(params)
+	if err != nil || dist != nil {
+		return dist, err
+	}
+	lo, hi, err := floatRange(params, defMin, defMax)
+	if err != nil {
+		return nil, err
+	}
+	return distribution.Uniform{Min: lo, Max: hi}, nil
+}
+
+// explicitBounds returns the min/max parameters, using infinities for
+// bounds that are not set.
+func explicitBounds(params map[string]interface{}) (float64, float64) {
+	lo, ok, _ := paramFloat(params, "min")
+	if !ok {
+		lo = math.Inf(-1)
+	}
+	hi, ok, _ := paramFloat(params, "max")
+	if !ok {
+		hi = math.Inf(1)
+	}
+	return lo, hi
+}
+
+// offsetSampler returns a sampler over [lo, hi] for relative date generators:
+// the declared distribution truncated to the window, or uniform.
+func offsetSampler(params map[string]interface{}, lo, hi float64) (distribution.Sampler, error) {
+	dist, err := distribution.FromParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if dist == nil {
+		return distribution.Uniform{Min: lo, Max: hi}, nil
+	}
+	return distribution.Truncated{Dist: dist, Min: lo, Max: hi}, nil
+}
+
+// round rounds v to the given number of decimal places.
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// weightedValue is one entry of a weighted/enum "values" list.
+type weightedValue struct {
+	value  interface{}
+	weight float64
+}
+
+func buildWeighted(col *schema.Column, _ *Env) (ValueFunc, error) {
+	values, err := weightedValues(col.GeneratorParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("parameter 'values' is required")
+	}
+	return pickWeighted(values), nil
+}
+
+func buildEnum(col *schema.Column, _ *Env) (ValueFunc, error) {
+	values, err := weightedValues(col.GeneratorParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		// Fall back to the values declared in the column type
+		for _, v := range col.DataType().Values {
+			values = append(values, weightedValue{value: v, weight: 1})
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("parameter 'values' is required for non-enum column types")
+	}
+	return pickWeighted(values), nil
+}
+
+// weightedValues reads the "values" parameter, accepting either
+// [{"value": "a", "weight": 0.7}, ...] or a plain list of equally likely values.
+func weightedValues(params map[string]interface{}) ([]weightedValue, error) {
+	raw, ok := params["values"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parameter 'values' must be an array")
+	}
+
+	values := make([]weightedValue, 0, len(list))
+	for i, item := range list {
+		obj, isObject := item.(map[string]interface{})
+		if !isObject {
+			values = append(values, weightedValue{value: normalizeValue(item), weight: 1})
+			continue
+		}
+
+		v, ok := obj["value"]
+		if !ok {
+			return nil, fmt.Errorf("values[%d]: 'value' is required", i)
+		}
+		w, ok, err := paramFloat(obj, "weight")
+		if err != nil {
+			return nil, fmt.Errorf("values[%d]: %w", i, err)
+		}
+		if !ok {
+			w = 1
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("values[%d]: weight must not be negative", i)
+		}
+		values = append(values, weightedValue{value: normalizeValue(v), weight: w})
+	}
+	return values, nil
+}
+
+// pickWeighted returns a ValueFunc that selects values proportionally to
+// their weights. Weights need not sum to exactly 1.
+func pickWeighted(values []weightedValue) ValueFunc {
+	cumulative := make([]float64, len(values))
+	total := 0.0
+	for i, v := range values {
+		total += v.weight
+		cumulative[i] = total
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		target := f.Rand.Float64() * total
+		i := sort.SearchFloat64s(cumulative, target)
+		if i >= len(values) {
+			i = len(values) - 1
+		}
+		return values[i].value
+	}
+}
+
+// normalizeValue converts whole-number JSON floats to int64 so integer
+// categories keep their integer type in the output.
+func normalizeValue(v interface{}) interface{} {
+	if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return v
+}
+
+func buildBoolean(col *schema.Column, _ *Env) (ValueFunc, error) {
+	p, ok, err := paramFloat(col.GeneratorParams, "true_rate")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		p = 0.5
+	}
+	if p < 0 || p > 1 {
+		return nil, fmt.Errorf("true_rate must be between 0 and 1")
+	}
+	// Integer flag columns such as tinyint(1) store 1 and 0; PostgreSQL
+	// rejects TRUE and FALSE in a SMALLINT column
+	if col.DataType().Family() == schema.FamilyInteger {
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			if f.Rand.Float64() < p {
+				return int64(1)
+			}
+			return int64(0)
+		}, nil
+	}
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		return f.Rand.Float64() < p
+	}, nil
+}
+
+// buildJSONObject generates a JSON document. The optional "fields" parameter
+// maps each key to a generator name or to {"generator": ..., "generator_params": ...}.
+func buildJSONObject(col *schema.Column, env *Env) (ValueFunc, error) {
+	raw, ok := col.GeneratorParams["fields"]
+	if !ok || raw == nil {
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			return fmt.Sprintf(`{"key":%q,"value":%d}`, f.Word(), f.Rand.Intn(1000))
+		}, nil
+	}
+
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parameter 'fields' must be an object")
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fieldFuncs := make([]ValueFunc, len(keys))
+	for i, key := range keys {
+		field := &schema.Column{Name: key, Type: "text"}
+		switch spec := fields[key].(type) {
+		case string:
+			field.Generator = spec
+		case map[string]interface{}:
+			name, _, err := paramString(spec, "generator")
+			if err != nil {
+				return nil, fmt.Errorf("fields.%s: %w", key, err)
+			}
+			field.Generator = name
+			if params, ok := spec["generator_params"].(map[string]interface{}); ok {
+				field.GeneratorParams = params
+			}
+		default:
+			return nil, fmt.Errorf("fields.%s: must be a generator name or object", key)
+		}
+
+		build, ok := builtins[field.Generator]
+		if !ok || field.Generator == "json_object" {
+			return nil, fmt.Errorf("fields.%s: unknown generator '%s'", key, field.Generator)
+		}
+		fn, err := build(field, env)
+		if err != nil {
+			return nil, fmt.Errorf("fields.%s: %w", key, err)
+		}
+		fieldFuncs[i] = fn
+	}
+
+	return func(f *gofakeit.Faker, r int) interface{} {
+		obj := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			v := fieldFuncs[i](f, r)
+			if t, ok := v.(time.Time); ok {
+				v = t.Format(time.RFC3339)
+			}
+			obj[key] = v
+		}
+		// Marshalling a map of plain values cannot fail
+		b, _ := json.Marshal(obj)
+		return string(b)
+	}, nil
+}
+
+// primaryKeyValue fills primary keys that have no generator: integer keys
+// count up from 1 like an auto-increment column, string keys get UUIDs.
+func primaryKeyValue(col *schema.Column) (ValueFunc, error) {
+	switch col.DataType().Family() {
+	case schema.FamilyInteger:
+		return func(_ *gofakeit.Faker, r int) interface{} { return int64(r + 1) }, nil
+	case schema.FamilyString:
+		return func(f *gofakeit.Faker, _ int) interface{} { return f.UUID() }, nil
+	default:
+		return nil, fmt.Errorf("primary key of type %q requires a generator", col.Type)
+	}
+}
+
+// fallbackValue fills columns that declare neither a generator nor a foreign
+// key, using the column default when present and otherwise a value suited to
+// the column type.
+func fallbackValue(col *schema.Column, env *Env) (ValueFunc, error) {
+	t := col.DataType()
+
+	if col.Default != nil {
+		v, err := defaultValue(*col.Default, t, env)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ *gofakeit.Faker, _ int) interface{} { return v }, nil
+	}
+
+	switch t.Family() {
+	case schema.FamilyInteger:
+		return func(f *gofakeit.Faker, _ int) interface{} { return int64(1 + f.Rand.Intn(1000)) }, nil
+	case schema.FamilyNumber:
+		return func(f *gofakeit.Faker, _ int) interface{} { return round(f.Rand.Float64()*1000, decimalScale(t)) }, nil
+	case schema.FamilyString:
+		return func(f *gofakeit.Faker, _ int) interface{} { return f.Sentence(6) }, nil
+	case schema.FamilyDate, schema.FamilyDatetime:
+		return timeBetween(col, env.Now.AddDate(-1, 0, 0), env.Now), nil
+	case schema.FamilyBoolean:
+		return func(f *gofakeit.Faker, _ int) interface{} { return f.Bool() }, nil
+	case schema.FamilyJSON:
+		return func(_ *gofakeit.Faker, _ int) interface{} { return "{}" }, nil
+	case schema.FamilyEnum:
+		return buildEnum(col, env)
+	default:
+		return nil, fmt.Errorf("no generator specified and no default available for type %q", col.Type)
+	}
+}
+
+// defaultValue converts a column's default expression to a typed value.
+func defaultValue(def string, t schema.DataType, env *Env) (interface{}, error) {
+	// An array default is already an array literal, such as {1,2,3}
+	if t.Array {
+		return def, nil
+	}
+	switch t.Family() {
+	case schema.FamilyInteger:
+		n, err := strconv.ParseInt(def, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("default %q is not a valid integer", def)
+		}
+		return n, nil
+	case schema.FamilyNumber:
+		n, err := strconv.ParseFloat(def, 64)
+		if err != nil {
+			return nil, fmt.Errorf("default %q is not a valid number", def)
+		}
+		return n, nil
+	case schema.FamilyBoolean:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return nil, fmt.Errorf("default %q is not a valid boolean", def)
+		}
+		return b, nil
+	case schema.FamilyDate, schema.FamilyDatetime:
+		switch strings.ToUpper(def) {
+		case "CURRENT_TIMESTAMP", "CURRENT_DATE", "NOW()":
+			if t.Family() == schema.FamilyDate {
+				return truncateDay(env.Now), nil
+			}
+			return env.Now.UTC().Truncate(time.Second), nil
+		}
+		for _, layout := range []string{dateLayout, "2006-01-02 15:04:05", time.RFC3339} {
+			if t, err := time.Parse(layout, def); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return nil, fmt.Errorf("default %q is not a valid date or timestamp", def)
+	default:
+		return def, nil
+	}
+}
+
+// slug lowercases s and strips everything but letters and digits, for use
+// in email local parts and domain names.
+func slug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "user"
+	}
+	return b.String()
+}
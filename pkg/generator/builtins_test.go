@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample builds the named generator for a column and draws n values.
+func sample(t *testing.T, col schema.Column, n int) []interface{} {
+	t.Helper()
+	build, ok := builtins[col.Generator]
+	require.True(t, ok, "generator %q should be registered", col.Generator)
+
+	fn, err := build(&col, &Env{Now: fixedNow})
+	require.NoError(t, err)
+
+	f := gofakeit.New(42)
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = fn(f, i)
+	}
+	return values
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	for _, want := range []string{
+		"first_name", "last_name", "full_name", "email", "phone", "address", "ssn", "date_of_birth",
+		"company_name", "job_title", "company_email", "domain",
+		"timestamp_past", "timestamp_future", "date_between",
+		"int_range", "float_range", "decimal_range",
+		"weighted", "enum", "boolean", "uuid", "json_object",
+	} {
+		assert.Contains(t, names, want)
+	}
+	assert.IsIncreasing(t, names)
+
+	// Every builtin is described by the schema catalog, and vice versa
+	var catalog []string
+	for _, g := range schema.Generators() {
+		catalog = append(catalog, g.Name)
+	}
+	assert.Equal(t, catalog, names)
+}
+
+func TestBuiltin_Phone(t *testing.T) {
+	tests := []struct {
+		format  string
+		pattern string
+	}{
+		{"", `^\(\d{3}\) \d{3}-\d{4}$`},
+		{"us", `^\(\d{3}\) \d{3}-\d{4}$`},
+		{"international", `^\+1-\d{3}-\d{3}-\d{4}$`},
+		{"digits", `^\d{10}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run("format="+tt.format, func(t *testing.T) {
+			col := schema.Column{Name: "phone", Type: "varchar(20)", Generator: "phone"}
+			if tt.format != "" {
+				col.GeneratorParams = map[string]interface{}{"format": tt.format}
+			}
+			for _, v := range sample(t, col, 20) {
+				assert.Regexp(t, tt.pattern, v)
+			}
+		})
+	}
+
+	t.Run("invalid format", func(t *testing.T) {
+		col := schema.Column{Name: "phone", Generator: "phone", GeneratorParams: map[string]interface{}{"format": "fax"}}
+		_, err := buildPhone(&col, &Env{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid format")
+	})
+}
+
+func TestBuiltin_SSN(t *testing.T) {
+	for _, v := range sample(t, schema.Column{Name: "ssn", Type: "char(11)", Generator: "ssn"}, 50) {
+		assert.Regexp(t, `^\d{3}-\d{2}-\d{4}$`, v)
+	}
+}
+
+func TestBuiltin_IntRange(t *testing.T) {
+	col := schema.Column{
+		Name:            "qty",
+		Type:            "int",
+		Generator:       "int_range",
+		GeneratorParams: map[string]interface{}{"min": 1.0, "max": 3.0},
+	}
+	seen := make(map[int64]bool)
+	for _, v := range sample(t, col, 200) {
+		n := v.(int64)
+		assert.GreaterOrEqual(t, n, int64(1))
+		assert.LessOrEqual(t, n, int64(3))
+		seen[n] = true
+	}
+	assert.Len(t, seen, 3, "every value in a small range should appear")
+}
+
+func TestBuiltin_RangeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator string
+		params    map[string]interface{}
+		errMsg    string
+	}{
+		{"min greater than max", "int_range", map[string]interface{}{"min": 10.0, "max": 1.0}, "must not be greater than max"},
+		{"non-numeric min", "float_range", map[string]interface{}{"min": "low", "max": 1.0}, "must be a number"},
+		{"no integers in range", "int_range", map[string]interface{}{"min": 1.2, "max": 1.8}, "contains no integers"},
+		{"fractional precision", "float_range", map[string]interface{}{"precision": 1.5}, "must be an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := schema.Column{Name: "n", Type: "float", Generator: tt.generator, GeneratorParams: tt.params}
+			_, err := builtins[tt.generator](&col, &Env{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestBuiltin_DecimalRangeUsesColumnScale(t *testing.T) {
+	col := schema.Column{
+		Name:            "amount",
+		Type:            "decimal(10,3)",
+		Generator:       "decimal_range",
+		GeneratorParams: map[string]interface{}{"min": 1.0, "max": 2.0},
+	}
+	for _, v := range sample(t, col, 50) {
+		f := v.(float64)
+		assert.InDelta(t, round(f, 3), f, 1e-12, "value should have at most 3 decimal places")
+	}
+}
+
+func TestBuiltin_DateBetween(t *testing.T) {
+	col := schema.Column{
+		Name:            "paid_on",
+		Type:            "date",
+		Generator:       "date_between",
+		GeneratorParams: map[string]interface{}{"start_date": "2023-01-01", "end_date": "2023-01-31"},
+	}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, v := range sample(t, col, 100) {
+		d := v.(time.Time)
+		assert.False(t, d.Before(start) || d.After(end), "%v should be within January 2023", d)
+		assert.Equal(t, d, truncateDay(d), "date columns should have no time component")
+	}
+
+	t.Run("end before start", func(t *testing.T) {
+		bad := col
+		bad.GeneratorParams = map[string]interface{}{"start_date": "2023-02-01", "end_date": "2023-01-01"}
+		_, err := buildDateBetween(&bad, &Env{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not be before start_date")
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		bad := col
+		bad.GeneratorParams = map[string]interface{}{"start_date": "01/01/2023", "end_date": "2023-01-01"}
+		_, err := buildDateBetween(&bad, &Env{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "YYYY-MM-DD")
+	})
+}
+
+func TestBuiltin_TimestampPast(t *testing.T) {
+	col := schema.Column{
+		Name:            "last_login",
+		Type:            "timestamp",
+		Generator:       "timestamp_past",
+		GeneratorParams: map[string]interface{}{"min_days_ago": 30.0, "max_days_ago": 90.0},
+	}
+	for _, v := range sample(t, col, 100) {
+		ts := v.(time.Time)
+		assert.False(t, ts.After(fixedNow.AddDate(0, 0, -30)))
+		assert.False(t, ts.Before(fixedNow.AddDate(0, 0, -90)))
+	}
+}
+
+func TestBuiltin_DateOfBirth(t *testing.T) {
+	col := schema.Column{
+		Name:            "dob",
+		Type:            "date",
+		Generator:       "date_of_birth",
+		GeneratorParams: map[string]interface{}{"min_age": 21.0, "max_age": 65.0},
+	}
+	for _, v := range sample(t, col, 100) {
+		dob := v.(time.Time)
+		age := fixedNow.Year() - dob.Year()
+		if fixedNow.YearDay() < dob.YearDay() {
+			age--
+		}
+		assert.GreaterOrEqual(t, age, 21)
+		assert.LessOrEqual(t, age, 65)
+	}
+}
+
+func TestBuiltin_Weighted(t *testing.T) {
+	col := schema.Column{
+		Name:      "status",
+		Type:      "varchar(20)",
+		Generator: "weighted",
+		GeneratorParams: map[string]interface{}{
+			"values": []interface{}{
+				map[string]interface{}{"value": "active", "weight": 0.9},
+				map[string]interface{}{"value": "closed", "weight": 0.1},
+			},
+		},
+	}
+	counts := make(map[interface{}]int)
+	for _, v := range sample(t, col, 1000) {
+		counts[v]++
+	}
+	assert.Len(t, counts, 2)
+	assert.Greater(t, counts["active"], 800)
+	assert.Less(t, counts["closed"], 200)
+
+	t.Run("values required", func(t *testing.T) {
+		_, err := buildWeighted(&schema.Column{Name: "status", Generator: "weighted"}, &Env{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'values' is required")
+	})
+}
+
+func TestBuiltin_EnumFallsBackToTypeValues(t *testing.T) {
+	col := schema.Column{Name: "size", Type: "enum('small','medium','large')", Generator: "enum"}
+	for _, v := range sample(t, col, 50) {
+		assert.Contains(t, []interface{}{"small", "medium", "large"}, v)
+	}
+}
+
+func TestBuiltin_JSONObject(t *testing.T) {
+	col := schema.Column{
+		Name:      "profile",
+		Type:      "json",
+		Generator: "json_object",
+		GeneratorParams: map[string]interface{}{
+			"fields": map[string]interface{}{
+				"name": "full_name",
+				"age": map[string]interface{}{
+					"generator":        "int_range",
+					"generator_params": map[string]interface{}{"min": 18.0, "max": 99.0},
+				},
+			},
+		},
+	}
+	for _, v := range sample(t, col, 10) {
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(v.(string)), &doc))
+		assert.Contains(t, doc, "name")
+		assert.Contains(t, doc, "age")
+	}
+
+	t.Run("unknown field generator", func(t *testing.T) {
+		bad := col
+		bad.GeneratorParams = map[string]interface{}{"fields": map[string]interface{}{"x": "nope"}}
+		_, err := buildJSONObject(&bad, &Env{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown generator 'nope'")
+	})
+}
+
+func TestFallbackValue_Default(t *testing.T) {
+	def := "active"
+	col := schema.Column{Name: "status", Type: "varchar(20)", Default: &def}
+	fn, err := fallbackValue(&col, &Env{Now: fixedNow})
+	require.NoError(t, err)
+	assert.Equal(t, "active", fn(gofakeit.New(1), 0))
+
+	ts := "CURRENT_TIMESTAMP"
+	col = schema.Column{Name: "created_at", Type: "timestamp", Default: &ts}
+	fn, err = fallbackValue(&col, &Env{Now: fixedNow})
+	require.NoError(t, err)
+	assert.Equal(t, fixedNow, fn(gofakeit.New(1), 0))
+
+	bad := "many"
+	col = schema.Column{Name: "count", Type: "int", Default: &bad}
+	_, err = fallbackValue(&col, &Env{Now: fixedNow})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a valid integer")
+}
+
+func TestTypeSizes(t *testing.T) {
+	tests := []struct {
+		declared string
+		length   int
+		scale    int
+	}{
+		{"int(11)", 0, 2},
+		{"VARCHAR(255)", 255, 2},
+		{"char(36)[]", 36, 2},
+		{"decimal(10,4)", 0, 4},
+		{"decimal(10)", 0, 0},
+		{"double", 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.declared, func(t *testing.T) {
+			dt, err := schema.ParseDataType(tt.declared)
+			require.NoError(t, err)
+			assert.Equal(t, tt.length, stringLength(dt))
+			assert.Equal(t, tt.scale, decimalScale(dt))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jbeausoleil/sourcebox/pkg/distribution"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// defaultZipfExponent is used for zipf cardinalities that do not set "s".
+// It gives a strongly skewed but not degenerate spread of children.
+const defaultZipfExponent = 2.0
+
+// tablePlan describes how many rows a table gets and, when one of its foreign
+// keys declares a cardinality, which parent each row references.
+type tablePlan struct {
+	rows     int
+	column   string          // foreign key column driven by cardinality, if any
+	parents  []interface{}   // parent key for each row when column is set
+	deferred map[string]bool // foreign key columns left for fillDeferred
+}
+
+// planTable sizes a table. Without a cardinality the table gets record_count
+// rows; with one, each parent's child count is drawn and the table gets their
+// sum, in shuffled order.
+func (e *Engine) planTable(t *schema.Table, ds *Dataset) (*tablePlan, error) {
+	plan := &tablePlan{rows: t.RecordCount, deferred: e.deferred[t.Name]}
+
+	for i := range t.Columns {
+		col := &t.Columns[i]
+		if col.ForeignKey == nil || col.ForeignKey.Cardinality == nil || plan.deferred[col.Name] {
+			continue
+		}
+
+		parents, err := e.assignParents(t, col, ds)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+		}
+		plan.rows = len(parents)
+		plan.column = col.Name
+		plan.parents = parents
+		break
+	}
+
+	return plan, nil
+}
+
+// assignParents draws a child count for every parent row and returns one
+// parent key per child row.
+func (e *Engine) assignParents(t *schema.Table, col *schema.Column, ds *Dataset) ([]interface{}, error) {
+	fk := col.ForeignKey
+	c := fk.Cardinality
+
+	parent := ds.Table(fk.Table)
+	if parent == nil {
+		return nil, fmt.Errorf("parent table '%s' has not been generated yet (check generation_order)", fk.Table)
+	}
+	if relationshipType(e.schema, t.Name, col) == schema.OneToOne && (c.CountColumn != "" || c.Max > 1) {
+		return nil, fmt.Errorf("cardinality allows more than one child per parent but the relationship is one_to_one")
+	}
+
+	count, err := cardinalityCounter(c, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	r := rand.New(e.stream(t.Name, col.Name, "cardinality"))
+	var parents []interface{}
+	for row, key := range parent.ColumnValues(fk.Column) {
+		n := count(r, row)
+		if key == nil {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			parents = append(parents, key)
+		}
+	}
+
+	// Interleave children so a parent's rows are not all adjacent
+	r.Shuffle(len(parents), func(i, j int) {
+		parents[i], parents[j] = parents[j], parents[i]
+	})
+
+	return parents, nil
+}
+
+// cardinalityCounter returns a function giving the number of children for
+// the parent at the given row index.
+func cardinalityCounter(c *schema.Cardinality, parent *TableData) (func(r *rand.Rand, row int) int, error) {
+	clamp := func(n int) int {
+		if n < c.Min {
+			n = c.Min
+		}
+		if c.Max > 0 && n > c.Max {
+			n = c.Max
+		}
+		return n
+	}
+
+	if c.CountColumn != "" {
+		values := parent.ColumnValues(c.CountColumn)
+		if values == nil {
+			return nil, fmt.Errorf("cardinality count_column '%s' does not exist in table '%s'", c.CountColumn, parent.Name)
+		}
+		return func(_ *rand.Rand, row int) int {
+			return clamp(countValue(values[row]))
+		}, nil
+	}
+
+	if c.Min == c.Max {
+		return func(*rand.Rand, int) int { return c.Min }, nil
+	}
+
+	kind := c.Distribution
+	if kind == "" {
+		kind = distribution.TypeUniform
+	}
+
+	params := make(map[string]interface{}, len(c.Params)+2)
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	params["min"] = float64(c.Min)
+	params["max"] = float64(c.Max)
+
+	switch kind {
+	case distribution.TypeUniform:
+		// Flooring a draw from [min, max+1) gives every count equal weight
+		params["max"] = float64(c.Max + 1)
+	case distribution.TypeZipf:
+		if _, ok := params["s"]; !ok {
+			params["s"] = defaultZipfExponent
+		}
+	case distribution.TypePoisson:
+		if _, ok := params["lambda"]; !ok {
+			params["lambda"] = float64(c.Min+c.Max) / 2
+		}
+	}
+
+	s, err := distribution.Parse(kind, params)
+	if err != nil {
+		return nil, fmt.Errorf("cardinality: distribution %q: %w", kind, err)
+	}
+
+	round := math.Round
+	if kind == distribution.TypeUniform {
+		round = math.Floor
+	}
+	return func(r *rand.Rand, _ int) int {
+		return clamp(int(round(s.Sample(r))))
+	}, nil
+}
+
+// countValue converts a generated count_column value to a child count.
+// Non-numeric values and NULLs count as zero.
+func countValue(v interface{}) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case float64:
+		return int(math.Round(n))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// borrowersLoansSchema builds a borrowers/loans schema whose loans.borrower_id
+// foreign key carries the given cardinality.
+func borrowersLoansSchema(borrowers int, c *schema.Cardinality) *schema.Schema {
+	return &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "borrowers",
+				RecordCount: borrowers,
+				Columns:     []schema.Column{{Name: "id", Type: "int", PrimaryKey: true}},
+			},
+			{
+				Name:        "loans",
+				RecordCount: 1,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{
+						Name:       "borrower_id",
+						Type:       "int",
+						ForeignKey: &schema.ForeignKey{Table: "borrowers", Column: "id", Cardinality: c},
+					},
+				},
+			},
+		},
+		GenerationOrder: []string{"borrowers", "loans"},
+	}
+}
+
+// childCounts returns how many loans reference each borrower, including
+// borrowers with no loans.
+func childCounts(ds *Dataset) map[interface{}]int {
+	counts := make(map[interface{}]int)
+	for _, id := range ds.Table("borrowers").ColumnValues("id") {
+		counts[id] = 0
+	}
+	for _, id := range ds.Table("loans").ColumnValues("borrower_id") {
+		counts[id]++
+	}
+	return counts
+}
+
+func TestGenerate_CardinalityExactlyOne(t *testing.T) {
+	s := borrowersLoansSchema(25, &schema.Cardinality{Min: 1, Max: 1})
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	assert.Len(t, ds.Table("loans").Rows, 25, "row count should follow the parents, not record_count")
+	for id, n := range childCounts(ds) {
+		assert.Equal(t, 1, n, "borrower %v", id)
+	}
+}
+
+func TestGenerate_CardinalityUniformRange(t *testing.T) {
+	s := borrowersLoansSchema(200, &schema.Cardinality{Min: 1, Max: 4})
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	seen := make(map[int]bool)
+	for _, n := range childCounts(ds) {
+		assert.GreaterOrEqual(t, n, 1)
+		assert.LessOrEqual(t, n, 4)
+		seen[n] = true
+	}
+	assert.Len(t, seen, 4, "every count from min to max should occur")
+}
+
+func TestGenerate_CardinalityZipfIsSkewed(t *testing.T) {
+	s := borrowersLoansSchema(500, &schema.Cardinality{Min: 0, Max: 10, Distribution: "zipf"})
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	none, many := 0, 0
+	for _, n := range childCounts(ds) {
+		assert.LessOrEqual(t, n, 10)
+		if n == 0 {
+			none++
+		}
+		if n >= 5 {
+			many++
+		}
+	}
+	assert.Greater(t, none, 250, "most borrowers should have no loans")
+	assert.Greater(t, many, 0, "a few borrowers should have many loans")
+}
+
+func TestGenerate_CardinalityPoisson(t *testing.T) {
+	s := borrowersLoansSchema(400, &schema.Cardinality{
+		Min:          0,
+		Max:          12,
+		Distribution: "poisson",
+		Params:       map[string]interface{}{"lambda": 3.0},
+	})
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+	assert.InDelta(t, 1200, len(ds.Table("loans").Rows), 120)
+}
+
+func TestGenerate_CardinalityCountColumn(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "loans",
+				RecordCount: 30,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "term_months", Type: "int", Generator: "weighted",
+						GeneratorParams: map[string]interface{}{"values": []interface{}{12.0, 24.0, 36.0}}},
+				},
+			},
+			{
+				Name:        "payments",
+				RecordCount: 1,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{
+						Name: "loan_id",
+						Type: "int",
+						ForeignKey: &schema.ForeignKey{
+							Table:       "loans",
+							Column:      "id",
+							Cardinality: &schema.Cardinality{CountColumn: "term_months"},
+						},
+					},
+				},
+			},
+		},
+		GenerationOrder: []string{"loans", "payments"},
+	}
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	loans := ds.Table("loans")
+	terms := make(map[interface{}]int)
+	total := 0
+	for i, id := range loans.ColumnValues("id") {
+		term := countValue(loans.ColumnValues("term_months")[i])
+		terms[id] = term
+		total += term
+	}
+
+	payments := make(map[interface{}]int)
+	for _, id := range ds.Table("payments").ColumnValues("loan_id") {
+		payments[id]++
+	}
+	assert.Len(t, ds.Table("payments").Rows, total)
+	for id, term := range terms {
+		assert.Equal(t, term, payments[id], "loan %v should have one payment per month of its term", id)
+	}
+}
+
+func TestGenerate_CardinalityDeterministic(t *testing.T) {
+	s := borrowersLoansSchema(50, &schema.Cardinality{Min: 0, Max: 6, Distribution: "zipf"})
+
+	first, err := Generate(s, Options{Seed: 9})
+	require.NoError(t, err)
+	second, err := Generate(s, Options{Seed: 9})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestGenerate_CardinalityConflictsWithOneToOne(t *testing.T) {
+	s := borrowersLoansSchema(5, &schema.Cardinality{Min: 0, Max: 3})
+	s.Relationships = []schema.Relationship{{
+		FromTable:        "loans",
+		FromColumn:       "borrower_id",
+		ToTable:          "borrowers",
+		ToColumn:         "id",
+		RelationshipType: "one_to_one",
+	}}
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'loans': column 'borrower_id': cardinality allows more than one child per parent")
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// fillDeferred generates the deferred foreign key columns of every table.
+// It runs after all tables exist, so every parent key is in the registry:
+// self-references are arranged into trees by hierarchyParents, and keys that
+// close a cycle are sampled like any other foreign key.
+func (e *Engine) fillDeferred(ds *Dataset) error {
+	for _, data := range ds.Tables {
+		t := e.schema.Table(data.Name)
+		for _, name := range data.Deferred {
+			col := t.Column(name)
+			plan := &tablePlan{rows: len(data.Rows)}
+			if col.ForeignKey.Table == t.Name {
+				parents, err := e.hierarchyParents(t, col, data)
+				if err != nil {
+					return fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+				}
+				plan.column = col.Name
+				plan.parents = parents
+			}
+
+			gen, err := e.columnGenerator(t, col, plan)
+			if err != nil {
+				return err
+			}
+
+			idx := data.ColumnIndex(name)
+			for r, row := range data.Rows {
+				v, err := gen.next(r)
+				if err != nil {
+					return fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+				}
+				row[idx] = v
+			}
+		}
+	}
+	return nil
+}
+
+// hierarchyParents assigns each row of a self-referencing table its parent
+// key following the foreign key's hierarchy (see schema.Hierarchy). Roots get
+// nil. Parents always come earlier in the table than their children.
+func (e *Engine) hierarchyParents(t *schema.Table, col *schema.Column, data *TableData) ([]interface{}, error) {
+	fk := col.ForeignKey
+	keys := data.ColumnValues(fk.Column)
+	if keys == nil {
+		return nil, fmt.Errorf("parent column '%s.%s' does not exist", fk.Table, fk.Column)
+	}
+
+	h := schema.Hierarchy{}
+	if fk.Hierarchy != nil {
+		h = *fk.Hierarchy
+	}
+	roots := max(h.Roots, 1)
+
+	r := rand.New(e.stream(t.Name, col.Name, "hierarchy"))
+	parents := make([]interface{}, len(keys))
+	depth := make([]int, len(keys))
+	children := make([]int, len(keys))
+
+	// open holds the rows that can still take another child
+	var open []int
+	for row := range keys {
+		depth[row] = 1
+		if row >= roots && len(open) > 0 {
+			i := r.Intn(len(open))
+			p := open[i]
+			parents[row] = keys[p]
+			depth[row] = depth[p] + 1
+			children[p]++
+			if h.MaxChildren > 0 && children[p] >= h.MaxChildren {
+				open[i] = open[len(open)-1]
+				open = open[:len(open)-1]
+			}
+		}
+		if keys[row] != nil && (h.MaxDepth == 0 || depth[row] < h.MaxDepth) {
+			open = append(open, row)
+		}
+	}
+
+	return parents, nil
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// employeesSchema has a self-referencing manager_id shaped by h.
+func employeesSchema(rows int, h *schema.Hierarchy) *schema.Schema {
+	return &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{{
+			Name:        "employees",
+			RecordCount: rows,
+			Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "manager_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{
+					Table: "employees", Column: "id", Hierarchy: h,
+				}},
+			},
+		}},
+	}
+}
+
+func TestGenerate_SelfReferenceHierarchy(t *testing.T) {
+	s := employeesSchema(200, &schema.Hierarchy{Roots: 2, MaxDepth: 3, MaxChildren: 5})
+
+	ds, err := Generate(s, Options{Seed: 3})
+	require.NoError(t, err)
+	data := ds.Table("employees")
+	assert.Equal(t, []string{"manager_id"}, data.Deferred)
+
+	depth := make(map[int64]int)
+	children := make(map[int64]int)
+	roots := 0
+	for _, row := range data.Rows {
+		id := row[0].(int64)
+		if row[1] == nil {
+			roots++
+			depth[id] = 1
+			continue
+		}
+		manager := row[1].(int64)
+		require.Less(t, manager, id, "managers should come before their reports")
+		depth[id] = depth[manager] + 1
+		children[manager]++
+	}
+
+	// A full tree holds 1 + 5 + 25 = 31 rows, so 200 rows need 7 trees:
+	// the 2 configured roots plus 5 started once the others were full
+	assert.Equal(t, 7, roots)
+	for id, d := range depth {
+		assert.LessOrEqual(t, d, 3, "employee %d is too deep", id)
+	}
+	for id, n := range children {
+		assert.LessOrEqual(t, n, 5, "employee %d has too many reports", id)
+	}
+
+	// Deterministic for the same seed
+	again, err := Generate(s, Options{Seed: 3})
+	require.NoError(t, err)
+	assert.Equal(t, data.Rows, again.Table("employees").Rows)
+}
+
+func TestGenerate_SelfReferenceDefaultsToOneTree(t *testing.T) {
+	ds, err := Generate(employeesSchema(50, nil), Options{})
+	require.NoError(t, err)
+
+	managers := ds.Table("employees").ColumnValues("manager_id")
+	assert.Nil(t, managers[0], "the first row is the only root")
+	for _, m := range managers[1:] {
+		assert.NotNil(t, m)
+	}
+}
+
+func TestGenerate_CycleBackfill(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "orders",
+				RecordCount: 20,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "latest_invoice_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{Table: "invoices", Column: "id"}},
+				},
+			},
+			{
+				Name:        "invoices",
+				RecordCount: 30,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "order_id", Type: "int", ForeignKey: &schema.ForeignKey{Table: "orders", Column: "id"}},
+				},
+			},
+		},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	require.Equal(t, "orders", ds.Tables[0].Name)
+
+	orders := ds.Table("orders")
+	assert.Equal(t, []string{"latest_invoice_id"}, orders.Deferred)
+	assert.Empty(t, ds.Table("invoices").Deferred)
+	for _, v := range orders.ColumnValues("latest_invoice_id") {
+		require.NotNil(t, v, "deferred keys should be filled once invoices exist")
+		assert.GreaterOrEqual(t, v.(int64), int64(1))
+		assert.LessOrEqual(t, v.(int64), int64(30))
+	}
+}
@@ -0,0 +1,436 @@
+// Package generator turns a parsed schema.Schema into rows of realistic data.
+//
+// The engine walks the schema's generation_order so parent tables are always
+// produced before the tables that reference them, generates record_count rows
+// per table, and dispatches every column to the built-in generator named by
+// Column.Generator using its generator_params.
+//
+// Columns without a generator are filled automatically:
+//   - Primary keys become sequential integers (or UUIDs for string keys)
+//   - Foreign keys are sampled from the parent keys held in a KeyRegistry,
+//     without reuse for one_to_one relationships
+//   - A foreign key with a cardinality decides how many children each parent
+//     gets, and with it the size of the child table
+//   - Deferred foreign keys (nullable self-references and nullable keys in a
+//     cycle) are filled once every table exists; self-references form trees
+//     shaped by the key's hierarchy
+//   - Other columns use their default value or a type-appropriate fallback
+//
+// Generation is deterministic: the same schema, Options.Seed and record counts
+// always yield identical rows, on any machine.
+//
+// Example usage:
+//
+//	s, err := schema.LoadSchema("schemas/example-schema.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	ds, err := generator.Generate(s, generator.Options{Seed: 42})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, t := range ds.Tables {
+//	    fmt.Printf("%s: %d rows\n", t.Name, len(t.Rows))
+//	}
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// maxUniqueAttempts bounds how many times a unique column is regenerated
+// before the engine gives up on finding an unused value.
+const maxUniqueAttempts = 1000
+
+// DefaultNow is the reference time used when Options.Now is zero. It is a
+// fixed instant rather than time.Now() so that output does not depend on the
+// day it was generated.
+var DefaultNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Options configures a generation run.
+type Options struct {
+	// Seed drives every random choice. The same schema, seed and record
+	// counts always produce identical datasets.
+	Seed int64
+
+	// Now is the reference time for relative generators such as
+	// timestamp_past and date_of_birth. Zero means DefaultNow.
+	Now time.Time
+}
+
+// Dataset holds the generated rows for every table, in generation order.
+type Dataset struct {
+	Tables []*TableData
+}
+
+// Table returns the generated data for the named table, or nil if the
+// table was not generated.
+func (d *Dataset) Table(name string) *TableData {
+	for _, t := range d.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// TableData holds the generated rows of a single table.
+// Each row has one value per entry in Columns, in the same order.
+//
+// Values are one of: nil (SQL NULL), int64, float64, string, bool or time.Time.
+type TableData struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+
+	// Deferred lists the foreign key columns whose parent rows may not exist
+	// yet when this table is inserted (see schema.DeferredForeignKeys).
+	// Writers insert NULL in these columns and set the generated values with
+	// UPDATE statements once every table has been loaded.
+	Deferred []string
+}
+
+// ColumnIndex returns the position of the named column, or -1 if absent.
+func (t *TableData) ColumnIndex(name string) int {
+	for i, c := range t.Columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ColumnValues returns every generated value of the named column.
+func (t *TableData) ColumnValues(name string) []interface{} {
+	idx := t.ColumnIndex(name)
+	if idx < 0 {
+		return nil
+	}
+	values := make([]interface{}, len(t.Rows))
+	for i, row := range t.Rows {
+		values[i] = row[idx]
+	}
+	return values
+}
+
+// Engine generates datasets from a schema.
+//
+// Every column draws from its own random stream derived from the seed and the
+// column's table and name, so adding a column or table leaves the values of
+// all other columns unchanged.
+type Engine struct {
+	schema   *schema.Schema
+	opts     Options
+	keys     *KeyRegistry
+	refs     map[string]map[string]bool
+	deferred map[string]map[string]bool
+}
+
+// New creates an Engine for the given schema.
+// The schema is expected to have passed schema.ValidateSchema.
+func New(s *schema.Schema, opts Options) *Engine {
+	if opts.Now.IsZero() {
+		opts.Now = DefaultNow
+	}
+	return &Engine{
+		schema:   s,
+		opts:     opts,
+		refs:     referencedColumns(s),
+		deferred: schema.DeferredForeignKeys(s.Tables),
+	}
+}
+
+// Generate is a convenience wrapper around New(s, opts).Generate().
+func Generate(s *schema.Schema, opts Options) (*Dataset, error) {
+	return New(s, opts).Generate()
+}
+
+// Generate produces rows for every table in the schema's generation_order,
+// or in foreign key order when the schema does not declare one.
+// Returns the first error encountered, or the complete Dataset.
+func (e *Engine) Generate() (*Dataset, error) {
+	ds := &Dataset{}
+	e.keys = NewKeyRegistry()
+
+	order, err := e.schema.TableOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range order {
+		table := e.schema.Table(name)
+		if table == nil {
+			return nil, fmt.Errorf("generation_order references table '%s' which does not exist in schema", name)
+		}
+
+		data, err := e.generateTable(table, ds)
+		if err != nil {
+			return nil, err
+		}
+		ds.Tables = append(ds.Tables, data)
+	}
+
+	if err := e.fillDeferred(ds); err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// generateTable builds a generator for each column and produces the planned
+// number of rows, recording key values in the registry as they are produced.
+func (e *Engine) generateTable(t *schema.Table, ds *Dataset) (*TableData, error) {
+	plan, err := e.planTable(t, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	gens := make([]*columnGenerator, len(t.Columns))
+	for i := range t.Columns {
+		gen, err := e.columnGenerator(t, &t.Columns[i], plan)
+		if err != nil {
+			return nil, err
+		}
+		gens[i] = gen
+	}
+
+	data := &TableData{
+		Name:    t.Name,
+		Columns: make([]string, len(t.Columns)),
+		Rows:    make([][]interface{}, plan.rows),
+	}
+	for i, col := range t.Columns {
+		data.Columns[i] = col.Name
+		if plan.deferred[col.Name] {
+			data.Deferred = append(data.Deferred, col.Name)
+		}
+	}
+	e.keys.register(t.Name)
+	refs := e.refs[t.Name]
+
+	for r := 0; r < plan.rows; r++ {
+		row := make([]interface{}, len(gens))
+		for i, gen := range gens {
+			v, err := gen.next(r)
+			if err != nil {
+				return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, gen.column.Name, err)
+			}
+			row[i] = v
+			if refs[gen.column.Name] {
+				e.keys.Record(t.Name, gen.column.Name, v)
+			}
+		}
+		data.Rows[r] = row
+	}
+
+	return data, nil
+}
+
+// columnGenerator wraps a ValueFunc with the per-column behaviour shared by
+// every generator: NULL injection and uniqueness enforcement.
+type columnGenerator struct {
+	column   *schema.Column
+	value    ValueFunc
+	faker    *gofakeit.Faker
+	nulls    *rand.Rand
+	nullRate float64
+	unique   bool
+	seen     map[interface{}]struct{}
+}
+
+// next produces the value for row r.
+func (g *columnGenerator) next(r int) (interface{}, error) {
+	if g.nullRate > 0 && g.nulls.Float64() < g.nullRate {
+		return nil, nil
+	}
+
+	if !g.unique {
+		return g.value(g.faker, r), nil
+	}
+
+	for attempt := 0; attempt < maxUniqueAttempts; attempt++ {
+		v := g.value(g.faker, r)
+		if v == nil {
+			// NULLs never collide under a UNIQUE constraint
+			return nil, nil
+		}
+		key := uniqueKey(v)
+		if _, dup := g.seen[key]; dup {
+			continue
+		}
+		g.seen[key] = struct{}{}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("could not generate a unique value after %d attempts", maxUniqueAttempts)
+}
+
+// uniqueKey normalises a value so it can be stored in a uniqueness set.
+func uniqueKey(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return v
+}
+
+// columnGenerator chooses how a column is filled: from its parent table for
+// foreign keys, from the named generator, or from a primary-key/type fallback.
+func (e *Engine) columnGenerator(t *schema.Table, col *schema.Column, plan *tablePlan) (*columnGenerator, error) {
+	gen := &columnGenerator{
+		column: col,
+		faker:  gofakeit.NewCustom(e.stream(t.Name, col.Name, "values")),
+		nulls:  rand.New(e.stream(t.Name, col.Name, "nulls")),
+		unique: col.Unique || col.PrimaryKey,
+		seen:   make(map[interface{}]struct{}),
+	}
+
+	rate, ok, err := paramFloat(col.GeneratorParams, "null_rate")
+	if err != nil {
+		return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+	}
+	if ok {
+		if !col.Nullable && rate > 0 {
+			return nil, fmt.Errorf("table '%s': column '%s': null_rate requires a nullable column", t.Name, col.Name)
+		}
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("table '%s': column '%s': null_rate must be between 0 and 1", t.Name, col.Name)
+		}
+		gen.nullRate = rate
+	}
+
+	switch {
+	case plan.deferred[col.Name]:
+		// Filled in by fillDeferred once every table has been generated
+		gen.value = func(*gofakeit.Faker, int) interface{} { return nil }
+
+	case col.Name == plan.column:
+		parents := plan.parents
+		gen.value = func(_ *gofakeit.Faker, r int) interface{} {
+			return parents[r]
+		}
+
+	case col.ForeignKey != nil && col.Generator == "":
+		fn, err := e.foreignKeyValue(t, col, plan.rows)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+		}
+		gen.value = fn
+
+	case col.Generator != "":
+		build, ok := builtins[col.Generator]
+		if !ok {
+			return nil, fmt.Errorf("table '%s': column '%s': unknown generator '%s'", t.Name, col.Name, col.Generator)
+		}
+		fn, err := build(col, e.env())
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': column '%s': generator '%s': %w", t.Name, col.Name, col.Generator, err)
+		}
+		gen.value = arrayOf(col.DataType(), fn)
+
+	case col.PrimaryKey:
+		fn, err := primaryKeyValue(col)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+		}
+		gen.value = fn
+
+	default:
+		fn, err := fallbackValue(col, e.env())
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': column '%s': %w", t.Name, col.Name, err)
+		}
+		if col.Default == nil {
+			fn = arrayOf(col.DataType(), fn)
+		}
+		gen.value = fn
+	}
+
+	if dt := col.DataType(); !dt.Array && stringLength(dt) > 0 {
+		gen.value = truncated(gen.value, stringLength(dt))
+	}
+
+	return gen, nil
+}
+
+// arrayOf turns an element generator into one for an array column of type
+// t, producing one to three elements per row as a PostgreSQL array literal
+// such as {"a","b"}. Returns fn unchanged when t is not an array.
+func arrayOf(t schema.DataType, fn ValueFunc) ValueFunc {
+	if !t.Array {
+		return fn
+	}
+	if n := stringLength(t); n > 0 {
+		fn = truncated(fn, n)
+	}
+	dateOnly := t.Family() == schema.FamilyDate
+	return func(f *gofakeit.Faker, r int) interface{} {
+		elems := make([]string, 1+f.Rand.Intn(3))
+		for i := range elems {
+			elems[i] = arrayElement(fn(f, r), dateOnly)
+		}
+		return "{" + strings.Join(elems, ",") + "}"
+	}
+}
+
+// arrayElement renders one value inside a PostgreSQL array literal. Strings
+// and times are double-quoted, with quotes and backslashes escaped.
+func arrayElement(v interface{}, dateOnly bool) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quote(v)
+	case time.Time:
+		if dateOnly {
+			return quote(v.Format(dateLayout))
+		}
+		return quote(v.Format("2006-01-02 15:04:05"))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// truncated limits string values to n characters so they fit varchar(n)
+// and char(n) columns.
+func truncated(fn ValueFunc, n int) ValueFunc {
+	return func(f *gofakeit.Faker, r int) interface{} {
+		v := fn(f, r)
+		if s, ok := v.(string); ok && utf8.RuneCountInString(s) > n {
+			return string([]rune(s)[:n])
+		}
+		return v
+	}
+}
+
+// stream returns a random source derived from the run seed and the given
+// labels. Hashing the labels (rather than drawing sub-seeds from one parent
+// stream) keeps each column's values independent of every other column.
+func (e *Engine) stream(labels ...string) rand.Source64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(e.opts.Seed))
+	h.Write(buf[:])
+	for _, label := range labels {
+		// The separator keeps ("ab", "c") and ("a", "bc") distinct
+		h.Write([]byte{0})
+		h.Write([]byte(label))
+	}
+	return rand.NewSource(int64(h.Sum64())).(rand.Source64)
+}
+
+// env returns the shared context handed to generator builders.
+func (e *Engine) env() *Env {
+	return &Env{Now: e.opts.Now}
+}
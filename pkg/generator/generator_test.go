@@ -0,0 +1,411 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadExampleSchema loads the fintech example schema shipped with the repo.
+func loadExampleSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.LoadSchema(filepath.Join("..", "..", "schemas", "example-schema.json"))
+	require.NoError(t, err)
+	return s
+}
+
+// fixedNow is the reference time used by tests that check date ranges.
+var fixedNow = time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func TestGenerate_ExampleSchema(t *testing.T) {
+	s := loadExampleSchema(t)
+
+	ds, err := Generate(s, Options{Now: fixedNow})
+	require.NoError(t, err)
+	require.Len(t, ds.Tables, 3)
+
+	// Tables come back in generation order; borrowers has record_count rows
+	// and the others follow from their foreign key cardinalities
+	for i, name := range s.GenerationOrder {
+		assert.Equal(t, name, ds.Tables[i].Name)
+		assert.NotEmpty(t, ds.Tables[i].Rows)
+	}
+	assert.Len(t, ds.Table("borrowers").Rows, s.Table("borrowers").RecordCount)
+
+	borrowers := ds.Table("borrowers")
+	require.NotNil(t, borrowers)
+	assert.Equal(t, []string{"id", "first_name", "last_name", "email", "phone", "credit_score"}, borrowers.Columns)
+
+	// Integer primary keys count up from 1
+	ids := borrowers.ColumnValues("id")
+	for i, id := range ids {
+		assert.Equal(t, int64(i+1), id)
+	}
+
+	// Unique emails
+	seen := make(map[interface{}]bool)
+	for _, email := range borrowers.ColumnValues("email") {
+		assert.False(t, seen[email], "email %v should be unique", email)
+		seen[email] = true
+		assert.Contains(t, email, "@")
+	}
+
+	// Credit scores respect int_range bounds
+	for _, v := range borrowers.ColumnValues("credit_score") {
+		score, ok := v.(int64)
+		require.True(t, ok, "credit_score should be int64, got %T", v)
+		assert.GreaterOrEqual(t, score, int64(300))
+		assert.LessOrEqual(t, score, int64(850))
+	}
+}
+
+func TestGenerate_ForeignKeysReferenceParentValues(t *testing.T) {
+	s := loadExampleSchema(t)
+
+	ds, err := Generate(s, Options{Now: fixedNow})
+	require.NoError(t, err)
+
+	borrowerIDs := make(map[interface{}]bool)
+	for _, id := range ds.Table("borrowers").ColumnValues("id") {
+		borrowerIDs[id] = true
+	}
+	for _, v := range ds.Table("loans").ColumnValues("borrower_id") {
+		assert.True(t, borrowerIDs[v], "borrower_id %v should reference an existing borrower", v)
+	}
+
+	loanIDs := make(map[interface{}]bool)
+	for _, id := range ds.Table("loans").ColumnValues("id") {
+		loanIDs[id] = true
+	}
+	for _, v := range ds.Table("payments").ColumnValues("loan_id") {
+		assert.True(t, loanIDs[v], "loan_id %v should reference an existing loan", v)
+	}
+}
+
+func TestGenerate_UnknownGenerator(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "users",
+				RecordCount: 5,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "nickname", Type: "varchar(50)", Generator: "nickname"},
+				},
+			},
+		},
+		GenerationOrder: []string{"users"},
+	}
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'users': column 'nickname': unknown generator 'nickname'")
+}
+
+func TestGenerate_MissingParentTable(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{Name: "parents", RecordCount: 2, Columns: []schema.Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+			{
+				Name:        "children",
+				RecordCount: 2,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "parent_id", Type: "int", ForeignKey: &schema.ForeignKey{Table: "parents", Column: "id"}},
+				},
+			},
+		},
+		// Child listed before its parent
+		GenerationOrder: []string{"children", "parents"},
+	}
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parent table 'parents' has not been generated yet")
+}
+
+func TestGenerate_DerivedOrder(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "children",
+				RecordCount: 2,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "parent_id", Type: "int", ForeignKey: &schema.ForeignKey{Table: "parents", Column: "id"}},
+				},
+			},
+			{Name: "parents", RecordCount: 2, Columns: []schema.Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+		},
+		// No generation_order: parents must be generated first anyway
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	require.Len(t, ds.Tables, 2)
+	assert.Equal(t, "parents", ds.Tables[0].Name)
+	assert.Equal(t, "children", ds.Tables[1].Name)
+}
+
+func TestGenerate_NullRate(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "people",
+				RecordCount: 500,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{
+						Name:            "phone",
+						Type:            "varchar(20)",
+						Nullable:        true,
+						Generator:       "phone",
+						GeneratorParams: map[string]interface{}{"null_rate": 0.5},
+					},
+				},
+			},
+		},
+		GenerationOrder: []string{"people"},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+
+	nulls := 0
+	for _, v := range ds.Table("people").ColumnValues("phone") {
+		if v == nil {
+			nulls++
+		}
+	}
+	assert.Greater(t, nulls, 150, "roughly half of the phones should be NULL")
+	assert.Less(t, nulls, 350, "roughly half of the phones should be NULL")
+}
+
+func TestGenerate_NullRateRequiresNullable(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "people",
+				RecordCount: 1,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{
+						Name:            "phone",
+						Type:            "varchar(20)",
+						Generator:       "phone",
+						GeneratorParams: map[string]interface{}{"null_rate": 0.5},
+					},
+				},
+			},
+		},
+		GenerationOrder: []string{"people"},
+	}
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "null_rate requires a nullable column")
+}
+
+func TestGenerate_TruncatesToColumnLength(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "notes",
+				RecordCount: 20,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "body", Type: "varchar(8)"},
+				},
+			},
+		},
+		GenerationOrder: []string{"notes"},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	for _, v := range ds.Table("notes").ColumnValues("body") {
+		assert.LessOrEqual(t, len([]rune(v.(string))), 8)
+	}
+}
+
+func TestGenerate_ArrayColumns(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"postgres"},
+		Tables: []schema.Table{
+			{
+				Name:        "posts",
+				RecordCount: 20,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "scores", Type: "int[]", Generator: "int_range", GeneratorParams: map[string]interface{}{"min": 1.0, "max": 9.0}},
+					{Name: "tags", Type: "varchar(4)[]", Generator: "enum", GeneratorParams: map[string]interface{}{"values": []interface{}{"go", "sql\"db"}}},
+				},
+			},
+		},
+		GenerationOrder: []string{"posts"},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	for _, v := range ds.Table("posts").ColumnValues("scores") {
+		assert.Regexp(t, `^\{[1-9](,[1-9]){0,2}\}$`, v)
+	}
+	for _, v := range ds.Table("posts").ColumnValues("tags") {
+		assert.Regexp(t, `^\{"(go|sql\\")"(,"(go|sql\\")"){0,2}\}$`, v)
+	}
+}
+
+func TestGenerate_UniqueExhausted(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "flags",
+				RecordCount: 5,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "flag", Type: "boolean", Unique: true, Generator: "boolean"},
+				},
+			},
+		},
+		GenerationOrder: []string{"flags"},
+	}
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not generate a unique value")
+}
+
+func TestGenerate_StringPrimaryKeyUsesUUID(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"postgres"},
+		Tables: []schema.Table{
+			{
+				Name:        "accounts",
+				RecordCount: 3,
+				Columns:     []schema.Column{{Name: "id", Type: "char(36)", PrimaryKey: true}},
+			},
+		},
+		GenerationOrder: []string{"accounts"},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	for _, v := range ds.Table("accounts").ColumnValues("id") {
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, v)
+	}
+}
+
+func TestGenerate_DeterministicForSameSeed(t *testing.T) {
+	s := loadExampleSchema(t)
+
+	first, err := Generate(s, Options{Seed: 42})
+	require.NoError(t, err)
+	second, err := Generate(s, Options{Seed: 42})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "same schema and seed should produce identical datasets")
+
+	other, err := Generate(s, Options{Seed: 43})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Table("borrowers").Rows, other.Table("borrowers").Rows,
+		"different seeds should produce different data")
+}
+
+func TestGenerate_DefaultNowIsFixed(t *testing.T) {
+	s := loadExampleSchema(t)
+
+	withDefault, err := Generate(s, Options{Seed: 7})
+	require.NoError(t, err)
+	withExplicit, err := Generate(s, Options{Seed: 7, Now: DefaultNow})
+	require.NoError(t, err)
+
+	assert.Equal(t, withDefault, withExplicit)
+}
+
+func TestGenerate_AddingColumnKeepsOtherStreams(t *testing.T) {
+	base := loadExampleSchema(t)
+	before, err := Generate(base, Options{Seed: 99})
+	require.NoError(t, err)
+
+	// Add a new column to loans only
+	extended := loadExampleSchema(t)
+	loans := extended.Table("loans")
+	loans.Columns = append(loans.Columns, schema.Column{
+		Name:      "memo",
+		Type:      "varchar(100)",
+		Generator: "company_name",
+	})
+	after, err := Generate(extended, Options{Seed: 99})
+	require.NoError(t, err)
+
+	// Other tables are untouched
+	assert.Equal(t, before.Table("borrowers"), after.Table("borrowers"))
+	assert.Equal(t, before.Table("payments"), after.Table("payments"))
+
+	// Existing loans columns keep their values
+	for _, col := range before.Table("loans").Columns {
+		assert.Equal(t, before.Table("loans").ColumnValues(col), after.Table("loans").ColumnValues(col),
+			"column %s should be unaffected by the new column", col)
+	}
+}
+
+func TestGenerate_ExampleSchemaDistributions(t *testing.T) {
+	s := loadExampleSchema(t)
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	// credit_score follows a normal distribution centred on 680
+	scores := ds.Table("borrowers").ColumnValues("credit_score")
+	sum := 0.0
+	for _, v := range scores {
+		sum += float64(v.(int64))
+	}
+	assert.InDelta(t, 680, sum/float64(len(scores)), 15)
+
+	// loan_amount is lognormal with a median of 15000: about half below it
+	below := 0
+	amounts := ds.Table("loans").ColumnValues("loan_amount")
+	for _, v := range amounts {
+		amount, ok := v.(float64)
+		if !ok {
+			amount = float64(v.(int64))
+		}
+		assert.GreaterOrEqual(t, amount, 1000.0)
+		assert.LessOrEqual(t, amount, 50000.0)
+		if amount < 15000 {
+			below++
+		}
+	}
+	assert.InDelta(t, len(amounts)/2, below, 0.06*float64(len(amounts)))
+
+	// interest_rate buckets span 3.5 to 25 with no explicit min/max
+	for _, v := range ds.Table("loans").ColumnValues("interest_rate") {
+		rate, ok := v.(float64)
+		if !ok {
+			rate = float64(v.(int64))
+		}
+		assert.GreaterOrEqual(t, rate, 3.5)
+		assert.LessOrEqual(t, rate, 25.0)
+	}
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// KeyRegistry records the key values of each table as its rows are produced,
+// so child tables can reference them through foreign keys.
+//
+// Primary keys are always recorded. Non-key columns are recorded only when
+// some foreign key in the schema targets them.
+type KeyRegistry struct {
+	keys map[string]map[string][]interface{}
+}
+
+// NewKeyRegistry creates an empty registry.
+func NewKeyRegistry() *KeyRegistry {
+	return &KeyRegistry{keys: make(map[string]map[string][]interface{})}
+}
+
+// Record appends a key value for table.column. NULLs are never recorded
+// because they cannot be referenced.
+func (k *KeyRegistry) Record(table, column string, value interface{}) {
+	if value == nil {
+		return
+	}
+	cols, ok := k.keys[table]
+	if !ok {
+		cols = make(map[string][]interface{})
+		k.keys[table] = cols
+	}
+	cols[column] = append(cols[column], value)
+}
+
+// Keys returns the recorded values of table.column in the order they were
+// produced, and whether the table has been registered at all.
+func (k *KeyRegistry) Keys(table, column string) ([]interface{}, bool) {
+	cols, ok := k.keys[table]
+	if !ok {
+		return nil, false
+	}
+	return cols[column], true
+}
+
+// register marks a table as generated, even if it produced no rows, so that
+// children can tell an empty parent from one that has not been generated.
+func (k *KeyRegistry) register(table string) {
+	if _, ok := k.keys[table]; !ok {
+		k.keys[table] = make(map[string][]interface{})
+	}
+}
+
+// referencedColumns returns, per table, the set of columns whose values must
+// be recorded: primary keys plus every column targeted by a foreign key.
+func referencedColumns(s *schema.Schema) map[string]map[string]bool {
+	refs := make(map[string]map[string]bool)
+	add := func(table, column string) {
+		if refs[table] == nil {
+			refs[table] = make(map[string]bool)
+		}
+		refs[table][column] = true
+	}
+
+	for _, t := range s.Tables {
+		for _, col := range t.Columns {
+			if col.PrimaryKey {
+				add(t.Name, col.Name)
+			}
+			if col.ForeignKey != nil {
+				add(col.ForeignKey.Table, col.ForeignKey.Column)
+			}
+		}
+	}
+	return refs
+}
+
+// relationshipType returns the declared relationship type for a foreign key
+// column, looking at entries written from either the child's or the parent's
+// perspective. A unique foreign key with no declared relationship is treated
+// as one_to_one, since it can never reuse a parent. Defaults to many_to_one.
+// one_to_many and many_to_one both allow a parent to be reused.
+func relationshipType(s *schema.Schema, table string, col *schema.Column) string {
+	if rel, ok := s.RelationshipFor(table, col.Name); ok {
+		return rel.RelationshipType
+	}
+	if col.Unique {
+		return schema.OneToOne
+	}
+	return schema.ManyToOne
+}
+
+// foreignKeyValue builds a ValueFunc that samples parent keys from the
+// registry.
+//
+// many_to_one (and one_to_many) relationships pick a parent uniformly and may
+// reuse it. one_to_one relationships hand out parents in a shuffled order
+// without reuse; once every parent is taken, a nullable column yields NULL
+// and a NOT NULL column is rejected up front. A nullable column whose parent
+// table is empty is always NULL.
+func (e *Engine) foreignKeyValue(t *schema.Table, col *schema.Column, rows int) (ValueFunc, error) {
+	fk := col.ForeignKey
+	keys, generated := e.keys.Keys(fk.Table, fk.Column)
+	if !generated {
+		return nil, fmt.Errorf("parent table '%s' has not been generated yet (check generation_order)", fk.Table)
+	}
+
+	if len(keys) == 0 {
+		if col.Nullable {
+			return func(*gofakeit.Faker, int) interface{} { return nil }, nil
+		}
+		return nil, fmt.Errorf("parent column '%s.%s' has no values", fk.Table, fk.Column)
+	}
+
+	if relationshipType(e.schema, t.Name, col) != schema.OneToOne {
+		return func(f *gofakeit.Faker, _ int) interface{} {
+			return keys[f.Rand.Intn(len(keys))]
+		}, nil
+	}
+
+	if !col.Nullable && rows > len(keys) {
+		return nil, fmt.Errorf("one_to_one relationship needs %d rows in '%s' but only %d were generated",
+			rows, fk.Table, len(keys))
+	}
+
+	var order []int
+	next := 0
+	return func(f *gofakeit.Faker, _ int) interface{} {
+		if order == nil {
+			order = f.Rand.Perm(len(keys))
+		}
+		if next >= len(order) {
+			return nil
+		}
+		v := keys[order[next]]
+		next++
+		return v
+	}, nil
+}
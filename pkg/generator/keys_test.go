@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userProfilesSchema builds a users/profiles schema whose profiles.user_id
+// foreign key has the given nullability and relationship type.
+func userProfilesSchema(users, profiles int, nullable bool, relType string) *schema.Schema {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "users",
+				RecordCount: users,
+				Columns:     []schema.Column{{Name: "id", Type: "int", PrimaryKey: true}},
+			},
+			{
+				Name:        "profiles",
+				RecordCount: profiles,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{
+						Name:       "user_id",
+						Type:       "int",
+						Nullable:   nullable,
+						ForeignKey: &schema.ForeignKey{Table: "users", Column: "id"},
+					},
+				},
+			},
+		},
+		GenerationOrder: []string{"users", "profiles"},
+	}
+	if relType != "" {
+		s.Relationships = []schema.Relationship{{
+			FromTable:        "profiles",
+			FromColumn:       "user_id",
+			ToTable:          "users",
+			ToColumn:         "id",
+			RelationshipType: relType,
+		}}
+	}
+	return s
+}
+
+func TestKeyRegistry(t *testing.T) {
+	k := NewKeyRegistry()
+
+	_, ok := k.Keys("users", "id")
+	assert.False(t, ok, "unregistered table should report not generated")
+
+	k.register("users")
+	keys, ok := k.Keys("users", "id")
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+
+	k.Record("users", "id", int64(1))
+	k.Record("users", "id", nil)
+	k.Record("users", "id", int64(2))
+	keys, _ = k.Keys("users", "id")
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, keys, "NULLs should not be recorded")
+}
+
+func TestGenerate_ManyToOneReusesParents(t *testing.T) {
+	s := userProfilesSchema(3, 50, false, "many_to_one")
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	counts := make(map[interface{}]int)
+	for _, v := range ds.Table("profiles").ColumnValues("user_id") {
+		require.NotNil(t, v)
+		counts[v]++
+	}
+	assert.Len(t, counts, 3)
+}
+
+func TestGenerate_OneToOneDoesNotReuseParents(t *testing.T) {
+	s := userProfilesSchema(20, 20, false, "one_to_one")
+
+	ds, err := Generate(s, Options{Seed: 1})
+	require.NoError(t, err)
+
+	seen := make(map[interface{}]bool)
+	for _, v := range ds.Table("profiles").ColumnValues("user_id") {
+		assert.False(t, seen[v], "user_id %v should only be used once", v)
+		seen[v] = true
+	}
+	assert.Len(t, seen, 20)
+}
+
+func TestGenerate_OneToOneDeclaredFromParentSide(t *testing.T) {
+	s := userProfilesSchema(10, 10, false, "")
+	s.Relationships = []schema.Relationship{{
+		FromTable:        "users",
+		FromColumn:       "id",
+		ToTable:          "profiles",
+		ToColumn:         "user_id",
+		RelationshipType: "one_to_one",
+	}}
+
+	ds, err := Generate(s, Options{Seed: 3})
+	require.NoError(t, err)
+
+	seen := make(map[interface{}]bool)
+	for _, v := range ds.Table("profiles").ColumnValues("user_id") {
+		assert.False(t, seen[v], "user_id %v should only be used once", v)
+		seen[v] = true
+	}
+}
+
+func TestGenerate_UniqueForeignKeyImpliesOneToOne(t *testing.T) {
+	s := userProfilesSchema(10, 10, false, "")
+	s.Tables[1].Columns[1].Unique = true
+
+	ds, err := Generate(s, Options{Seed: 5})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, ds.Table("users").ColumnValues("id"), ds.Table("profiles").ColumnValues("user_id"))
+}
+
+func TestGenerate_OneToOneNotEnoughParents(t *testing.T) {
+	s := userProfilesSchema(5, 10, false, "one_to_one")
+
+	_, err := Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'profiles': column 'user_id': one_to_one relationship needs 10 rows in 'users' but only 5 were generated")
+}
+
+func TestGenerate_OneToOneNullableRunsOutToNull(t *testing.T) {
+	s := userProfilesSchema(5, 10, true, "one_to_one")
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+
+	values := ds.Table("profiles").ColumnValues("user_id")
+	for i, v := range values {
+		if i < 5 {
+			assert.NotNil(t, v)
+		} else {
+			assert.Nil(t, v, "rows beyond the parent count should be NULL")
+		}
+	}
+}
+
+func TestGenerate_NullableForeignKeyWithEmptyParent(t *testing.T) {
+	s := userProfilesSchema(0, 4, true, "")
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	for _, v := range ds.Table("profiles").ColumnValues("user_id") {
+		assert.Nil(t, v)
+	}
+
+	s = userProfilesSchema(0, 4, false, "")
+	_, err = Generate(s, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parent column 'users.id' has no values")
+}
+
+func TestGenerate_ForeignKeyToNonPrimaryKeyColumn(t *testing.T) {
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{
+			{
+				Name:        "currencies",
+				RecordCount: 3,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "code", Type: "char(3)", Unique: true, Generator: "enum",
+						GeneratorParams: map[string]interface{}{"values": []interface{}{"USD", "EUR", "GBP"}}},
+				},
+			},
+			{
+				Name:        "prices",
+				RecordCount: 20,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "currency", Type: "char(3)", ForeignKey: &schema.ForeignKey{Table: "currencies", Column: "code"}},
+				},
+			},
+		},
+		GenerationOrder: []string{"currencies", "prices"},
+	}
+
+	ds, err := Generate(s, Options{})
+	require.NoError(t, err)
+	for _, v := range ds.Table("prices").ColumnValues("currency") {
+		assert.Contains(t, []interface{}{"USD", "EUR", "GBP"}, v)
+	}
+}
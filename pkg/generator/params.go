@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// dateLayout is the YYYY-MM-DD format used by date parameters such as
+// date_between's start_date and end_date.
+const dateLayout = "2006-01-02"
+
+// paramFloat reads a numeric parameter. JSON numbers decode as float64, but
+// integer types are accepted so schemas built in Go code work too.
+// Returns ok=false when the parameter is absent.
+func paramFloat(params map[string]interface{}, key string) (float64, bool, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return 0, false, nil
+	}
+
+	switch v := raw.(type) {
+	case float64:
+		return v, true, nil
+	case float32:
+		return float64(v), true, nil
+	case int:
+		return float64(v), true, nil
+	case int64:
+		return float64(v), true, nil
+	case int32:
+		return float64(v), true, nil
+	default:
+		return 0, false, fmt.Errorf("parameter '%s' must be a number, got %T", key, raw)
+	}
+}
+
+// paramInt reads an integer parameter, rejecting numbers with a fractional part.
+func paramInt(params map[string]interface{}, key string) (int, bool, error) {
+	f, ok, err := paramFloat(params, key)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if f != float64(int(f)) {
+		return 0, false, fmt.Errorf("parameter '%s' must be an integer, got %v", key, f)
+	}
+	return int(f), true, nil
+}
+
+// paramString reads a string parameter.
+func paramString(params map[string]interface{}, key string) (string, bool, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return "", false, nil
+	}
+	s, isString := raw.(string)
+	if !isString {
+		return "", false, fmt.Errorf("parameter '%s' must be a string, got %T", key, raw)
+	}
+	return s, true, nil
+}
+
+// paramDate reads a YYYY-MM-DD date parameter.
+func paramDate(params map[string]interface{}, key string) (time.Time, bool, error) {
+	s, ok, err := paramString(params, key)
+	if err != nil || !ok {
+		return time.Time{}, ok, err
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parameter '%s' must be a YYYY-MM-DD date, got %q", key, s)
+	}
+	return t, true, nil
+}
+
+// floatRange reads min/max parameters, applying defaults for missing values.
+func floatRange(params map[string]interface{}, defMin, defMax float64) (float64, float64, error) {
+	lo, ok, err := paramFloat(params, "min")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		lo = defMin
+	}
+
+	hi, ok, err := paramFloat(params, "max")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		hi = defMax
+	}
+
+	if lo > hi {
+		return 0, 0, fmt.Errorf("min (%v) must not be greater than max (%v)", lo, hi)
+	}
+	return lo, hi, nil
+}
+
+// stringLength returns the declared length of a char or varchar type, or
+// 0 when unbounded.
+func stringLength(t schema.DataType) int {
+	if t.Base == "varchar" || t.Base == "char" {
+		return t.Length
+	}
+	return 0
+}
+
+// decimalScale returns the number of decimal places to round generated
+// numbers to: the declared scale of decimal(p,s) types, 2 otherwise.
+func decimalScale(t schema.DataType) int {
+	if t.Base == "decimal" && t.Precision > 0 {
+		return t.Scale
+	}
+	return 2
+}
@@ -0,0 +1,215 @@
+// Package registry discovers the schemas available to SourceBox: the
+// built-in schemas compiled into the binary plus any schema files found in
+// user schema directories.
+//
+// Built-ins are loaded first and always win, so a user file can never
+// silently change what a well-known name like fintech-loans generates.
+// Files that fail to load, or that reuse a name that is already taken, are
+// skipped and reported through Warnings instead of failing the whole
+// registry.
+//
+// Example usage:
+//
+//	reg := registry.Load(schemas.FS, registry.DefaultDirs())
+//	for _, e := range reg.Filter(registry.Filter{Industry: "fintech"}) {
+//	    fmt.Println(e.Name, e.TotalRecords)
+//	}
+package registry
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// BuiltinSource is the Entry.Source of schemas compiled into the binary.
+const BuiltinSource = "built-in"
+
+// PathEnv names the environment variable listing extra schema directories,
+// separated like PATH.
+const PathEnv = "SOURCEBOX_SCHEMA_PATH"
+
+// Entry summarises one available schema.
+type Entry struct {
+	Name           string   `json:"name" yaml:"name"`
+	Description    string   `json:"description" yaml:"description"`
+	Industry       string   `json:"industry" yaml:"industry"`
+	Tags           []string `json:"tags" yaml:"tags"`
+	Tables         int      `json:"tables" yaml:"tables"`
+	TotalRecords   int      `json:"total_records" yaml:"total_records"`
+	ComplexityTier int      `json:"complexity_tier" yaml:"complexity_tier"`
+
+	// Source is BuiltinSource or the path of the file the schema came from.
+	Source string `json:"source" yaml:"source"`
+
+	// Schema is the parsed definition.
+	Schema *schema.Schema `json:"-" yaml:"-"`
+}
+
+// Filter selects entries. Zero-valued fields match everything; string
+// fields are compared case-insensitively.
+type Filter struct {
+	Industry string
+	Tag      string
+	Tier     int
+}
+
+// Match reports whether e satisfies every field of f.
+func (f Filter) Match(e Entry) bool {
+	if f.Industry != "" && !strings.EqualFold(f.Industry, e.Industry) {
+		return false
+	}
+	if f.Tier != 0 && f.Tier != e.ComplexityTier {
+		return false
+	}
+	if f.Tag == "" {
+		return true
+	}
+	for _, tag := range e.Tags {
+		if strings.EqualFold(f.Tag, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the schemas discovered by Load, sorted by name.
+type Registry struct {
+	entries  []Entry
+	warnings []error
+}
+
+// DefaultDirs returns the user schema directories: every entry of
+// $SOURCEBOX_SCHEMA_PATH followed by ~/.sourcebox/schemas.
+func DefaultDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(PathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".sourcebox", "schemas"))
+	}
+	return dirs
+}
+
+// schemaPatterns match the schema files Load picks up.
+var schemaPatterns = []string{"*.json", "*.yaml", "*.yml"}
+
+// Load discovers the *.json, *.yaml and *.yml schemas at the root of
+// builtin and in each of dirs. Directories that do not exist are ignored.
+func Load(builtin fs.FS, dirs []string) *Registry {
+	r := &Registry{}
+
+	if builtin != nil {
+		var names []string
+		for _, pattern := range schemaPatterns {
+			matches, err := fs.Glob(builtin, pattern)
+			if err != nil {
+				r.warnings = append(r.warnings, fmt.Errorf("failed to list built-in schemas: %w", err))
+			}
+			names = append(names, matches...)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.addBuiltin(builtin, name)
+		}
+	}
+
+	for _, dir := range dirs {
+		var files []string
+		for _, pattern := range schemaPatterns {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				r.warnings = append(r.warnings, fmt.Errorf("failed to list schemas in %s: %w", dir, err))
+				continue
+			}
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			s, err := schema.LoadSchema(file)
+			if err != nil {
+				r.warnings = append(r.warnings, err)
+				continue
+			}
+			r.add(s, file)
+		}
+	}
+
+	sort.SliceStable(r.entries, func(i, j int) bool { return r.entries[i].Name < r.entries[j].Name })
+	return r
+}
+
+func (r *Registry) addBuiltin(fsys fs.FS, name string) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		r.warnings = append(r.warnings, fmt.Errorf("built-in schema %s: %w", name, err))
+		return
+	}
+	defer f.Close()
+
+	s, err := schema.Parse(f, schema.FormatOf(name))
+	if err != nil {
+		r.warnings = append(r.warnings, fmt.Errorf("built-in schema %s: %w", name, err))
+		return
+	}
+	r.add(s, BuiltinSource)
+}
+
+func (r *Registry) add(s *schema.Schema, source string) {
+	if existing, ok := r.Get(s.Name); ok {
+		r.warnings = append(r.warnings, fmt.Errorf("%s: schema '%s' is already defined by %s", source, s.Name, existing.Source))
+		return
+	}
+
+	r.entries = append(r.entries, Entry{
+		Name:           s.Name,
+		Description:    s.Description,
+		Industry:       s.Metadata.Industry,
+		Tags:           s.Metadata.Tags,
+		Tables:         len(s.Tables),
+		TotalRecords:   s.Metadata.TotalRecords,
+		ComplexityTier: s.Metadata.ComplexityTier,
+		Source:         source,
+		Schema:         s,
+	})
+}
+
+// Entries returns every schema, sorted by name.
+func (r *Registry) Entries() []Entry {
+	return r.entries
+}
+
+// Filter returns the entries matching f, sorted by name.
+func (r *Registry) Filter(f Filter) []Entry {
+	var matched []Entry
+	for _, e := range r.entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// Get returns the entry with the given name.
+func (r *Registry) Get(name string) (*Entry, bool) {
+	for i := range r.entries {
+		if r.entries[i].Name == name {
+			return &r.entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Warnings returns the problems found while loading: unreadable or invalid
+// schema files and duplicate names.
+func (r *Registry) Warnings() []error {
+	return r.warnings
+}
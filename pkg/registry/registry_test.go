@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jbeausoleil/sourcebox/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaJSON returns a minimal valid schema document.
+func schemaJSON(name, industry string, tier int, tags ...string) string {
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = strconv.Quote(tag)
+	}
+	return `{
+  "schema_version": "1.0",
+  "name": "` + name + `",
+  "description": "Test schema",
+  "author": "Tests",
+  "version": "1.0.0",
+  "database_type": ["mysql"],
+  "metadata": {"industry": "` + industry + `", "tags": [` + strings.Join(quoted, ", ") + `], "total_records": 10, "complexity_tier": ` + strconv.Itoa(tier) + `},
+  "tables": [{"name": "t", "record_count": 10, "columns": [{"name": "id", "type": "int", "primary_key": true}]}],
+  "generation_order": ["t"]
+}`
+}
+
+func writeSchema(t *testing.T, dir, file, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad_BuiltinSchemas(t *testing.T) {
+	reg := Load(schemas.FS, nil)
+
+	assert.Empty(t, reg.Warnings(), "built-in schemas should always load")
+	var names []string
+	for _, e := range reg.Entries() {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"fintech-loans", "healthcare-patients", "retail-orders"}, names)
+
+	e, ok := reg.Get("fintech-loans")
+	require.True(t, ok, "fintech-loans should be built in")
+	assert.Equal(t, "fintech", e.Industry)
+	assert.Equal(t, 3, e.Tables)
+	assert.Equal(t, 16200, e.TotalRecords)
+	assert.Equal(t, BuiltinSource, e.Source)
+	require.NotNil(t, e.Schema)
+	assert.Equal(t, "fintech-loans", e.Schema.Name)
+}
+
+func TestLoad_UserDirectories(t *testing.T) {
+	builtin := fstest.MapFS{
+		"b.json": {Data: []byte(schemaJSON("bravo", "retail", 2, "orders"))},
+	}
+	dir := t.TempDir()
+	alpha := writeSchema(t, dir, "alpha.json", schemaJSON("alpha", "fintech", 1, "loans"))
+	writeSchema(t, dir, "notes.txt", "not a schema")
+
+	reg := Load(builtin, []string{dir, filepath.Join(dir, "missing")})
+
+	assert.Empty(t, reg.Warnings(), "non-JSON files and missing directories should be ignored")
+	entries := reg.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "alpha", entries[0].Name, "entries should be sorted by name")
+	assert.Equal(t, alpha, entries[0].Source)
+	assert.Equal(t, "bravo", entries[1].Name)
+	assert.Equal(t, BuiltinSource, entries[1].Source)
+}
+
+func TestLoad_YAMLSchemas(t *testing.T) {
+	yamlSchema := func(name string) string {
+		return `# a YAML schema
+name: ` + name + `
+database_type: [mysql]
+metadata: {industry: retail, tags: [orders]}
+tables:
+  - name: t
+    record_count: 10
+    columns:
+      - {name: id, type: int, primary_key: true}
+`
+	}
+	builtin := fstest.MapFS{
+		"d.yml": {Data: []byte(yamlSchema("delta"))},
+	}
+	dir := t.TempDir()
+	echo := writeSchema(t, dir, "echo.yaml", yamlSchema("echo"))
+	writeSchema(t, dir, "broken.yml", "name: [")
+
+	reg := Load(builtin, []string{dir})
+
+	entries := reg.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "delta", entries[0].Name)
+	assert.Equal(t, BuiltinSource, entries[0].Source)
+	assert.Equal(t, "echo", entries[1].Name)
+	assert.Equal(t, echo, entries[1].Source)
+	assert.Equal(t, []string{"orders"}, entries[1].Tags)
+
+	require.Len(t, reg.Warnings(), 1)
+	assert.Contains(t, reg.Warnings()[0].Error(), "broken.yml")
+}
+
+func TestLoad_Warnings(t *testing.T) {
+	builtin := fstest.MapFS{
+		"a.json": {Data: []byte(schemaJSON("alpha", "fintech", 1))},
+	}
+	dir := t.TempDir()
+	writeSchema(t, dir, "broken.json", `{"name": `)
+	writeSchema(t, dir, "shadow.json", schemaJSON("alpha", "healthcare", 3))
+
+	reg := Load(builtin, []string{dir})
+
+	require.Len(t, reg.Entries(), 1, "invalid and duplicate schemas should be skipped")
+	assert.Equal(t, BuiltinSource, reg.Entries()[0].Source, "built-ins should win over user files")
+
+	require.Len(t, reg.Warnings(), 2)
+	assert.Contains(t, reg.Warnings()[0].Error(), "broken.json")
+	assert.Contains(t, reg.Warnings()[1].Error(), "schema 'alpha' is already defined by built-in")
+}
+
+func TestFilter(t *testing.T) {
+	builtin := fstest.MapFS{
+		"a.json": {Data: []byte(schemaJSON("alpha", "fintech", 1, "loans", "credit"))},
+		"b.json": {Data: []byte(schemaJSON("bravo", "fintech", 2, "cards"))},
+		"c.json": {Data: []byte(schemaJSON("charlie", "healthcare", 2, "patients"))},
+	}
+	reg := Load(builtin, nil)
+
+	names := func(f Filter) []string {
+		var out []string
+		for _, e := range reg.Filter(f) {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"alpha", "bravo", "charlie"}, names(Filter{}))
+	assert.Equal(t, []string{"alpha", "bravo"}, names(Filter{Industry: "FinTech"}))
+	assert.Equal(t, []string{"alpha"}, names(Filter{Tag: "credit"}))
+	assert.Equal(t, []string{"bravo", "charlie"}, names(Filter{Tier: 2}))
+	assert.Equal(t, []string{"bravo"}, names(Filter{Industry: "fintech", Tier: 2}))
+	assert.Empty(t, names(Filter{Tag: "nope"}))
+}
+
+func TestDefaultDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(PathEnv, "/a"+string(os.PathListSeparator)+"/b")
+
+	assert.Equal(t, []string{"/a", "/b", filepath.Join(home, ".sourcebox", "schemas")}, DefaultDirs())
+}
@@ -0,0 +1,202 @@
+package sample
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// maxCategories is the most distinct values a column may have to be
+// generated from a weighted list of them. A column also needs each value
+// to appear twice on average, so that unique values such as names are not
+// mistaken for categories.
+const maxCategories = 20
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// fit chooses the generator of a column that is not a key, with its
+// parameters fitted to the observed values. It returns "" when the values
+// say nothing the type-based fallback would not do.
+func fit(col *schema.Column, c *column) (string, map[string]interface{}) {
+	switch c.kind {
+	case kindBoolean:
+		trues := 0
+		for _, v := range c.values {
+			if booleans[strings.ToLower(v)] {
+				trues++
+			}
+		}
+		return "boolean", map[string]interface{}{"true_rate": round(float64(trues)/float64(len(c.values)), 4)}
+
+	case kindInteger, kindNumber:
+		if values, ok := categories(c); ok {
+			return "weighted", map[string]interface{}{"values": values}
+		}
+		if c.kind == kindInteger {
+			return "int_range", numeric(c, 0)
+		}
+		t := col.DataType()
+		if t.Base == "decimal" {
+			return "decimal_range", numeric(c, t.Scale)
+		}
+		return "float_range", numeric(c, 4)
+
+	case kindDate, kindDatetime, kindTimestamp:
+		return "date_between", dateRange(c)
+
+	case kindUUID:
+		return "uuid", nil
+
+	case kindString:
+		if all(c.values, emailPattern.MatchString) {
+			return "email", nil
+		}
+		if generator, params := schema.SuggestGenerator(col); generator != "" {
+			return generator, params
+		}
+		if values, ok := categories(c); ok {
+			return "weighted", map[string]interface{}{"values": values}
+		}
+	}
+	return "", nil
+}
+
+// categories returns the distinct values of c with their observed
+// frequencies as weights, most frequent first, when c has few enough of
+// them to be a category.
+func categories(c *column) ([]interface{}, bool) {
+	counts := make(map[string]int)
+	var order []string
+	for _, v := range c.values {
+		v = canonical(v, c.kind)
+		if counts[v] == 0 {
+			order = append(order, v)
+		}
+		counts[v]++
+	}
+	if len(order) > maxCategories || 2*len(order) > len(c.values) {
+		return nil, false
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	values := make([]interface{}, len(order))
+	for i, v := range order {
+		var value interface{} = v
+		if c.kind == kindInteger || c.kind == kindNumber {
+			value, _ = strconv.ParseFloat(v, 64)
+		}
+		values[i] = map[string]interface{}{
+			"value":  value,
+			"weight": round(float64(counts[v])/float64(len(c.values)), 4),
+		}
+	}
+	return values, true
+}
+
+// numeric returns the range of c's values and a distribution fitted to
+// them: lognormal for positive values with a long right tail, normal
+// otherwise. Fitted parameters are rounded to two decimal places more than
+// the values have.
+func numeric(c *column, scale int) map[string]interface{} {
+	xs := make([]float64, len(c.values))
+	for i, v := range c.values {
+		xs[i], _ = strconv.ParseFloat(v, 64)
+	}
+	lo, hi := xs[0], xs[0]
+	sum := 0.0
+	for _, x := range xs {
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+		sum += x
+	}
+	params := map[string]interface{}{"min": lo, "max": hi}
+
+	n := float64(len(xs))
+	mean := sum / n
+	variance, skew := 0.0, 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	if len(xs) < 3 || variance == 0 {
+		return params
+	}
+	stdDev := math.Sqrt(variance / (n - 1))
+	for _, x := range xs {
+		skew += math.Pow((x-mean)/stdDev, 3)
+	}
+	skew /= n
+
+	places := scale + 2
+	if lo > 0 && skew > 1 {
+		var logSum, logVariance float64
+		for _, x := range xs {
+			logSum += math.Log(x)
+		}
+		mu := logSum / n
+		for _, x := range xs {
+			logVariance += (math.Log(x) - mu) * (math.Log(x) - mu)
+		}
+		if sigma := math.Sqrt(logVariance / (n - 1)); round(sigma, 4) > 0 {
+			params["distribution"] = map[string]interface{}{
+				"type":   "lognormal",
+				"params": map[string]interface{}{"median": round(math.Exp(mu), places), "sigma": round(sigma, 4), "min": lo, "max": hi},
+			}
+			return params
+		}
+	}
+	if stdDev = round(stdDev, places); stdDev > 0 {
+		params["distribution"] = map[string]interface{}{
+			"type":   "normal",
+			"params": map[string]interface{}{"mean": round(mean, places), "std_dev": stdDev, "min": lo, "max": hi},
+		}
+	}
+	return params
+}
+
+// dateRange returns the first and last day of c's values as date_between
+// parameters.
+func dateRange(c *column) map[string]interface{} {
+	layouts := map[kind][]string{kindDate: dateLayouts, kindDatetime: datetimeLayouts, kindTimestamp: zonedLayouts}[c.kind]
+	var first, last time.Time
+	for i, v := range c.values {
+		t, _ := parseTime(v, layouts)
+		if i == 0 || t.Before(first) {
+			first = t
+		}
+		if i == 0 || t.After(last) {
+			last = t
+		}
+	}
+	return map[string]interface{}{
+		"start_date": first.UTC().Format("2006-01-02"),
+		"end_date":   last.UTC().Format("2006-01-02"),
+	}
+}
+
+// parseTime parses v with the first layout that fits.
+func parseTime(v string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func jsonValid(v string) bool {
+	return json.Valid([]byte(v))
+}
+
+// round rounds x to the given number of decimal places.
+func round(x float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(x*p) / p
+}
@@ -0,0 +1,96 @@
+package sample
+
+import (
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/distribution"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profiled(name string, values ...string) (*schema.Column, *column) {
+	c := &column{name: name, values: values, kind: kindOf(values)}
+	return &schema.Column{Name: name, Type: c.kind.dataType(values)}, c
+}
+
+func TestFit_Numeric(t *testing.T) {
+	col, c := profiled("score", "52", "61", "48", "70", "55", "58", "66", "49", "63", "57", "44", "71", "59", "62", "53", "68", "50", "65", "60", "47", "56")
+	generator, params := fit(col, c)
+	assert.Equal(t, "int_range", generator)
+	assert.Equal(t, 44.0, params["min"])
+	assert.Equal(t, 71.0, params["max"])
+	assert.Equal(t, map[string]interface{}{
+		"type":   "normal",
+		"params": map[string]interface{}{"mean": 57.81, "std_dev": 7.8, "min": 44.0, "max": 71.0},
+	}, params["distribution"])
+
+	col.Generator, col.GeneratorParams = generator, params
+	require.NoError(t, schema.ValidateGenerator(col, "t"))
+	_, err := distribution.FromParams(params)
+	require.NoError(t, err)
+}
+
+func TestFit_Skewed(t *testing.T) {
+	col, c := profiled("amount", "10.00", "12.50", "11.00", "9.75", "14.20", "10.80", "13.10", "250.00", "12.00", "11.40", "980.00", "10.10", "15.30", "9.90", "12.75", "11.80", "13.60", "10.40", "400.00", "12.20", "11.10")
+	generator, params := fit(col, c)
+	assert.Equal(t, "decimal_range", generator)
+	dist := params["distribution"].(map[string]interface{})
+	assert.Equal(t, "lognormal", dist["type"], "a long right tail")
+	assert.Equal(t, 19.7392, dist["params"].(map[string]interface{})["median"], "the geometric mean, not dragged up by the tail")
+
+	col.Generator, col.GeneratorParams = generator, params
+	require.NoError(t, schema.ValidateGenerator(col, "t"))
+}
+
+func TestFit_Uniform(t *testing.T) {
+	col, c := profiled("ratio", "0.5")
+	generator, params := fit(col, c)
+	assert.Equal(t, "decimal_range", generator)
+	assert.Equal(t, map[string]interface{}{"min": 0.5, "max": 0.5}, params, "too few values to fit a distribution")
+
+	col, c = profiled("ratio", "1e-3", "2e-3", "3.5e-3", "1.5e-2")
+	generator, params = fit(col, c)
+	assert.Equal(t, "float_range", generator)
+	assert.Equal(t, "normal", params["distribution"].(map[string]interface{})["type"])
+}
+
+func TestFit_Strings(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []string
+		generator string
+	}{
+		{"contact", []string{"a@x.io", "b@y.org"}, "email"},
+		{"phone_number", []string{"555-0100", "555-0101"}, "phone"},
+		{"status", []string{"new", "done", "new", "new"}, "weighted"},
+		{"comment", []string{"fine", "great", "meh"}, ""},
+		{"token", []string{"1b4e28ba-2fa1-11d2-883f-0016d3cca427"}, "uuid"},
+		{"seen_at", []string{"2024-01-01 10:00:00", "2023-12-31 09:00:00"}, "date_between"},
+		{"payload", []string{`{"a": 1}`}, ""},
+	}
+	for _, tt := range tests {
+		col, c := profiled(tt.name, tt.values...)
+		generator, _ := fit(col, c)
+		assert.Equal(t, tt.generator, generator, tt.name)
+	}
+
+	col, c := profiled("seen_at", "2024-01-01 10:00:00", "2023-12-31 09:00:00")
+	_, params := fit(col, c)
+	assert.Equal(t, map[string]interface{}{"start_date": "2023-12-31", "end_date": "2024-01-01"}, params)
+}
+
+func TestCategories(t *testing.T) {
+	_, c := profiled("n", "2", "1", "2", "+2", "1", "3")
+	values, ok := categories(c)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"value": 2.0, "weight": 0.5},
+		map[string]interface{}{"value": 1.0, "weight": 0.3333},
+		map[string]interface{}{"value": 3.0, "weight": 0.1667},
+	}, values)
+
+	_, c = profiled("n", "a", "b", "c", "a")
+	_, ok = categories(c)
+	assert.False(t, ok, "values must repeat")
+}
@@ -0,0 +1,190 @@
+package sample
+
+import (
+	"strconv"
+	"strings"
+)
+
+// primaryKey picks t's primary key: a column with a value in every row and
+// no value twice. A column named id, or after the table (customer_id in
+// customers), is preferred; then a leading integer or UUID column, as
+// exports usually start with the key; then any UUID column, or a string
+// column named like a key, such as code. A table with no candidate gets an
+// id column, numbered by the generator.
+func (im *importer) primaryKey(t *table) {
+	var best *column
+	bestScore := 0
+	for i, c := range t.columns {
+		if c.nulls > 0 || len(c.values) == 0 || !c.kind.key() || !distinct(c) {
+			continue
+		}
+		score := 0
+		switch {
+		case c.name == "id" || c.name == singular(t.name)+"_id" || c.name == t.name+"_id":
+			score = 3
+		case i == 0 && c.kind != kindString:
+			score = 2
+		case c.kind == kindUUID || (c.kind == kindString && keyName(c.name)):
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best != nil {
+		t.pk = best
+		return
+	}
+
+	name := "id"
+	for n := 2; t.column(name) != nil; n++ {
+		name = "id_" + strconv.Itoa(n)
+	}
+	t.pk = &column{name: name, kind: kindInteger}
+	t.synthetic = true
+	im.warn(t.at, "table '%s' has no column with a distinct value in every row; added primary key '%s'", t.name, name)
+}
+
+// column returns t's column with the given name, or nil.
+func (t *table) column(name string) *column {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// foreignKeys links each column of t whose values all appear in another
+// table's primary key, and whose name suggests it refers to that table, to
+// that table. A name that contains the table's name (customer_id or
+// customer for customers) settles it; a name that merely looks like a key
+// (ending in _id, _key, _ref or _code) only counts when one table matches.
+// A table refers to itself only through a nullable column.
+func (im *importer) foreignKeys(t *table) {
+	for _, c := range t.columns {
+		if c == t.pk || len(c.values) == 0 {
+			continue
+		}
+
+		var named, keyed []*table
+		for _, parent := range im.tables {
+			pk := parent.pk
+			if parent.synthetic || !compatible(c.kind, pk.kind) || (parent == t && c.nulls == 0) {
+				continue
+			}
+			if !contained(c, pk) {
+				continue
+			}
+			switch {
+			case refersTo(c.name, parent) || (pk.name != "id" && c.name == pk.name):
+				named = append(named, parent)
+			case keyName(c.name):
+				keyed = append(keyed, parent)
+			}
+		}
+
+		switch {
+		case len(named) > 0:
+			c.ref = named[0]
+		case len(keyed) == 1:
+			c.ref = keyed[0]
+		case len(keyed) > 1:
+			names := make([]string, len(keyed))
+			for i, p := range keyed {
+				names[i] = p.name + "." + p.pk.name
+			}
+			im.warn(c.at, "table '%s': column '%s' could refer to %s; no foreign key added", t.name, c.name, strings.Join(names, " or "))
+		}
+	}
+}
+
+// key reports whether columns of kind k can be keys.
+func (k kind) key() bool {
+	return k == kindInteger || k == kindUUID || k == kindString
+}
+
+// compatible reports whether a column of kind child can refer to a key of
+// kind parent.
+func compatible(child, parent kind) bool {
+	return child == parent && child.key()
+}
+
+// contained reports whether every value of c is one of pk's.
+func contained(c, pk *column) bool {
+	keys := make(map[string]bool, len(pk.values))
+	for _, v := range pk.values {
+		keys[canonical(v, pk.kind)] = true
+	}
+	for _, v := range c.values {
+		if !keys[canonical(v, c.kind)] {
+			return false
+		}
+	}
+	return true
+}
+
+// distinct reports whether no value of c appears twice.
+func distinct(c *column) bool {
+	seen := make(map[string]bool, len(c.values))
+	for _, v := range c.values {
+		v = canonical(v, c.kind)
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// canonical returns the form of v that compares equal to the same value
+// written differently, such as +7 and 7, or a UUID in upper case.
+func canonical(v string, k kind) string {
+	switch k {
+	case kindInteger:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case kindUUID:
+		return strings.ToLower(v)
+	}
+	return v
+}
+
+// refersTo reports whether a column name mentions table t, as customer_id
+// and customer do for customers.
+func refersTo(name string, t *table) bool {
+	for _, word := range []string{t.name, singular(t.name)} {
+		if name == word || strings.HasPrefix(name, word+"_") || strings.HasSuffix(name, "_"+word) || strings.Contains(name, "_"+word+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// keyName reports whether a column name looks like a key's.
+func keyName(name string) bool {
+	if name == "id" || name == "code" {
+		return true
+	}
+	for _, suffix := range []string{"_id", "_key", "_ref", "_code"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// singular returns the English singular of a table name, as far as a
+// naming convention goes: categories becomes category, orders order.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
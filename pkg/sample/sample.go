@@ -0,0 +1,490 @@
+// Package sample infers schemas from sample data, so that a small export
+// of real (or anonymized) rows can be turned into a schema that generates
+// more rows like them.
+//
+// Each CSV file becomes a table. Column types are inferred from the values,
+// primary keys from columns whose values are all present and distinct, and
+// foreign keys from columns whose values all appear in another table's
+// primary key. Generators are fitted to what was observed: numeric columns
+// get their range and a normal or lognormal distribution, columns with few
+// distinct values get a weighted list of them, date columns get their date
+// range, and nullable columns their null rate.
+//
+// Example usage:
+//
+//	s, warnings, err := sample.FromCSV([]sample.File{{Name: "customers.csv", Data: data}}, sample.Options{})
+//	if err != nil {
+//	    return err
+//	}
+package sample
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// File is one CSV file to import. Its first row names the columns.
+type File struct {
+	// Name identifies the file in diagnostics, usually its path. The table
+	// is named after its base name, without the extension.
+	Name string
+	Data []byte
+}
+
+// Options configures FromCSV.
+type Options struct {
+	// Name is the schema name. Defaults to "imported".
+	Name string
+
+	// Comma is the field delimiter. Zero means a tab for .tsv files and a
+	// comma for anything else.
+	Comma rune
+}
+
+// nullValues are the cell values read as NULL, besides the empty string.
+var nullValues = map[string]bool{"NULL": true, "null": true, `\N`: true}
+
+// FromCSV builds a schema from CSV files, one table per file, with each
+// table's record_count set to its number of rows so that seed keeps the
+// tables' proportions.
+//
+// Types are chosen from the values: boolean, int or bigint, decimal(p,s)
+// or double, date, datetime, timestamp (for times with a zone), char(36)
+// for UUIDs, json, and varchar(255) or text for anything else. Empty cells
+// and NULL, null and \N are missing values.
+//
+// The schema is not validated. Anything FromCSV had to guess or add, such
+// as a primary key for a table without one, is reported as a warning
+// located in the file. A file that is not valid CSV returns an
+// *schema.Error.
+func FromCSV(files []File, opts Options) (*schema.Schema, schema.ErrorList, error) {
+	im := &importer{}
+	used := make(map[string]bool)
+	for _, f := range files {
+		t, err := im.read(f, opts.Comma)
+		if err != nil {
+			return nil, nil, err
+		}
+		name := t.name
+		for i := 2; used[t.name]; i++ {
+			t.name = fmt.Sprintf("%s_%d", name, i)
+		}
+		if t.name != name {
+			im.warn(t.at, "table '%s' already exists; this file's table is named '%s'", name, t.name)
+		}
+		used[t.name] = true
+		im.tables = append(im.tables, t)
+	}
+
+	for _, t := range im.tables {
+		im.primaryKey(t)
+	}
+	for _, t := range im.tables {
+		im.foreignKeys(t)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "imported"
+	}
+	s := &schema.Schema{
+		SchemaVersion: "1.0",
+		Name:          name,
+		Version:       "1.0.0",
+		DatabaseType:  append([]string{}, dialect.Names...),
+		Tables:        []schema.Table{},
+	}
+	for _, t := range im.tables {
+		table := im.table(t)
+		s.Tables = append(s.Tables, table)
+		s.Metadata.TotalRecords += table.RecordCount
+	}
+	if order, err := schema.DependencyOrder(s.Tables); err == nil {
+		s.GenerationOrder = order
+	}
+	return s, im.warnings, nil
+}
+
+// importer accumulates tables across files.
+type importer struct {
+	tables   []*table
+	warnings schema.ErrorList
+}
+
+// table is one file's data, profiled.
+type table struct {
+	name    string
+	at      position
+	rows    int
+	columns []*column
+
+	// pk is the primary key column; synthetic when the data has none
+	pk        *column
+	synthetic bool
+}
+
+// column is one CSV column, profiled.
+type column struct {
+	name   string
+	at     position
+	values []string // the non-null values, in row order
+	nulls  int
+	kind   kind
+
+	// ref is the primary key this column's values were all found in
+	ref *table
+}
+
+// position locates a header cell in a file.
+type position struct {
+	file      string
+	line, col int
+	source    string
+}
+
+// warn records something FromCSV guessed or changed.
+func (im *importer) warn(at position, format string, args ...interface{}) {
+	im.warnings = append(im.warnings, &schema.Error{
+		Severity: schema.SeverityWarning,
+		File:     at.file,
+		Line:     at.line,
+		Column:   at.col,
+		Source:   at.source,
+		Err:      fmt.Errorf(format, args...),
+	})
+}
+
+// read parses and profiles one file.
+func (im *importer) read(f File, comma rune) (*table, error) {
+	data := bytes.TrimPrefix(f.Data, []byte("\xef\xbb\xbf"))
+	lines := strings.Split(string(data), "\n")
+	at := func(line, col int) position {
+		pos := position{file: f.Name, line: line, col: col}
+		if line >= 1 && line <= len(lines) {
+			pos.source = strings.TrimRight(lines[line-1], "\r")
+		}
+		return pos
+	}
+
+	if comma == 0 {
+		comma = ','
+		if strings.EqualFold(filepath.Ext(f.Name), ".tsv") {
+			comma = '\t'
+		}
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &schema.Error{Severity: schema.SeverityError, File: f.Name, Err: fmt.Errorf("no header row")}
+	}
+	if err != nil {
+		return nil, located(err, at)
+	}
+
+	base := filepath.Base(f.Name)
+	t := &table{name: identifier(strings.TrimSuffix(base, filepath.Ext(base))), at: at(1, 1)}
+	if t.name == "" {
+		t.name = "data"
+	}
+	used := make(map[string]bool)
+	for i, h := range header {
+		line, col := r.FieldPos(i)
+		c := &column{name: identifier(h), at: at(line, col)}
+		switch {
+		case c.name == "":
+			c.name = fmt.Sprintf("column_%d", i+1)
+			im.warn(c.at, "table '%s': column %d has no name; named '%s'", t.name, i+1, c.name)
+		case used[c.name]:
+			name := c.name
+			for n := 2; used[c.name]; n++ {
+				c.name = fmt.Sprintf("%s_%d", name, n)
+			}
+			im.warn(c.at, "table '%s': column '%s' appears more than once; named '%s'", t.name, name, c.name)
+		}
+		used[c.name] = true
+		t.columns = append(t.columns, c)
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, located(err, at)
+		}
+		t.rows++
+		for i, v := range record {
+			c := t.columns[i]
+			if v == "" || nullValues[v] {
+				c.nulls++
+				continue
+			}
+			c.values = append(c.values, v)
+		}
+	}
+	if t.rows == 0 {
+		im.warn(t.at, "table '%s' has no rows; column types could not be inferred", t.name)
+	}
+
+	for _, c := range t.columns {
+		c.kind = kindOf(c.values)
+		if len(c.values) == 0 && t.rows > 0 {
+			im.warn(c.at, "table '%s': column '%s' is empty in every row; imported as text", t.name, c.name)
+		}
+	}
+	return t, nil
+}
+
+// located turns a CSV parse error into an *schema.Error pointing into the
+// file.
+func located(err error, at func(line, col int) position) error {
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		return err
+	}
+	pos := at(pe.Line, pe.Column)
+	return &schema.Error{
+		Severity: schema.SeverityError,
+		File:     pos.file,
+		Line:     pos.line,
+		Column:   pos.col,
+		Source:   pos.source,
+		Err:      pe.Err,
+	}
+}
+
+// identifier turns a file or header name into a snake_case identifier:
+// "First Name" and "firstName" both become first_name. A leading digit
+// gets an underscore in front.
+func identifier(s string) string {
+	var b strings.Builder
+	var prev rune
+	pendingSep := false
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				pendingSep = true
+			}
+			if pendingSep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pendingSep = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingSep = true
+		}
+		prev = r
+	}
+	name := b.String()
+	if r, _ := utf8.DecodeRuneInString(name); unicode.IsDigit(r) {
+		name = "_" + name
+	}
+	return name
+}
+
+// table builds the schema table for t.
+func (im *importer) table(t *table) schema.Table {
+	out := schema.Table{Name: t.name, RecordCount: t.rows}
+	if out.RecordCount == 0 {
+		out.RecordCount = 1
+	}
+	if t.synthetic {
+		out.Columns = append(out.Columns, schema.Column{Name: t.pk.name, Type: "int", PrimaryKey: true, AutoIncrement: true})
+	}
+	for _, c := range t.columns {
+		out.Columns = append(out.Columns, im.column(t, c))
+	}
+	return out
+}
+
+// column builds the schema column for c: its type, key and generator.
+func (im *importer) column(t *table, c *column) schema.Column {
+	col := schema.Column{Name: c.name, Type: c.kind.dataType(c.values)}
+
+	switch {
+	case c == t.pk:
+		col.PrimaryKey = true
+		if c.kind == kindUUID {
+			col.Generator = "uuid"
+		}
+		return col
+	case c.ref != nil:
+		parent := c.ref.pk
+		col.Type = parent.kind.dataType(parent.values)
+		col.ForeignKey = &schema.ForeignKey{Table: c.ref.name, Column: parent.name, OnDelete: "RESTRICT", OnUpdate: "RESTRICT"}
+	default:
+		col.Generator, col.GeneratorParams = fit(&col, c)
+	}
+
+	if c.nulls > 0 {
+		col.Nullable = true
+		if col.GeneratorParams == nil {
+			col.GeneratorParams = make(map[string]interface{})
+		}
+		col.GeneratorParams["null_rate"] = round(float64(c.nulls)/float64(c.nulls+len(c.values)), 4)
+	}
+	return col
+}
+
+// kind is the type inferred for a column's values.
+type kind int
+
+const (
+	kindEmpty kind = iota // no values at all
+	kindBoolean
+	kindInteger
+	kindNumber
+	kindDate
+	kindDatetime  // a date and time without a zone
+	kindTimestamp // a date and time with a zone
+	kindUUID
+	kindJSON
+	kindString
+)
+
+// kindOf returns the narrowest kind every value fits.
+func kindOf(values []string) kind {
+	if len(values) == 0 {
+		return kindEmpty
+	}
+	for _, k := range []kind{kindBoolean, kindInteger, kindNumber, kindDate, kindDatetime, kindTimestamp, kindUUID, kindJSON} {
+		if all(values, k.matches) {
+			return k
+		}
+	}
+	return kindString
+}
+
+func all(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if !match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// booleans maps the spellings read as booleans to their value.
+var booleans = map[string]bool{
+	"true": true, "false": false, "t": true, "f": false,
+	"yes": true, "no": false, "y": true, "n": false,
+}
+
+// Layouts of the dates and times recognized, without and with a zone.
+// Fractional seconds are optional in each.
+var (
+	dateLayouts     = []string{"2006-01-02", "2006/01/02"}
+	datetimeLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04", "2006-01-02T15:04"}
+	zonedLayouts    = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999Z07"}
+)
+
+func (k kind) matches(v string) bool {
+	switch k {
+	case kindBoolean:
+		_, ok := booleans[strings.ToLower(v)]
+		return ok
+	case kindInteger:
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil && !leadingZero(v)
+	case kindNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		return err == nil && !leadingZero(v) && !math.IsInf(f, 0) && !math.IsNaN(f) && !strings.ContainsAny(v, "xXpP_")
+	case kindDate:
+		_, ok := parseTime(v, dateLayouts)
+		return ok
+	case kindDatetime:
+		_, ok := parseTime(v, datetimeLayouts)
+		return ok
+	case kindTimestamp:
+		_, ok := parseTime(v, zonedLayouts)
+		return ok
+	case kindUUID:
+		return uuidPattern.MatchString(v)
+	case kindJSON:
+		v = strings.TrimSpace(v)
+		return (strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[")) && jsonValid(v)
+	}
+	return true
+}
+
+// leadingZero reports whether v's whole part starts with a zero, as codes
+// such as ZIP codes do; those stay text so that the zeros survive.
+func leadingZero(v string) bool {
+	whole, _, _ := strings.Cut(strings.TrimLeft(v, "+-"), ".")
+	return len(whole) > 1 && whole[0] == '0'
+}
+
+// dataType returns the column type for values of kind k.
+func (k kind) dataType(values []string) string {
+	switch k {
+	case kindBoolean:
+		return "boolean"
+	case kindInteger:
+		for _, v := range values {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			if n < -1<<31 || n > 1<<31-1 {
+				return "bigint"
+			}
+		}
+		return "int"
+	case kindNumber:
+		return numberType(values)
+	case kindDate:
+		return "date"
+	case kindDatetime:
+		return "datetime"
+	case kindTimestamp:
+		return "timestamp"
+	case kindUUID:
+		return "char(36)"
+	case kindJSON:
+		return "json"
+	case kindString:
+		for _, v := range values {
+			if utf8.RuneCountInString(v) > 255 {
+				return "text"
+			}
+		}
+		return "varchar(255)"
+	}
+	return "text"
+}
+
+// numberType returns decimal(p,s) wide enough for every value, or double
+// for values in exponent notation or beyond decimal's precision.
+func numberType(values []string) string {
+	intDigits, scale := 1, 0
+	for _, v := range values {
+		if strings.ContainsAny(v, "eE") {
+			return "double"
+		}
+		whole, frac, _ := strings.Cut(strings.TrimLeft(v, "+-"), ".")
+		if n := len(strings.TrimLeft(whole, "0")); n > intDigits {
+			intDigits = n
+		}
+		if len(frac) > scale {
+			scale = len(frac)
+		}
+	}
+	if intDigits+scale > 38 || scale > 30 {
+		return "double"
+	}
+	return fmt.Sprintf("decimal(%d,%d)", intDigits+scale, scale)
+}
@@ -0,0 +1,196 @@
+package sample
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customersCSV = `id,First Name,last_name,email,signup_date,tier,is_active,credit_limit,zip
+1,Ann,Lee,ann@example.com,2023-01-05,gold,true,1500.00,02134
+2,Bob,Ng,bob@example.com,2023-02-11,basic,false,800.50,10001
+3,Cy,Ott,cy@example.com,2023-03-20,basic,true,12000.00,
+4,Di,Poe,di@example.com,2023-04-01,silver,true,950.25,94105
+5,Ed,Qi,ed@example.com,2023-05-15,basic,true,700.00,NULL
+6,Flo,Ray,flo@example.com,2023-06-30,gold,false,2200.00,60601
+`
+
+const ordersCSV = `order_id,customer_id,placed_at,total,status,quantity,coupon
+1001,1,2024-01-01 10:00:00,19.99,shipped,1,
+1002,2,2024-01-02 11:30:00,250.00,pending,3,SAVE10
+1003,1,2024-01-03 09:15:00,35.50,shipped,2,
+1004,3,2024-01-04 14:45:00,12.00,cancelled,1,
+1005,5,2024-01-05 16:20:00,99.90,shipped,5,SAVE10
+1006,6,2024-01-06 08:05:00,18.75,shipped,1,
+1007,2,2024-01-07 19:40:00,640.00,pending,2,
+1008,4,2024-01-08 12:00:00,22.10,shipped,1,
+`
+
+func fromCSV(t *testing.T, files ...File) (*schema.Schema, schema.ErrorList) {
+	t.Helper()
+	s, warnings, err := FromCSV(files, Options{})
+	require.NoError(t, err)
+	require.NoError(t, schema.ValidateSchema(s), "inferred schemas should validate")
+	return s, warnings
+}
+
+func TestFromCSV(t *testing.T) {
+	s, warnings := fromCSV(t,
+		File{Name: "export/orders.csv", Data: []byte(ordersCSV)},
+		File{Name: "export/customers.csv", Data: []byte(customersCSV)},
+	)
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, "imported", s.Name)
+	assert.Equal(t, []string{"mysql", "postgres"}, s.DatabaseType)
+	assert.Equal(t, []string{"customers", "orders"}, s.GenerationOrder, "parents come first, whatever the file order")
+	assert.Equal(t, 14, s.Metadata.TotalRecords)
+
+	customers := s.Table("customers")
+	require.NotNil(t, customers)
+	assert.Equal(t, 6, customers.RecordCount)
+	assert.Equal(t, schema.Column{Name: "id", Type: "int", PrimaryKey: true}, *customers.Column("id"))
+	assert.Equal(t, "first_name", customers.Column("first_name").Generator, "headers become snake_case names")
+	assert.Equal(t, "email", customers.Column("email").Generator)
+	assert.Equal(t, schema.Column{Name: "signup_date", Type: "date", Generator: "date_between", GeneratorParams: map[string]interface{}{
+		"start_date": "2023-01-05", "end_date": "2023-06-30",
+	}}, *customers.Column("signup_date"))
+	assert.Equal(t, schema.Column{Name: "tier", Type: "varchar(255)", Generator: "weighted", GeneratorParams: map[string]interface{}{
+		"values": []interface{}{
+			map[string]interface{}{"value": "basic", "weight": 0.5},
+			map[string]interface{}{"value": "gold", "weight": 0.3333},
+			map[string]interface{}{"value": "silver", "weight": 0.1667},
+		},
+	}}, *customers.Column("tier"))
+	assert.Equal(t, schema.Column{Name: "is_active", Type: "boolean", Generator: "boolean", GeneratorParams: map[string]interface{}{
+		"true_rate": 0.6667,
+	}}, *customers.Column("is_active"))
+	assert.Equal(t, "decimal(7,2)", customers.Column("credit_limit").Type)
+	assert.Equal(t, schema.Column{Name: "zip", Type: "varchar(255)", Nullable: true, GeneratorParams: map[string]interface{}{
+		"null_rate": 0.3333,
+	}}, *customers.Column("zip"), "leading zeros keep codes as text")
+
+	orders := s.Table("orders")
+	require.NotNil(t, orders)
+	assert.True(t, orders.Column("order_id").PrimaryKey)
+	assert.Equal(t, schema.Column{Name: "customer_id", Type: "int", ForeignKey: &schema.ForeignKey{
+		Table: "customers", Column: "id", OnDelete: "RESTRICT", OnUpdate: "RESTRICT",
+	}}, *orders.Column("customer_id"))
+	assert.Equal(t, "datetime", orders.Column("placed_at").Type)
+	assert.Equal(t, "int", orders.Column("quantity").Type)
+	assert.Equal(t, "weighted", orders.Column("quantity").Generator)
+	assert.Equal(t, map[string]interface{}{"value": 1.0, "weight": 0.5}, orders.Column("quantity").GeneratorParams["values"].([]interface{})[0])
+	assert.Equal(t, 0.75, orders.Column("coupon").GeneratorParams["null_rate"])
+}
+
+func TestFromCSV_Keys(t *testing.T) {
+	people := File{Name: "people.tsv", Data: []byte("id\tmanagerId\tteam_code\n1\t\tA\n2\t1\tB\n3\t1\tA\n")}
+	teams := File{Name: "teams.csv", Data: []byte("code,label\nA,Alpha\nB,Beta\nC,Gamma\n")}
+	scores := File{Name: "scores.csv", Data: []byte("player,points\nann,3\nbob,3\n")}
+
+	s, warnings := fromCSV(t, people, teams, scores)
+
+	manager := s.Table("people").Column("manager_id")
+	require.NotNil(t, manager, "tabs separate .tsv files and camelCase becomes snake_case")
+	assert.Equal(t, &schema.ForeignKey{Table: "people", Column: "id", OnDelete: "RESTRICT", OnUpdate: "RESTRICT"}, manager.ForeignKey, "a nullable column can refer to its own table")
+	assert.Equal(t, "teams", s.Table("people").Column("team_code").ForeignKey.Table)
+	assert.True(t, s.Table("teams").Column("code").PrimaryKey, "a string column named like a key")
+
+	scoresTable := s.Table("scores")
+	assert.Equal(t, schema.Column{Name: "id", Type: "int", PrimaryKey: true, AutoIncrement: true}, scoresTable.Columns[0])
+	assert.Equal(t, "player", scoresTable.Columns[1].Name)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "scores.csv", warnings[0].File)
+	assert.Contains(t, warnings[0].Error(), "table 'scores' has no column with a distinct value in every row; added primary key 'id'")
+}
+
+func TestFromCSV_AmbiguousReference(t *testing.T) {
+	a := File{Name: "a.csv", Data: []byte("id\n1\n2\n")}
+	b := File{Name: "b.csv", Data: []byte("id\n1\n2\n3\n")}
+	c := File{Name: "c.csv", Data: []byte("id,owner_id\n1,1\n2,2\n")}
+
+	s, warnings := fromCSV(t, a, b, c)
+	assert.Nil(t, s.Table("c").Column("owner_id").ForeignKey)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, 1, warnings[0].Line)
+	assert.Equal(t, 4, warnings[0].Column)
+	assert.Contains(t, warnings[0].Error(), "column 'owner_id' could refer to a.id or b.id")
+}
+
+func TestFromCSV_Names(t *testing.T) {
+	s, warnings := fromCSV(t,
+		File{Name: "data.csv", Data: []byte("id,Name,name,,2nd\n1,a,b,c,d\n")},
+		File{Name: "other/data.csv", Data: []byte("id\n1\n")},
+	)
+	names := make([]string, 0)
+	for _, c := range s.Tables[0].Columns {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"id", "name", "name_2", "column_4", "_2nd"}, names)
+	assert.Equal(t, "data_2", s.Tables[1].Name)
+	assert.Len(t, warnings, 3)
+}
+
+func TestFromCSV_Errors(t *testing.T) {
+	_, _, err := FromCSV([]File{{Name: "bad.csv", Data: []byte("a,b\n1,2\n3,\"x\n")}}, Options{})
+	require.Error(t, err)
+	var located *schema.Error
+	require.True(t, errors.As(err, &located))
+	assert.Equal(t, "bad.csv", located.File)
+	assert.Equal(t, 3, located.Line)
+	assert.Contains(t, located.Error(), `extraneous or missing " in quoted-field`)
+
+	_, _, err = FromCSV([]File{{Name: "short.csv", Data: []byte("a,b\n1\n")}}, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wrong number of fields")
+
+	_, _, err = FromCSV([]File{{Name: "empty.csv"}}, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no header row")
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		values   []string
+		kind     kind
+		dataType string
+	}{
+		{nil, kindEmpty, "text"},
+		{[]string{"true", "F", "yes"}, kindBoolean, "boolean"},
+		{[]string{"1", "-20", "+3"}, kindInteger, "int"},
+		{[]string{"1", "3000000000"}, kindInteger, "bigint"},
+		{[]string{"1", "2.5", "-100.125"}, kindNumber, "decimal(6,3)"},
+		{[]string{"1e10", "2.5"}, kindNumber, "double"},
+		{[]string{"007", "123"}, kindString, "varchar(255)"},
+		{[]string{"NaN"}, kindString, "varchar(255)"},
+		{[]string{"2024-02-29", "2024/03/01"}, kindDate, "date"},
+		{[]string{"2024-02-29 10:00", "2024-03-01T09:30:15.250"}, kindDatetime, "datetime"},
+		{[]string{"2024-02-29T10:00:00Z", "2024-03-01 09:30:15+02:00"}, kindTimestamp, "timestamp"},
+		{[]string{"8C5B8A2E-3F1D-4B7A-9C61-2D4E6F8A0B1C"}, kindUUID, "char(36)"},
+		{[]string{`{"a": 1}`, "[1, 2]"}, kindJSON, "json"},
+		{[]string{"{not json"}, kindString, "varchar(255)"},
+	}
+	for _, tt := range tests {
+		k := kindOf(tt.values)
+		assert.Equal(t, tt.kind, k, "%q", tt.values)
+		assert.Equal(t, tt.dataType, k.dataType(tt.values), "%q", tt.values)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	for in, want := range map[string]string{
+		"First Name":    "first_name",
+		"firstName":     "first_name",
+		"userID":        "user_id",
+		" Total ($) ":   "total",
+		"2024 revenue":  "_2024_revenue",
+		"order-items":   "order_items",
+		"ALREADY_SNAKE": "already_snake",
+		"---":           "",
+	} {
+		assert.Equal(t, want, identifier(in), in)
+	}
+}
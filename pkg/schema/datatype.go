@@ -0,0 +1,391 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DataType is a parsed column type. The grammar is
+//
+//	type := base [ "(" args ")" ] [ "unsigned" ] [ "[]" ]
+//
+// where base is one of the supported types (case-insensitive) and args
+// depend on it: a length for varchar(255), char(36) and bit(8), precision
+// and scale for decimal(10,2), a precision for float(24), fractional-second
+// digits for datetime(3) and timestamp(6), a display width for int(11), and
+// quoted values for enum('a','b'). A trailing "[]" declares a PostgreSQL
+// array of that type.
+type DataType struct {
+	// Base is the lowercased base type name, such as "varchar".
+	Base string
+
+	// Length is the declared length of char, varchar and bit types, or the
+	// display width of integer types; 0 when not declared.
+	Length int
+
+	// Precision is the total number of digits of decimal and float types,
+	// or the fractional-second digits of datetime and timestamp; 0 when not
+	// declared.
+	Precision int
+
+	// Scale is the number of decimal places of a decimal type.
+	Scale int
+
+	// Unsigned is set for MySQL unsigned numeric types.
+	Unsigned bool
+
+	// Values holds the unquoted values of an enum type.
+	Values []string
+
+	// Array is set for PostgreSQL arrays, such as int[].
+	Array bool
+}
+
+// baseTypes maps every supported base type to its family (see TypeFamily).
+// Defined in F007.
+var baseTypes = map[string]string{
+	// Integer types
+	"int": FamilyInteger, "bigint": FamilyInteger, "smallint": FamilyInteger, "tinyint": FamilyInteger,
+	// Decimal types
+	"decimal": FamilyNumber, "float": FamilyNumber, "double": FamilyNumber,
+	// String types
+	"varchar": FamilyString, "text": FamilyString, "char": FamilyString,
+	// Date/Time types
+	"date": FamilyDate, "datetime": FamilyDatetime, "timestamp": FamilyDatetime,
+	// Boolean types
+	"boolean": FamilyBoolean, "bit": FamilyBoolean,
+	// JSON types
+	"json": FamilyJSON, "jsonb": FamilyJSON,
+	// Enum type
+	"enum": FamilyEnum,
+}
+
+// ParseDataType parses a column type such as "VARCHAR(255)" or
+// "decimal(10, 2) unsigned". It checks the syntax and the limits every
+// database shares (a length of at least 1, a scale no larger than the
+// precision); ValidateFor checks the limits of one database.
+func ParseDataType(dataType string) (DataType, error) {
+	fail := func(format string, args ...interface{}) (DataType, error) {
+		return DataType{}, fmt.Errorf("invalid data type %q: %s", dataType, fmt.Sprintf(format, args...))
+	}
+
+	s := strings.TrimSpace(dataType)
+	if s == "" {
+		return DataType{}, fmt.Errorf("invalid data type: column type is required")
+	}
+
+	var t DataType
+	if strings.HasSuffix(s, "[]") {
+		t.Array = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "[]"))
+	}
+
+	// Base name
+	i := 0
+	for i < len(s) && isLetter(s[i]) {
+		i++
+	}
+	t.Base = strings.ToLower(s[:i])
+	rest := s[i:]
+	family, known := baseTypes[t.Base]
+	if !known || (rest != "" && !strings.ContainsAny(rest[:1], " \t(")) {
+		return fail("type not supported")
+	}
+
+	// Argument list
+	rest = strings.TrimLeft(rest, " \t")
+	var args []string
+	hasArgs := strings.HasPrefix(rest, "(")
+	if hasArgs {
+		var n int
+		var err error
+		args, n, err = splitArgs(rest)
+		if err != nil {
+			return fail("%v", err)
+		}
+		rest = strings.TrimLeft(rest[n:], " \t")
+	}
+
+	// Modifiers
+	if strings.EqualFold(rest, "unsigned") {
+		t.Unsigned = true
+		rest = ""
+	}
+	if rest != "" {
+		return fail("unexpected %q after the type", rest)
+	}
+	if t.Unsigned && family != FamilyInteger && family != FamilyNumber {
+		return fail("unsigned applies only to numeric types")
+	}
+	if t.Array && t.Base == "enum" {
+		return fail("arrays of enum are not supported")
+	}
+
+	if err := t.setArgs(args, hasArgs); err != nil {
+		return fail("%v", err)
+	}
+	return t, nil
+}
+
+// setArgs interprets the argument list according to the base type.
+func (t *DataType) setArgs(args []string, hasArgs bool) error {
+	if hasArgs && len(args) == 0 {
+		return fmt.Errorf("empty argument list")
+	}
+
+	// ints parses the arguments as non-negative integers, allowing at most max of them
+	ints := func(max int) ([]int, error) {
+		if len(args) > max {
+			return nil, fmt.Errorf("%s takes at most %d argument(s), got %d", t.Base, max, len(args))
+		}
+		out := make([]int, len(args))
+		for i, a := range args {
+			n, err := strconv.Atoi(strings.TrimSpace(a))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s argument %q must be a non-negative integer", t.Base, strings.TrimSpace(a))
+			}
+			out[i] = n
+		}
+		return out, nil
+	}
+
+	switch t.Base {
+	case "int", "bigint", "smallint", "tinyint":
+		n, err := ints(1)
+		if err != nil {
+			return err
+		}
+		if len(n) == 1 {
+			if n[0] < 1 || n[0] > 255 {
+				return fmt.Errorf("display width must be between 1 and 255")
+			}
+			t.Length = n[0]
+		}
+
+	case "decimal":
+		n, err := ints(2)
+		if err != nil {
+			return err
+		}
+		if len(n) > 0 {
+			t.Precision = n[0]
+			if t.Precision < 1 {
+				return fmt.Errorf("precision must be at least 1")
+			}
+		}
+		if len(n) > 1 {
+			t.Scale = n[1]
+			if t.Scale > t.Precision {
+				return fmt.Errorf("scale (%d) must not be greater than precision (%d)", t.Scale, t.Precision)
+			}
+		}
+
+	case "float":
+		n, err := ints(1)
+		if err != nil {
+			return err
+		}
+		if len(n) == 1 {
+			if n[0] < 1 || n[0] > 53 {
+				return fmt.Errorf("float precision must be between 1 and 53")
+			}
+			t.Precision = n[0]
+		}
+
+	case "varchar", "char", "bit":
+		n, err := ints(1)
+		if err != nil {
+			return err
+		}
+		if len(n) == 1 {
+			if n[0] < 1 {
+				return fmt.Errorf("length must be at least 1")
+			}
+			if t.Base == "bit" && n[0] > 64 {
+				return fmt.Errorf("bit length must be between 1 and 64")
+			}
+			t.Length = n[0]
+		}
+
+	case "datetime", "timestamp":
+		n, err := ints(1)
+		if err != nil {
+			return err
+		}
+		if len(n) == 1 {
+			if n[0] > 6 {
+				return fmt.Errorf("fractional seconds precision must be between 0 and 6")
+			}
+			t.Precision = n[0]
+		}
+
+	case "enum":
+		if len(args) == 0 {
+			return fmt.Errorf("enum requires at least one value")
+		}
+		seen := make(map[string]bool, len(args))
+		for _, a := range args {
+			a = strings.TrimSpace(a)
+			if len(a) < 2 || a[0] != '\'' || a[len(a)-1] != '\'' {
+				return fmt.Errorf("enum value %s must be a single-quoted string", a)
+			}
+			v := strings.ReplaceAll(a[1:len(a)-1], "''", "'")
+			if seen[v] {
+				return fmt.Errorf("duplicate enum value '%s'", v)
+			}
+			seen[v] = true
+			t.Values = append(t.Values, v)
+		}
+
+	default:
+		if hasArgs {
+			return fmt.Errorf("%s takes no arguments", t.Base)
+		}
+	}
+	return nil
+}
+
+// splitArgs splits the parenthesised argument list at the start of s,
+// ignoring commas and parentheses inside single-quoted strings, where a
+// doubled quote is an escaped one. It returns the arguments and the number
+// of bytes consumed, including both parentheses.
+func splitArgs(s string) ([]string, int, error) {
+	var args []string
+	var cur strings.Builder
+	quoted := false
+
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted && c == '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				cur.WriteString("''")
+				i++
+				continue
+			}
+			quoted = false
+		case quoted:
+		case c == '\'':
+			quoted = true
+		case c == ',':
+			args = append(args, cur.String())
+			cur.Reset()
+			continue
+		case c == ')':
+			if arg := cur.String(); len(args) > 0 || strings.TrimSpace(arg) != "" {
+				args = append(args, arg)
+			}
+			for _, a := range args {
+				if strings.TrimSpace(a) == "" {
+					return nil, 0, fmt.Errorf("empty argument")
+				}
+			}
+			return args, i + 1, nil
+		case c == '(':
+			return nil, 0, fmt.Errorf("unexpected '(' in argument list")
+		}
+		cur.WriteByte(c)
+	}
+
+	if quoted {
+		return nil, 0, fmt.Errorf("unterminated string in argument list")
+	}
+	return nil, 0, fmt.Errorf("missing ')'")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Family returns the type's family, such as "integer" or "string". For an
+// array it is the family of the elements.
+func (t DataType) Family() string {
+	return baseTypes[t.Base]
+}
+
+// String renders the type in canonical form: lowercase, without spaces
+// between arguments, for example "decimal(10,2) unsigned" or "int[]".
+func (t DataType) String() string {
+	s := t.Base
+	switch {
+	case t.Base == "enum":
+		quoted := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		s += "(" + strings.Join(quoted, ",") + ")"
+	case t.Base == "decimal" && t.Precision > 0:
+		s += fmt.Sprintf("(%d,%d)", t.Precision, t.Scale)
+	case t.Precision > 0:
+		s += fmt.Sprintf("(%d)", t.Precision)
+	case t.Length > 0:
+		s += fmt.Sprintf("(%d)", t.Length)
+	}
+	if t.Unsigned {
+		s += " unsigned"
+	}
+	if t.Array {
+		s += "[]"
+	}
+	return s
+}
+
+// Dialect limits on declared sizes.
+const (
+	mysqlMaxVarchar   = 65535
+	mysqlMaxChar      = 255
+	mysqlMaxPrecision = 65
+	mysqlMaxScale     = 30
+	postgresMaxLength = 10485760
+	postgresMaxDigits = 1000
+)
+
+// ValidateFor checks the type against the limits and features of one
+// database ("mysql" or "postgres"): declared lengths and precision, and
+// that unsigned types are only used with MySQL and arrays only with
+// PostgreSQL. Returns nil for an unknown database.
+func (t DataType) ValidateFor(database string) error {
+	switch database {
+	case "mysql":
+		switch {
+		case t.Array:
+			return fmt.Errorf("array types are not supported by mysql")
+		case t.Base == "varchar" && t.Length == 0:
+			return fmt.Errorf("varchar requires a length in mysql, such as varchar(255)")
+		case t.Base == "varchar" && t.Length > mysqlMaxVarchar:
+			return fmt.Errorf("varchar length %d exceeds the mysql maximum of %d", t.Length, mysqlMaxVarchar)
+		case t.Base == "char" && t.Length > mysqlMaxChar:
+			return fmt.Errorf("char length %d exceeds the mysql maximum of %d", t.Length, mysqlMaxChar)
+		case t.Base == "decimal" && t.Precision > mysqlMaxPrecision:
+			return fmt.Errorf("decimal precision %d exceeds the mysql maximum of %d", t.Precision, mysqlMaxPrecision)
+		case t.Base == "decimal" && t.Scale > mysqlMaxScale:
+			return fmt.Errorf("decimal scale %d exceeds the mysql maximum of %d", t.Scale, mysqlMaxScale)
+		}
+	case "postgres":
+		switch {
+		case t.Unsigned:
+			return fmt.Errorf("unsigned types are not supported by postgres")
+		case (t.Base == "varchar" || t.Base == "char") && t.Length > postgresMaxLength:
+			return fmt.Errorf("%s length %d exceeds the postgres maximum of %d", t.Base, t.Length, postgresMaxLength)
+		case t.Base == "decimal" && t.Precision > postgresMaxDigits:
+			return fmt.Errorf("decimal precision %d exceeds the postgres maximum of %d", t.Precision, postgresMaxDigits)
+		case t.Base == "bit" && t.Length > 1:
+			// bit(n) maps to BIT(n), which takes bit strings rather than the
+			// booleans generated for the bit family
+			return fmt.Errorf("bit(%d) is not supported by postgres: use bit or boolean", t.Length)
+		}
+	}
+	return nil
+}
+
+// DataType returns the column's parsed type. The type is expected to have
+// passed validation; an invalid one yields a DataType holding only its
+// lowercased text as Base.
+func (c *Column) DataType() DataType {
+	t, err := ParseDataType(c.Type)
+	if err != nil {
+		return DataType{Base: strings.ToLower(strings.TrimSpace(c.Type))}
+	}
+	return t
+}
@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDataType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  DataType
+		str   string
+	}{
+		{"int", DataType{Base: "int"}, "int"},
+		{"INT(11) UNSIGNED", DataType{Base: "int", Length: 11, Unsigned: true}, "int(11) unsigned"},
+		{"bigint unsigned", DataType{Base: "bigint", Unsigned: true}, "bigint unsigned"},
+		{"decimal(10, 2)", DataType{Base: "decimal", Precision: 10, Scale: 2}, "decimal(10,2)"},
+		{"decimal(5)", DataType{Base: "decimal", Precision: 5}, "decimal(5,0)"},
+		{"float(24)", DataType{Base: "float", Precision: 24}, "float(24)"},
+		{"VARCHAR(255)", DataType{Base: "varchar", Length: 255}, "varchar(255)"},
+		{"varchar", DataType{Base: "varchar"}, "varchar"},
+		{"datetime(3)", DataType{Base: "datetime", Precision: 3}, "datetime(3)"},
+		{"bit(8)", DataType{Base: "bit", Length: 8}, "bit(8)"},
+		{"jsonb", DataType{Base: "jsonb"}, "jsonb"},
+		{"int[]", DataType{Base: "int", Array: true}, "int[]"},
+		{"varchar(20) []", DataType{Base: "varchar", Length: 20, Array: true}, "varchar(20)[]"},
+		{"enum('a', 'b,c', 'it''s')", DataType{Base: "enum", Values: []string{"a", "b,c", "it's"}}, "enum('a','b,c','it''s')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDataType(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.str, got.String())
+		})
+	}
+}
+
+func TestParseDataTypeInvalid(t *testing.T) {
+	tests := []struct {
+		input    string
+		errorMsg string
+	}{
+		{"", "column type is required"},
+		{"integer_foo", "type not supported"},
+		{"textual", "type not supported"},
+		{"int4", "type not supported"},
+		{"varchar(abc)", "varchar argument \"abc\" must be a non-negative integer"},
+		{"varchar(0)", "length must be at least 1"},
+		{"varchar(10", "missing ')'"},
+		{"varchar(10,2)", "varchar takes at most 1 argument(s), got 2"},
+		{"varchar()", "empty argument list"},
+		{"enum(a,b", "missing ')'"},
+		{"enum(a,b)", "enum value a must be a single-quoted string"},
+		{"enum('a','b", "unterminated string"},
+		{"enum('a','a')", "duplicate enum value 'a'"},
+		{"enum('a')[]", "arrays of enum are not supported"},
+		{"decimal(5,6)", "scale (6) must not be greater than precision (5)"},
+		{"decimal(0)", "precision must be at least 1"},
+		{"decimal(10,2,1)", "decimal takes at most 2 argument(s), got 3"},
+		{"float(54)", "float precision must be between 1 and 53"},
+		{"int(0)", "display width must be between 1 and 255"},
+		{"bit(65)", "bit length must be between 1 and 64"},
+		{"timestamp(7)", "fractional seconds precision must be between 0 and 6"},
+		{"text(100)", "text takes no arguments"},
+		{"varchar unsigned", "unsigned applies only to numeric types"},
+		{"int signed", "unexpected \"signed\" after the type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseDataType(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+			assert.Error(t, ValidateDataType(tt.input))
+		})
+	}
+}
+
+func TestDataTypeValidateFor(t *testing.T) {
+	tests := []struct {
+		input    string
+		database string
+		errorMsg string
+	}{
+		{"varchar(255)", "mysql", ""},
+		{"varchar(255)", "postgres", ""},
+		{"varchar", "mysql", "varchar requires a length in mysql"},
+		{"varchar", "postgres", ""},
+		{"varchar(70000)", "mysql", "varchar length 70000 exceeds the mysql maximum of 65535"},
+		{"varchar(70000)", "postgres", ""},
+		{"char(300)", "mysql", "char length 300 exceeds the mysql maximum of 255"},
+		{"decimal(66,2)", "mysql", "decimal precision 66 exceeds the mysql maximum of 65"},
+		{"decimal(40,31)", "mysql", "decimal scale 31 exceeds the mysql maximum of 30"},
+		{"decimal(66,2)", "postgres", ""},
+		{"int unsigned", "mysql", ""},
+		{"int unsigned", "postgres", "unsigned types are not supported by postgres"},
+		{"int[]", "mysql", "array types are not supported by mysql"},
+		{"int[]", "postgres", ""},
+		{"bit(8)", "postgres", "bit(8) is not supported by postgres"},
+		{"bit(1)", "postgres", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" on "+tt.database, func(t *testing.T) {
+			dt, err := ParseDataType(tt.input)
+			require.NoError(t, err)
+
+			err = dt.ValidateFor(tt.database)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestValidate_DialectTypeLimits(t *testing.T) {
+	s := &Schema{
+		Name:         "limits",
+		DatabaseType: []string{"mysql", "postgres"},
+		Tables: []Table{{
+			Name:        "accounts",
+			RecordCount: 10,
+			Columns: []Column{
+				{Name: "id", Type: "bigint unsigned", PrimaryKey: true},
+				{Name: "code", Type: "varchar"},
+			},
+		}},
+	}
+
+	list := Validate(s)
+	require.Len(t, list, 2)
+	assert.Equal(t, "V-C002", list[0].Code)
+	assert.Equal(t, "/tables/0/columns/0/type", list[0].Pointer)
+	assert.Contains(t, list[0].Error(), "table 'accounts': column 'id': unsigned types are not supported by postgres")
+	assert.Equal(t, "/tables/0/columns/1/type", list[1].Pointer)
+	assert.Contains(t, list[1].Error(), "table 'accounts': column 'code': varchar requires a length in mysql")
+}
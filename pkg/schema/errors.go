@@ -0,0 +1,417 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity says whether a diagnostic blocks the schema from being used.
+type Severity string
+
+const (
+	// SeverityError marks a problem that makes the schema unusable.
+	SeverityError Severity = "error"
+
+	// SeverityWarning marks a problem worth reviewing that does not stop
+	// the schema from loading.
+	SeverityWarning Severity = "warning"
+)
+
+// Error is a schema problem located in the source document.
+//
+// Code is the validation rule that failed, as numbered in the schema spec
+// (for example V-C002 for an invalid data type); syntax errors have none.
+// Pointer is the JSON pointer (RFC 6901) of the offending value, such as
+// /tables/1/columns/2/type, or of the closest enclosing value when the
+// offending one is missing. File, Line, Column and Source are filled in when
+// the schema was parsed from text; Line and Column are 1-based, and Source is
+// the text of the line.
+type Error struct {
+	Severity Severity
+	Code     string
+	File     string
+	Line     int
+	Column   int
+	Pointer  string
+	Source   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same Code, so callers can
+// test for a rule with errors.Is(err, &schema.Error{Code: "V-G002"}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// ErrorList is every problem found in a schema, in the order the checks
+// run: schema-level fields first, then tables and columns, then foreign
+// keys and generation order. It may hold warnings as well as errors.
+type ErrorList []*Error
+
+// Error joins the messages, one per line.
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap exposes the entries to errors.Is and errors.As.
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Errors returns the entries with SeverityError.
+func (l ErrorList) Errors() ErrorList {
+	return l.filter(SeverityError)
+}
+
+// Warnings returns the entries with SeverityWarning.
+func (l ErrorList) Warnings() ErrorList {
+	return l.filter(SeverityWarning)
+}
+
+func (l ErrorList) filter(severity Severity) ErrorList {
+	var out ErrorList
+	for _, e := range l {
+		if e.Severity == severity {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Err returns the first entry with SeverityError, or nil if there is none.
+// It is how the fail-fast Validate* functions report.
+func (l ErrorList) Err() error {
+	for _, e := range l {
+		if e.Severity == SeverityError {
+			return e
+		}
+	}
+	return nil
+}
+
+// collector accumulates diagnostics for Validate.
+type collector struct {
+	list ErrorList
+}
+
+// error records an error-severity diagnostic for rule code at pointer. If
+// err already carries a more precise pointer, that one is kept.
+func (c *collector) error(code, pointer string, err error) {
+	c.add(SeverityError, code, pointer, err)
+}
+
+// warn records a warning-severity diagnostic.
+func (c *collector) warn(code, pointer string, err error) {
+	c.add(SeverityWarning, code, pointer, err)
+}
+
+func (c *collector) add(severity Severity, code, pointer string, err error) {
+	if err == nil {
+		return
+	}
+	var located *Error
+	if errors.As(err, &located) {
+		err = located.Err
+		if located.Pointer != "" {
+			pointer = located.Pointer
+		}
+	}
+	c.list = append(c.list, &Error{Severity: severity, Code: code, Pointer: pointer, Err: err})
+}
+
+// Excerpt renders the source line with a caret under the error's column,
+// preceded by its location, for example:
+//
+//	--> loans.json:12:19 (/tables/0/columns/2/type)
+//	   |
+//	12 |           "type": "integr",
+//	   |                   ^
+//
+// Returns "" when the error has no source position.
+func (e *Error) Excerpt() string {
+	if e.Line == 0 {
+		return ""
+	}
+
+	loc := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.File != "" {
+		loc = e.File + ":" + loc
+	}
+	if e.Pointer != "" {
+		loc += " (" + e.Pointer + ")"
+	}
+
+	num := strconv.Itoa(e.Line)
+	gutter := strings.Repeat(" ", len(num))
+	// Tabs keep their width so the caret lines up with the text above it
+	var pad strings.Builder
+	for i, r := range []rune(e.Source) {
+		if i >= e.Column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+
+	return fmt.Sprintf("%s--> %s\n%s |\n%s | %s\n%s | %s^", gutter, loc, gutter, num, e.Source, gutter, pad.String())
+}
+
+// at attaches a JSON pointer to err unless it already carries one, so the
+// innermost (most precise) location wins.
+func at(pointer string, err error) error {
+	var located *Error
+	if errors.As(err, &located) && located.Pointer != "" {
+		return err
+	}
+	return &Error{Pointer: pointer, Err: err}
+}
+
+// position records where each value and object key of a JSON document
+// starts, by JSON pointer.
+type position struct {
+	data   []byte
+	values map[string]int
+	keys   map[string]int
+	order  []string // key pointers in document order
+}
+
+// indexJSON builds a position index for data. It stops quietly at the first
+// syntax error, keeping whatever it has indexed so far.
+func indexJSON(data []byte) *position {
+	p := &position{data: data, values: make(map[string]int), keys: make(map[string]int)}
+
+	type frame struct {
+		pointer string
+		array   bool
+		index   int
+		key     string
+		wantKey bool
+	}
+	var stack []*frame
+
+	// next moves the parent container past the value that just ended
+	next := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if top := stack[len(stack)-1]; top.array {
+			top.index++
+		} else {
+			top.wantKey = true
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		start := p.skip(int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return p
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			next()
+			continue
+		}
+
+		pointer := ""
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.wantKey {
+				top.key, _ = tok.(string)
+				top.wantKey = false
+				key := top.pointer + "/" + escapePointer(top.key)
+				p.keys[key] = start
+				p.order = append(p.order, key)
+				continue
+			}
+			if top.array {
+				pointer = top.pointer + "/" + strconv.Itoa(top.index)
+			} else {
+				pointer = top.pointer + "/" + escapePointer(top.key)
+			}
+		}
+		p.values[pointer] = start
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{pointer: pointer, wantKey: true})
+		case json.Delim('['):
+			stack = append(stack, &frame{pointer: pointer, array: true})
+		default:
+			next()
+		}
+	}
+}
+
+// skip returns the offset of the next token at or after offset, passing
+// over whitespace and the separators the decoder consumes implicitly.
+func (p *position) skip(offset int) int {
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// lookup returns the offset of pointer's value, or of its closest enclosing
+// value that exists in the document.
+func (p *position) lookup(pointer string) int {
+	for {
+		if offset, ok := p.values[pointer]; ok {
+			return offset
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return 0
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// locate fills in e's line, column and source line for a byte offset.
+func (p *position) locate(e *Error, offset int) {
+	offset = min(max(offset, 0), len(p.data))
+	lineStart := bytes.LastIndexByte(p.data[:offset], '\n') + 1
+	lineEnd := bytes.IndexByte(p.data[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(p.data)
+	} else {
+		lineEnd += offset
+	}
+
+	e.Line = bytes.Count(p.data[:lineStart], []byte{'\n'}) + 1
+	e.Column = utf8.RuneCount(p.data[lineStart:offset]) + 1
+	e.Source = strings.TrimRight(string(p.data[lineStart:lineEnd]), "\r")
+}
+
+// valueAt returns the pointer of the innermost value starting before offset.
+func (p *position) valueAt(offset int) (string, int) {
+	best, bestOffset := "", 0
+	for pointer, start := range p.values {
+		if start < offset && (start > bestOffset || (start == bestOffset && len(pointer) > len(best))) {
+			best, bestOffset = pointer, start
+		}
+	}
+	return best, bestOffset
+}
+
+// decodeError locates an error returned by json.Decoder in data.
+func decodeError(data []byte, err error) *Error {
+	p := indexJSON(data)
+	located := &Error{Severity: SeverityError, Err: err}
+
+	var syntax *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		p.locate(located, int(syntax.Offset)-1)
+	case errors.As(err, &typeErr):
+		pointer, offset := p.valueAt(int(typeErr.Offset))
+		located.Pointer = pointer
+		p.locate(located, offset)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder does not say where the field is, so find the first
+		// key in the document that Schema has no field for
+		for _, key := range p.order {
+			if !knownPath(reflect.TypeOf(Schema{}), strings.Split(key, "/")[1:]) {
+				located.Pointer = key
+				p.locate(located, p.keys[key])
+				break
+			}
+		}
+	}
+	return located
+}
+
+// locateErrors fills in the source position of each diagnostic.
+func locateErrors(data []byte, list ErrorList) {
+	p := indexJSON(data)
+	for _, e := range list {
+		if e.Line == 0 {
+			p.locate(e, p.lookup(e.Pointer))
+		}
+	}
+}
+
+// knownPath reports whether the pointer segments name a field that t can
+// decode. Object keys match json tags case-insensitively, as encoding/json
+// does.
+func knownPath(t reflect.Type, segments []string) bool {
+	for _, seg := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, unescapePointer(seg))
+			if !ok {
+				return false
+			}
+			t = field.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			// interface{} holds anything; a scalar with children is a type
+			// error, not an unknown field
+			return true
+		}
+	}
+	return true
+}
+
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "" {
+			tag = f.Name
+		}
+		if tag != "-" && strings.EqualFold(tag, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,201 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// locatedSchema has an unsupported column type on line 12.
+const locatedSchema = `{
+  "schema_version": "1.0",
+  "name": "located",
+  "database_type": ["mysql"],
+  "tables": [
+    {
+      "name": "loans",
+      "record_count": 10,
+      "columns": [
+        {"name": "id", "type": "int", "primary_key": true},
+        {
+          "name": "amount", "type": "money"
+        }
+      ]
+    }
+  ]
+}`
+
+func parseError(t *testing.T, input string) *Error {
+	t.Helper()
+	_, err := ParseSchema(strings.NewReader(input))
+	require.Error(t, err)
+	var located *Error
+	require.True(t, errors.As(err, &located), "expected a *schema.Error, got %T: %v", err, err)
+	return located
+}
+
+func TestParseSchema_ValidationErrorPosition(t *testing.T) {
+	e := parseError(t, locatedSchema)
+
+	assert.Equal(t, "/tables/0/columns/1/type", e.Pointer)
+	assert.Equal(t, 12, e.Line)
+	assert.Equal(t, 37, e.Column)
+	assert.Equal(t, `          "name": "amount", "type": "money"`, e.Source)
+	assert.Contains(t, e.Error(), "line 12, column 37: table 0 (loans): column 1 (amount): invalid data type")
+}
+
+func TestParseSchema_MissingValueUsesEnclosingPosition(t *testing.T) {
+	input := strings.Replace(locatedSchema, `"record_count": 10,`, `"record_count": 0,`, 1)
+	e := parseError(t, input)
+	assert.Equal(t, "/tables/0/record_count", e.Pointer)
+	assert.Equal(t, 8, e.Line)
+
+	// A missing name points at the table object that lacks it
+	input = strings.Replace(locatedSchema, `"name": "loans",`, ``, 1)
+	e = parseError(t, input)
+	assert.Equal(t, "/tables/0/name", e.Pointer)
+	assert.Equal(t, 6, e.Line)
+	assert.Equal(t, 5, e.Column)
+}
+
+func TestParseSchema_DecodeErrorPositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pointer string
+		line    int
+		column  int
+	}{
+		{
+			name:   "syntax error",
+			input:  "{\n  \"name\": \"x\",\n  \"tables\": [,]\n}",
+			line:   3,
+			column: 14,
+		},
+		{
+			name:    "wrong type",
+			input:   "{\n  \"name\": \"x\",\n  \"tables\": [{\"name\": \"t\", \"record_count\": \"ten\"}]\n}",
+			pointer: "/tables/0/record_count",
+			line:    3,
+			column:  44,
+		},
+		{
+			name:    "unknown field",
+			input:   "{\n  \"name\": \"x\",\n  \"tables\": [{\"name\": \"t\",\n    \"colums\": []}]\n}",
+			pointer: "/tables/0/colums",
+			line:    4,
+			column:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := parseError(t, tt.input)
+			assert.Equal(t, tt.pointer, e.Pointer)
+			assert.Equal(t, tt.line, e.Line)
+			assert.Equal(t, tt.column, e.Column)
+		})
+	}
+}
+
+func TestLoadSchema_ErrorFileAndExcerpt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.json")
+	require.NoError(t, os.WriteFile(path, []byte(locatedSchema), 0o644))
+
+	_, err := LoadSchema(path)
+	require.Error(t, err)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, path, e.File)
+
+	assert.Equal(t, "  --> "+path+":12:37 (/tables/0/columns/1/type)\n"+
+		"   |\n"+
+		`12 |           "name": "amount", "type": "money"`+"\n"+
+		"   |                                     ^", e.Excerpt())
+}
+
+func TestValidateSchema_ErrorPointer(t *testing.T) {
+	s := &Schema{
+		Name:            "test",
+		DatabaseType:    []string{"mysql"},
+		Tables:          []Table{fkTable("borrowers"), fkTable("loans", "borrower_id->lenders")},
+		GenerationOrder: []string{"borrowers", "loans"},
+	}
+
+	err := ValidateSchema(s)
+	require.Error(t, err)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "/tables/1/columns/1/foreign_key/table", e.Pointer)
+	assert.Zero(t, e.Line, "a schema built in code has no source position")
+	assert.Empty(t, e.Excerpt())
+}
+
+func TestValidate_CollectsAllProblems(t *testing.T) {
+	loans := fkTable("loans", "borrower_id->lenders")
+	loans.RecordCount = 0
+	loans.Columns[1].ForeignKey.OnDelete = "NOTHING"
+	loans.Columns = append(loans.Columns, Column{Name: "terms", Type: "jsonb"}, Column{Name: "rate", Type: "money"})
+	s := &Schema{
+		DatabaseType:    []string{"mysql"},
+		Tables:          []Table{fkTable("borrowers"), loans},
+		GenerationOrder: []string{"borrowers", "loans", "payments"},
+	}
+
+	list := Validate(s)
+
+	type diag struct {
+		Severity Severity
+		Code     string
+		Pointer  string
+	}
+	var got []diag
+	for _, e := range list {
+		got = append(got, diag{e.Severity, e.Code, e.Pointer})
+	}
+	assert.Equal(t, []diag{
+		{SeverityError, "V-S001", "/name"},
+		{SeverityError, "V-T002", "/tables/1/record_count"},
+		{SeverityError, "V-C002", "/tables/1/columns/3/type"},
+		{SeverityWarning, "V-C002", "/tables/1/columns/2/type"},
+		{SeverityError, "V-C006", "/tables/1/columns/1/foreign_key/table"},
+		{SeverityError, "V-R003", "/tables/1/columns/1/foreign_key/on_delete"},
+		{SeverityError, "V-S007", "/generation_order/2"},
+	}, got)
+	assert.Len(t, list.Errors(), 6)
+	assert.Len(t, list.Warnings(), 1)
+
+	// The fail-fast API reports the first error only
+	err := ValidateSchema(s)
+	require.Error(t, err)
+	assert.Equal(t, list[0], err)
+
+	// errors.Is matches entries by rule code
+	assert.True(t, errors.Is(list, &Error{Code: "V-R003"}))
+	assert.False(t, errors.Is(list, &Error{Code: "V-G002"}))
+}
+
+func TestParseSchema_ReturnsAllErrors(t *testing.T) {
+	input := strings.Replace(locatedSchema, `"record_count": 10,`, `"record_count": 0,`, 1)
+	_, err := ParseSchema(strings.NewReader(input))
+	require.Error(t, err)
+
+	var list ErrorList
+	require.True(t, errors.As(err, &list), "expected an ErrorList, got %T", err)
+	require.Len(t, list, 2)
+	assert.Equal(t, 8, list[0].Line)
+	assert.Equal(t, 12, list[1].Line)
+	assert.Contains(t, err.Error(), "record_count must be greater than 0")
+	assert.Contains(t, err.Error(), "invalid data type \"money\"")
+
+	// Warnings alone do not fail parsing
+	input = strings.Replace(locatedSchema, `"money"`, `"jsonb"`, 1)
+	s, err := ParseSchema(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Len(t, Validate(s).Warnings(), 1)
+}
@@ -0,0 +1,563 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/distribution"
+)
+
+// Column type families, grouping data types that hold the same kind of value.
+// See TypeFamily.
+const (
+	FamilyInteger  = "integer"
+	FamilyNumber   = "number"
+	FamilyString   = "string"
+	FamilyDate     = "date"
+	FamilyDatetime = "datetime"
+	FamilyBoolean  = "boolean"
+	FamilyJSON     = "json"
+	FamilyEnum     = "enum"
+)
+
+// TypeFamily returns the family of a column data type, such as "integer" for
+// bigint or "string" for varchar(255), or "" for an invalid type.
+func TypeFamily(dataType string) string {
+	t, err := ParseDataType(dataType)
+	if err != nil {
+		return ""
+	}
+	return t.Family()
+}
+
+// ParamType is the JSON type a generator parameter accepts.
+type ParamType string
+
+const (
+	ParamNumber       ParamType = "number"
+	ParamInteger      ParamType = "integer"
+	ParamString       ParamType = "string"
+	ParamDate         ParamType = "date"   // a "YYYY-MM-DD" string
+	ParamArray        ParamType = "array"  // a JSON array
+	ParamObject       ParamType = "object" // a JSON object
+	ParamDistribution ParamType = "distribution"
+)
+
+// GeneratorParam describes one entry of a generator's generator_params.
+type GeneratorParam struct {
+	Name        string
+	Type        ParamType
+	Required    bool
+	Default     string   // the value used when absent, for documentation
+	Values      []string // the accepted values of a string parameter, if limited
+	Description string
+}
+
+// GeneratorSpec describes a built-in generator: the column types it can fill
+// and the parameters it accepts. Validation checks columns against it; the
+// generator package implements it.
+type GeneratorSpec struct {
+	Name        string
+	Category    string
+	Description string
+
+	// Types lists the column type families (see TypeFamily) the generator's
+	// values can be stored in.
+	Types []string
+
+	// Params lists the accepted generator_params, besides null_rate, which
+	// every column accepts. A generator with a "distribution" parameter also
+	// accepts the flat distribution form, whose parameters (distribution.
+	// ParamNames) sit next to its own.
+	Params []GeneratorParam
+
+	// check validates relationships between parameters, such as min <= max,
+	// once each has the right type.
+	check func(params map[string]interface{}) error
+}
+
+// Param returns the named parameter, or nil if the generator has none.
+func (g *GeneratorSpec) Param(name string) *GeneratorParam {
+	for i := range g.Params {
+		if g.Params[i].Name == name {
+			return &g.Params[i]
+		}
+	}
+	return nil
+}
+
+// nullRateParam is accepted by every column, with or without a generator.
+var nullRateParam = GeneratorParam{
+	Name: "null_rate", Type: ParamNumber, Default: "0",
+	Description: "fraction of rows (0 to 1) left NULL; requires a nullable column",
+}
+
+var (
+	distributionParam = GeneratorParam{
+		Name: "distribution", Type: ParamDistribution,
+		Description: "shapes the values: a type name with its parameters alongside, or {\"type\": ..., \"params\": {...}}",
+	}
+	minParam = GeneratorParam{Name: "min", Type: ParamNumber, Description: "smallest value"}
+	maxParam = GeneratorParam{Name: "max", Type: ParamNumber, Description: "largest value"}
+)
+
+// generatorSpecs is the catalog of built-in generators, keyed by name.
+var generatorSpecs = map[string]*GeneratorSpec{}
+
+func init() {
+	text := []string{FamilyString}
+	times := []string{FamilyDate, FamilyDatetime}
+
+	for _, g := range []*GeneratorSpec{
+		// Personal data
+		{Name: "first_name", Category: "personal", Description: "a first name", Types: text},
+		{Name: "last_name", Category: "personal", Description: "a last name", Types: text},
+		{Name: "full_name", Category: "personal", Description: "a first and last name", Types: text},
+		{Name: "email", Category: "personal", Description: "a name-based address at a non-routable domain", Types: text},
+		{Name: "phone", Category: "personal", Description: "a US phone number", Types: text, Params: []GeneratorParam{
+			{Name: "format", Type: ParamString, Default: "us", Values: []string{"us", "international", "digits"}, Description: "(555) 555-5555, +1-555-555-5555 or 5555555555"},
+		}},
+		{Name: "address", Category: "personal", Description: "a street address, sometimes with a unit", Types: text},
+		{Name: "ssn", Category: "personal", Description: "a US social security number (never a real one)", Types: text},
+		{Name: "date_of_birth", Category: "personal", Description: "a birth date for an age in [min_age, max_age]", Types: times, Params: []GeneratorParam{
+			{Name: "min_age", Type: ParamInteger, Default: "18", Description: "youngest age in years"},
+			{Name: "max_age", Type: ParamInteger, Default: "80", Description: "oldest age in years"},
+			distributionParam,
+		}, check: orderedInts("min_age", 18, "max_age", 80)},
+
+		// Company data
+		{Name: "company_name", Category: "company", Description: "a company name", Types: text},
+		{Name: "job_title", Category: "company", Description: "a job title", Types: text, Params: []GeneratorParam{
+			{Name: "level", Type: ParamString, Values: []string{"entry", "mid", "senior"}, Description: "seniority of the titles"},
+		}},
+		{Name: "company_email", Category: "company", Description: "a first.last address at a company domain", Types: text, Params: []GeneratorParam{
+			{Name: "domain", Type: ParamString, Description: "fixed domain; random company domains when absent"},
+		}},
+		{Name: "domain", Category: "company", Description: "a company domain name", Types: text},
+
+		// Date/time
+		{Name: "timestamp_past", Category: "datetime", Description: "a time between max_days_ago and min_days_ago before now", Types: times, Params: []GeneratorParam{
+			{Name: "min_days_ago", Type: ParamInteger, Default: "0", Description: "most recent offset in days"},
+			{Name: "max_days_ago", Type: ParamInteger, Default: "365", Description: "oldest offset in days"},
+			distributionParam,
+		}, check: orderedInts("min_days_ago", 0, "max_days_ago", 365)},
+		{Name: "timestamp_future", Category: "datetime", Description: "a time between min_days_ahead and max_days_ahead after now", Types: times, Params: []GeneratorParam{
+			{Name: "min_days_ahead", Type: ParamInteger, Default: "0", Description: "nearest offset in days"},
+			{Name: "max_days_ahead", Type: ParamInteger, Default: "365", Description: "furthest offset in days"},
+			distributionParam,
+		}, check: orderedInts("min_days_ahead", 0, "max_days_ahead", 365)},
+		{Name: "date_between", Category: "datetime", Description: "a time between two dates, inclusive", Types: times, Params: []GeneratorParam{
+			{Name: "start_date", Type: ParamDate, Required: true, Description: "first possible day (YYYY-MM-DD)"},
+			{Name: "end_date", Type: ParamDate, Required: true, Description: "last possible day (YYYY-MM-DD)"},
+		}, check: checkDateBetween},
+
+		// Numeric
+		{Name: "int_range", Category: "numeric", Description: "an integer in [min, max]", Types: []string{FamilyInteger, FamilyNumber}, Params: []GeneratorParam{
+			withDefault(minParam, "0"), withDefault(maxParam, "1000"), distributionParam,
+		}, check: checkIntRange},
+		{Name: "float_range", Category: "numeric", Description: "a number in [min, max]", Types: []string{FamilyNumber}, Params: []GeneratorParam{
+			withDefault(minParam, "0"), withDefault(maxParam, "100"), distributionParam,
+			{Name: "precision", Type: ParamInteger, Default: "2", Description: "decimal places to round to"},
+		}, check: checkRange},
+		{Name: "decimal_range", Category: "numeric", Description: "a decimal in [min, max]", Types: []string{FamilyNumber}, Params: []GeneratorParam{
+			withDefault(minParam, "0"), withDefault(maxParam, "1000"), distributionParam,
+			{Name: "scale", Type: ParamInteger, Default: "the column's scale", Description: "decimal places to round to"},
+		}, check: checkRange},
+
+		// Categorical and structured
+		{Name: "weighted", Category: "categorical", Description: "one of a list of values, picked by weight", Types: []string{FamilyString, FamilyInteger, FamilyNumber, FamilyEnum, FamilyBoolean}, Params: []GeneratorParam{
+			{Name: "values", Type: ParamArray, Required: true, Description: "[{\"value\": ..., \"weight\": ...}] or plain values of equal weight"},
+		}, check: checkValues},
+		{Name: "enum", Category: "categorical", Description: "one of a list of values, by default those of an enum column", Types: []string{FamilyEnum, FamilyString, FamilyInteger, FamilyNumber}, Params: []GeneratorParam{
+			{Name: "values", Type: ParamArray, Default: "the column's enum values", Description: "[{\"value\": ..., \"weight\": ...}] or plain values of equal weight"},
+		}, check: checkValues},
+		{Name: "boolean", Category: "categorical", Description: "true or false (1 or 0 in an integer column)", Types: []string{FamilyBoolean, FamilyInteger}, Params: []GeneratorParam{
+			{Name: "true_rate", Type: ParamNumber, Default: "0.5", Description: "probability (0 to 1) of true"},
+		}, check: checkTrueRate},
+		{Name: "uuid", Category: "categorical", Description: "a random UUID", Types: text},
+		{Name: "json_object", Category: "categorical", Description: "a JSON document", Types: []string{FamilyJSON, FamilyString}, Params: []GeneratorParam{
+			{Name: "fields", Type: ParamObject, Description: "maps each key to a generator name or {\"generator\": ..., \"generator_params\": {...}}"},
+		}, check: checkFields},
+	} {
+		generatorSpecs[g.Name] = g
+	}
+}
+
+// Generators returns the catalog of built-in generators, sorted by name.
+func Generators() []*GeneratorSpec {
+	specs := make([]*GeneratorSpec, 0, len(generatorSpecs))
+	for _, g := range generatorSpecs {
+		specs = append(specs, g)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// LookupGenerator returns the catalog entry of the named generator.
+func LookupGenerator(name string) (*GeneratorSpec, bool) {
+	g, ok := generatorSpecs[name]
+	return g, ok
+}
+
+func withDefault(p GeneratorParam, def string) GeneratorParam {
+	p.Default = def
+	return p
+}
+
+// ValidateGenerator validates a column's generator and generator_params
+// against the catalog: the generator must exist and suit the column type
+// (V-C003), its parameters must be known, of the right type and consistent
+// (V-C004), and a distribution must be valid (V-C005).
+// Returns the first validation error encountered, or nil if valid.
+func ValidateGenerator(col *Column, tableName string) error {
+	var c collector
+	c.generator(col, tableName, "")
+	return c.list.Err()
+}
+
+func (c *collector) generator(col *Column, tableName, pointer string) {
+	prefix := fmt.Sprintf("table '%s': column '%s'", tableName, col.Name)
+	params := col.GeneratorParams
+
+	// null_rate applies with or without a generator
+	if v, ok := params[nullRateParam.Name]; ok {
+		if err := checkParam(&nullRateParam, v); err != nil {
+			c.error("V-C004", pointer+"/generator_params/null_rate", fmt.Errorf("%s: %w", prefix, err))
+		} else if rate, _ := toFloat(v); rate < 0 || rate > 1 {
+			c.error("V-C004", pointer+"/generator_params/null_rate", fmt.Errorf("%s: null_rate must be between 0 and 1", prefix))
+		} else if rate > 0 && !col.Nullable {
+			c.error("V-C004", pointer+"/generator_params/null_rate", fmt.Errorf("%s: null_rate requires a nullable column", prefix))
+		}
+	}
+
+	if col.Generator == "" {
+		for _, key := range sortedKeys(params) {
+			if key != nullRateParam.Name {
+				c.error("V-C004", pointer+"/generator_params/"+escapePointer(key), fmt.Errorf("%s: generator_params key '%s' has no effect without a generator", prefix, key))
+			}
+		}
+		return
+	}
+
+	g, ok := LookupGenerator(col.Generator)
+	if !ok {
+		names := make([]string, 0, len(generatorSpecs))
+		for name := range generatorSpecs {
+			names = append(names, name)
+		}
+		c.error("V-C003", pointer+"/generator", fmt.Errorf("%s: unknown generator '%s'%s", prefix, col.Generator, didYouMean(col.Generator, names)))
+		return
+	}
+
+	if family := col.DataType().Family(); family != "" && !containsString(g.Types, family) {
+		c.error("V-C003", pointer+"/generator", fmt.Errorf("%s: generator '%s' cannot fill a %s column (it suits: %s)", prefix, g.Name, col.Type, strings.Join(g.Types, ", ")))
+	}
+
+	prefix += ": generator '" + g.Name + "'"
+	before := len(c.list)
+
+	// The flat distribution form puts the distribution's parameters next to
+	// the generator's own
+	_, flat := params["distribution"].(string)
+	for _, key := range sortedKeys(params) {
+		if key == nullRateParam.Name {
+			continue
+		}
+		keyPointer := pointer + "/generator_params/" + escapePointer(key)
+		p := g.Param(key)
+		if p == nil {
+			if flat && containsString(distribution.ParamNames, key) {
+				continue
+			}
+			names := []string{nullRateParam.Name}
+			for _, p := range g.Params {
+				names = append(names, p.Name)
+			}
+			if g.Param("distribution") != nil {
+				names = append(names, distribution.ParamNames...)
+			}
+			c.error("V-C004", keyPointer, fmt.Errorf("%s: unknown parameter '%s'%s", prefix, key, didYouMean(key, names)))
+			continue
+		}
+		c.error("V-C004", keyPointer, prefixed(prefix, checkParam(p, params[key])))
+	}
+	for _, p := range g.Params {
+		if _, ok := params[p.Name]; p.Required && !ok {
+			c.error("V-C004", pointer+"/generator_params", fmt.Errorf("%s: parameter '%s' is required", prefix, p.Name))
+		}
+	}
+	// enum falls back to the values of the column's type, which only an
+	// enum type has
+	if _, ok := params["values"]; g.Name == "enum" && !ok && col.DataType().Family() != FamilyEnum {
+		c.error("V-C004", pointer+"/generator_params", fmt.Errorf("%s: parameter 'values' is required unless the column type is enum", prefix))
+	}
+
+	// Relationships between parameters only make sense once each is valid
+	if len(c.list) > before {
+		return
+	}
+	if g.check != nil {
+		c.error("V-C004", pointer+"/generator_params", prefixed(prefix, g.check(params)))
+	}
+	if _, ok := params["distribution"]; ok {
+		if _, err := distribution.FromParams(params); err != nil {
+			c.error("V-C005", pointer+"/generator_params/distribution", fmt.Errorf("%s: %w", prefix, err))
+		}
+	}
+}
+
+// checkParam checks that v has the type p declares.
+func checkParam(p *GeneratorParam, v interface{}) error {
+	switch p.Type {
+	case ParamNumber:
+		if _, ok := toFloat(v); !ok {
+			return fmt.Errorf("parameter '%s' must be a number, got %s", p.Name, jsonType(v))
+		}
+	case ParamInteger:
+		f, ok := toFloat(v)
+		if !ok || f != math.Trunc(f) {
+			return fmt.Errorf("parameter '%s' must be an integer, got %v", p.Name, v)
+		}
+	case ParamString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("parameter '%s' must be a string, got %s", p.Name, jsonType(v))
+		}
+		if len(p.Values) > 0 && !containsString(p.Values, s) {
+			return fmt.Errorf("invalid %s %q: must be one of: %s", p.Name, s, strings.Join(p.Values, ", "))
+		}
+	case ParamDate:
+		s, ok := v.(string)
+		if _, err := time.Parse("2006-01-02", s); !ok || err != nil {
+			return fmt.Errorf("parameter '%s' must be a YYYY-MM-DD date, got %v", p.Name, v)
+		}
+	case ParamArray:
+		if _, ok := v.([]interface{}); !ok {
+			return fmt.Errorf("parameter '%s' must be an array, got %s", p.Name, jsonType(v))
+		}
+	case ParamObject:
+		if _, ok := v.(map[string]interface{}); !ok {
+			return fmt.Errorf("parameter '%s' must be an object, got %s", p.Name, jsonType(v))
+		}
+	case ParamDistribution:
+		switch v.(type) {
+		case string, map[string]interface{}:
+		default:
+			return fmt.Errorf("parameter '%s' must be a type name or an object, got %s", p.Name, jsonType(v))
+		}
+	}
+	return nil
+}
+
+// checkRange checks min <= max for the numeric range generators.
+func checkRange(params map[string]interface{}) error {
+	lo, hasMin := toFloat(params["min"])
+	hi, hasMax := toFloat(params["max"])
+	if hasMin && hasMax && lo > hi {
+		return fmt.Errorf("min (%v) must not be greater than max (%v)", lo, hi)
+	}
+	return nil
+}
+
+// checkIntRange checks that min <= max and, when both are given, that an
+// integer lies between them.
+func checkIntRange(params map[string]interface{}) error {
+	if err := checkRange(params); err != nil {
+		return err
+	}
+	lo, hasMin := toFloat(params["min"])
+	hi, hasMax := toFloat(params["max"])
+	if hasMin && hasMax && math.Ceil(lo) > math.Floor(hi) {
+		return fmt.Errorf("no integer lies between min (%v) and max (%v)", lo, hi)
+	}
+	return nil
+}
+
+// orderedInts returns a check that 0 <= lo <= hi for a pair of integer
+// parameters with the given defaults.
+func orderedInts(loKey string, loDefault float64, hiKey string, hiDefault float64) func(map[string]interface{}) error {
+	return func(params map[string]interface{}) error {
+		lo, ok := toFloat(params[loKey])
+		if !ok {
+			lo = loDefault
+		}
+		hi, ok := toFloat(params[hiKey])
+		if !ok {
+			hi = hiDefault
+		}
+		if lo < 0 || lo > hi {
+			return fmt.Errorf("%s (%v) must be between 0 and %s (%v)", loKey, lo, hiKey, hi)
+		}
+		return nil
+	}
+}
+
+func checkDateBetween(params map[string]interface{}) error {
+	start, _ := params["start_date"].(string)
+	end, _ := params["end_date"].(string)
+	// YYYY-MM-DD dates compare correctly as strings
+	if end < start {
+		return fmt.Errorf("end_date (%s) must not be before start_date (%s)", end, start)
+	}
+	return nil
+}
+
+func checkTrueRate(params map[string]interface{}) error {
+	if p, ok := toFloat(params["true_rate"]); ok && (p < 0 || p > 1) {
+		return fmt.Errorf("true_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// checkValues checks the entries of a weighted or enum "values" list.
+func checkValues(params map[string]interface{}) error {
+	list, _ := params["values"].([]interface{})
+	if _, ok := params["values"]; ok && len(list) == 0 {
+		return fmt.Errorf("parameter 'values' must not be empty")
+	}
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := obj["value"]; !ok {
+			return fmt.Errorf("values[%d]: 'value' is required", i)
+		}
+		if w, ok := obj["weight"]; ok {
+			if f, ok := toFloat(w); !ok || f < 0 {
+				return fmt.Errorf("values[%d]: weight must be a number that is not negative", i)
+			}
+		}
+	}
+	return nil
+}
+
+// checkFields checks that each json_object field names a known generator.
+func checkFields(params map[string]interface{}) error {
+	fields, _ := params["fields"].(map[string]interface{})
+	for _, key := range sortedKeys(fields) {
+		var name string
+		switch spec := fields[key].(type) {
+		case string:
+			name = spec
+		case map[string]interface{}:
+			name, _ = spec["generator"].(string)
+		default:
+			return fmt.Errorf("fields.%s: must be a generator name or object", key)
+		}
+		if _, ok := LookupGenerator(name); !ok || name == "json_object" {
+			return fmt.Errorf("fields.%s: unknown generator '%s'", key, name)
+		}
+	}
+	return nil
+}
+
+// toFloat converts a JSON number (or a Go integer, for schemas built in
+// code) to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// jsonType names the JSON type of a decoded value, for error messages.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		if _, ok := toFloat(v); ok {
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func prefixed(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", prefix, err)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// didYouMean suggests the candidate closest to name, when one is within two
+// edits, as a message suffix such as " (did you mean 'email'?)".
+func didYouMean(name string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(name), c); d < bestDist || (d == bestDist && best != "" && c < best) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean '%s'?)", best)
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn one into the other.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
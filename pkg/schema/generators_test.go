@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerators(t *testing.T) {
+	specs := Generators()
+	require.NotEmpty(t, specs)
+	for i, g := range specs {
+		if i > 0 {
+			assert.Less(t, specs[i-1].Name, g.Name, "generators should be sorted by name")
+		}
+		assert.NotEmpty(t, g.Types, "generator %s should declare the column types it fills", g.Name)
+		assert.NotEmpty(t, g.Description, "generator %s should be described", g.Name)
+	}
+
+	g, ok := LookupGenerator("date_between")
+	require.True(t, ok)
+	require.NotNil(t, g.Param("start_date"))
+	assert.True(t, g.Param("start_date").Required)
+	assert.Nil(t, g.Param("years_ago"))
+}
+
+func TestTypeFamily(t *testing.T) {
+	assert.Equal(t, FamilyInteger, TypeFamily("BIGINT"))
+	assert.Equal(t, FamilyNumber, TypeFamily("decimal(10, 2)"))
+	assert.Equal(t, FamilyString, TypeFamily("varchar(255)"))
+	assert.Equal(t, FamilyDatetime, TypeFamily("timestamp"))
+	assert.Equal(t, FamilyEnum, TypeFamily("enum('a','b')"))
+	assert.Equal(t, "", TypeFamily("money"))
+}
+
+func TestValidateGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		col      Column
+		errorMsg string
+		code     string
+	}{
+		{
+			name: "no generator",
+			col:  Column{Name: "c", Type: "int"},
+		},
+		{
+			name: "valid parameters",
+			col: Column{Name: "c", Type: "int", Nullable: true, Generator: "int_range", GeneratorParams: map[string]interface{}{
+				"min": 300.0, "max": 850.0, "null_rate": 0.1,
+			}},
+		},
+		{
+			name: "flat distribution",
+			col: Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{
+				"distribution": "normal", "mean": 680.0, "std_dev": 80.0, "min": 300.0, "max": 850.0,
+			}},
+		},
+		{
+			name: "nested distribution",
+			col: Column{Name: "c", Type: "decimal(10,2)", Generator: "decimal_range", GeneratorParams: map[string]interface{}{
+				"distribution": map[string]interface{}{"type": "lognormal", "params": map[string]interface{}{"median": 5000.0}},
+			}},
+		},
+		{
+			name:     "unknown generator",
+			col:      Column{Name: "c", Type: "int", Generator: "normal(mean=680, std_dev=80, min=300, max=850)"},
+			errorMsg: "unknown generator 'normal(mean=680, std_dev=80, min=300, max=850)'",
+			code:     "V-C003",
+		},
+		{
+			name:     "misspelled generator",
+			col:      Column{Name: "c", Type: "varchar(100)", Generator: "emial"},
+			errorMsg: "unknown generator 'emial' (did you mean 'email'?)",
+			code:     "V-C003",
+		},
+		{
+			name:     "incompatible column type",
+			col:      Column{Name: "c", Type: "int", Generator: "email"},
+			errorMsg: "generator 'email' cannot fill a int column (it suits: string)",
+			code:     "V-C003",
+		},
+		{
+			name:     "misspelled parameter",
+			col:      Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{"mni": 1.0}},
+			errorMsg: "generator 'int_range': unknown parameter 'mni' (did you mean 'min'?)",
+			code:     "V-C004",
+		},
+		{
+			name:     "distribution parameter without a flat distribution",
+			col:      Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{"mean": 1.0}},
+			errorMsg: "generator 'int_range': unknown parameter 'mean'",
+			code:     "V-C004",
+		},
+		{
+			name:     "min greater than max",
+			col:      Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{"min": 10.0, "max": 1.0}},
+			errorMsg: "generator 'int_range': min (10) must not be greater than max (1)",
+			code:     "V-C004",
+		},
+		{
+			name:     "no integer between min and max",
+			col:      Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{"min": 0.2, "max": 0.8}},
+			errorMsg: "generator 'int_range': no integer lies between min (0.2) and max (0.8)",
+			code:     "V-C004",
+		},
+		{
+			name: "fractional bounds around an integer",
+			col:  Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{"min": 0.5, "max": 1.5}},
+		},
+		{
+			name: "enum values from the column type",
+			col:  Column{Name: "c", Type: "enum('a','b')", Generator: "enum"},
+		},
+		{
+			name:     "enum without values on a non-enum column",
+			col:      Column{Name: "c", Type: "varchar(10)", Generator: "enum"},
+			errorMsg: "generator 'enum': parameter 'values' is required unless the column type is enum",
+			code:     "V-C004",
+		},
+		{
+			name: "enum with values on a non-enum column",
+			col:  Column{Name: "c", Type: "varchar(10)", Generator: "enum", GeneratorParams: map[string]interface{}{"values": []interface{}{"x", "y"}}},
+		},
+		{
+			name:     "wrong parameter type",
+			col:      Column{Name: "c", Type: "date", Generator: "date_of_birth", GeneratorParams: map[string]interface{}{"min_age": "18"}},
+			errorMsg: "generator 'date_of_birth': parameter 'min_age' must be an integer, got 18",
+			code:     "V-C004",
+		},
+		{
+			name:     "value not allowed",
+			col:      Column{Name: "c", Type: "varchar(20)", Generator: "phone", GeneratorParams: map[string]interface{}{"format": "uk"}},
+			errorMsg: "generator 'phone': invalid format \"uk\": must be one of: us, international, digits",
+			code:     "V-C004",
+		},
+		{
+			name:     "missing required parameter",
+			col:      Column{Name: "c", Type: "date", Generator: "date_between", GeneratorParams: map[string]interface{}{"start_date": "2020-01-01"}},
+			errorMsg: "generator 'date_between': parameter 'end_date' is required",
+			code:     "V-C004",
+		},
+		{
+			name:     "null_rate on a NOT NULL column",
+			col:      Column{Name: "c", Type: "int", GeneratorParams: map[string]interface{}{"null_rate": 0.2}},
+			errorMsg: "null_rate requires a nullable column",
+			code:     "V-C004",
+		},
+		{
+			name:     "parameters without a generator",
+			col:      Column{Name: "c", Type: "int", GeneratorParams: map[string]interface{}{"min": 1.0}},
+			errorMsg: "generator_params key 'min' has no effect without a generator",
+			code:     "V-C004",
+		},
+		{
+			name: "invalid distribution",
+			col: Column{Name: "c", Type: "int", Generator: "int_range", GeneratorParams: map[string]interface{}{
+				"distribution": "normal", "mean": 680.0,
+			}},
+			errorMsg: "generator 'int_range': distribution \"normal\": std_dev is required",
+			code:     "V-C005",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGenerator(&tt.col, "t")
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "table 't': column 'c': "+tt.errorMsg)
+			assert.ErrorIs(t, err, &Error{Code: tt.code})
+		})
+	}
+}
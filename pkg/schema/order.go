@@ -0,0 +1,239 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dependency is one foreign key edge: Table.Column references Parent.
+type dependency struct {
+	Table    string
+	Column   string
+	Parent   string
+	Nullable bool
+	pointer  string // JSON pointer of the foreign_key object
+}
+
+// foreignKeyEdges returns the foreign key edges of each table, keyed by
+// child table name. References to tables that are not in tables are skipped;
+// they are reported by ValidateForeignKeys.
+func foreignKeyEdges(tables []Table) map[string][]dependency {
+	known := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		known[t.Name] = true
+	}
+
+	edges := make(map[string][]dependency, len(tables))
+	for i, t := range tables {
+		for j, col := range t.Columns {
+			if col.ForeignKey == nil || !known[col.ForeignKey.Table] {
+				continue
+			}
+			edges[t.Name] = append(edges[t.Name], dependency{
+				Table:    t.Name,
+				Column:   col.Name,
+				Parent:   col.ForeignKey.Table,
+				Nullable: col.Nullable,
+				pointer:  fmt.Sprintf("/tables/%d/columns/%d/foreign_key", i, j),
+			})
+		}
+	}
+	return edges
+}
+
+// DeferredForeignKeys returns, per table, the foreign key columns that are
+// filled in after every table has been generated: nullable self-references
+// and nullable foreign keys that are part of a cycle. Their rows are
+// inserted with NULL and back-filled by a second pass, which breaks the
+// cycle. A cycle made only of NOT NULL foreign keys cannot be deferred and
+// is reported by DependencyOrder.
+func DeferredForeignKeys(tables []Table) map[string]map[string]bool {
+	edges := foreignKeyEdges(tables)
+
+	// reaches reports whether from depends, directly or not, on to
+	reaches := func(from, to string) bool {
+		seen := map[string]bool{from: true}
+		stack := []string{from}
+		for len(stack) > 0 {
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, d := range edges[name] {
+				if d.Parent == to {
+					return true
+				}
+				if !seen[d.Parent] {
+					seen[d.Parent] = true
+					stack = append(stack, d.Parent)
+				}
+			}
+		}
+		return false
+	}
+
+	deferred := make(map[string]map[string]bool)
+	for _, t := range tables {
+		for _, d := range edges[t.Name] {
+			if !d.Nullable || (d.Parent != d.Table && !reaches(d.Parent, d.Table)) {
+				continue
+			}
+			if deferred[d.Table] == nil {
+				deferred[d.Table] = make(map[string]bool)
+			}
+			deferred[d.Table][d.Column] = true
+		}
+	}
+	return deferred
+}
+
+// dependencies returns the foreign key edges that constrain generation
+// order: every edge except the deferred ones.
+func dependencies(tables []Table) map[string][]dependency {
+	edges := foreignKeyEdges(tables)
+	deferred := DeferredForeignKeys(tables)
+
+	deps := make(map[string][]dependency, len(edges))
+	for table, list := range edges {
+		for _, d := range list {
+			if !deferred[table][d.Column] {
+				deps[table] = append(deps[table], d)
+			}
+		}
+	}
+	return deps
+}
+
+// DependencyOrder derives a generation order from the tables' foreign keys.
+// Every table comes after the tables it references, ignoring deferred
+// foreign keys; tables that do not depend on each other keep their
+// declaration order, so the result is deterministic. Returns an error naming
+// the cycle if NOT NULL foreign keys form one.
+func DependencyOrder(tables []Table) ([]string, error) {
+	if err := detectCycle(tables); err != nil {
+		return nil, err
+	}
+
+	deps := dependencies(tables)
+	placed := make(map[string]bool, len(tables))
+	order := make([]string, 0, len(tables))
+
+	// Repeatedly take the first table, in declaration order, whose parents
+	// have all been placed. detectCycle guarantees progress on every pass.
+	for len(order) < len(tables) {
+		for _, t := range tables {
+			if placed[t.Name] || !parentsPlaced(deps[t.Name], placed) {
+				continue
+			}
+			placed[t.Name] = true
+			order = append(order, t.Name)
+			break
+		}
+	}
+
+	return order, nil
+}
+
+func parentsPlaced(deps []dependency, placed map[string]bool) bool {
+	for _, d := range deps {
+		if !placed[d.Parent] {
+			return false
+		}
+	}
+	return true
+}
+
+// detectCycle walks the graph of non-deferred foreign keys depth-first and
+// reports the first cycle found, with a suggestion for breaking it.
+func detectCycle(tables []Table) error {
+	deps := dependencies(tables)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(tables))
+	var path []dependency
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		state[name] = visiting
+		for _, d := range deps[name] {
+			switch state[d.Parent] {
+			case visiting:
+				// The cycle starts at the edge leaving d.Parent; a
+				// self-reference has no such edge and is just d.
+				start := len(path)
+				for i := range path {
+					if path[i].Table == d.Parent {
+						start = i
+					}
+				}
+				return cycleError(append(append([]dependency{}, path[start:]...), d))
+			case unvisited:
+				path = append(path, d)
+				if err := visit(d.Parent); err != nil {
+					return err
+				}
+				path = path[:len(path)-1]
+			}
+		}
+		state[name] = done
+		return nil
+	}
+
+	for _, t := range tables {
+		if state[t.Name] == unvisited {
+			if err := visit(t.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cycleError describes a foreign key cycle. Every key in it is NOT NULL,
+// since nullable keys in a cycle are deferred.
+func cycleError(cycle []dependency) error {
+	tables := make([]string, 0, len(cycle)+1)
+	columns := make([]string, 0, len(cycle))
+	for _, d := range cycle {
+		tables = append(tables, d.Table)
+		columns = append(columns, d.Table+"."+d.Column)
+	}
+	tables = append(tables, cycle[0].Table)
+
+	return at(cycle[0].pointer, fmt.Errorf("circular dependency detected: %s (via %s): make one of these foreign keys nullable so it can be deferred and back-filled, or remove it",
+		strings.Join(tables, " -> "), strings.Join(columns, ", ")))
+}
+
+// ValidateDependencyOrder checks that order lists every referenced (parent)
+// table before the tables whose foreign keys point to it. Deferred foreign
+// keys are exempt, since they are filled in last. order is expected
+// to have passed ValidateGenerationOrder.
+func ValidateDependencyOrder(tables []Table, order []string) error {
+	position := make(map[string]int, len(order))
+	for i, name := range order {
+		position[name] = i
+	}
+
+	deps := dependencies(tables)
+	for _, t := range tables {
+		for _, d := range deps[t.Name] {
+			if position[d.Table] < position[d.Parent] {
+				return at(fmt.Sprintf("/generation_order/%d", position[d.Table]), fmt.Errorf("invalid generation_order: '%s' has foreign key to '%s' (column '%s'), but '%s' appears later in generation_order (position %d vs %d)",
+					d.Table, d.Parent, d.Column, d.Parent, position[d.Parent], position[d.Table]))
+			}
+		}
+	}
+	return nil
+}
+
+// TableOrder returns the order in which tables should be generated: the
+// declared generation_order if there is one, otherwise the order derived
+// from foreign keys by DependencyOrder.
+func (s *Schema) TableOrder() ([]string, error) {
+	if len(s.GenerationOrder) > 0 {
+		return s.GenerationOrder, nil
+	}
+	return DependencyOrder(s.Tables)
+}
@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fkTable returns a table with an id primary key and one foreign key column
+// per entry of refs, given as "column->parent". A column prefixed with "?"
+// is nullable.
+func fkTable(name string, refs ...string) Table {
+	t := Table{
+		Name:        name,
+		RecordCount: 10,
+		Columns:     []Column{{Name: "id", Type: "int", PrimaryKey: true}},
+	}
+	for _, ref := range refs {
+		col, parent, _ := strings.Cut(ref, "->")
+		nullable := strings.HasPrefix(col, "?")
+		t.Columns = append(t.Columns, Column{
+			Name:     strings.TrimPrefix(col, "?"),
+			Type:     "int",
+			Nullable: nullable,
+			ForeignKey: &ForeignKey{
+				Table: parent, Column: "id", OnDelete: "CASCADE", OnUpdate: "CASCADE",
+			},
+		})
+	}
+	return t
+}
+
+func TestDependencyOrder(t *testing.T) {
+	tables := []Table{
+		fkTable("payments", "loan_id->loans"),
+		fkTable("loans", "borrower_id->borrowers", "branch_id->branches"),
+		fkTable("audit_log"),
+		fkTable("borrowers"),
+		fkTable("branches"),
+	}
+
+	order, err := DependencyOrder(tables)
+	require.NoError(t, err)
+	// Independent tables keep their declaration order
+	assert.Equal(t, []string{"audit_log", "borrowers", "branches", "loans", "payments"}, order)
+}
+
+func TestDependencyOrder_Cycles(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []Table
+		want   []string
+	}{
+		{
+			name: "two tables without a nullable key",
+			tables: []Table{
+				fkTable("users", "primary_address_id->addresses"),
+				fkTable("addresses", "user_id->users"),
+			},
+			want: []string{
+				"circular dependency detected: users -> addresses -> users",
+				"via users.primary_address_id, addresses.user_id",
+				"make one of these foreign keys nullable",
+			},
+		},
+		{
+			name: "NOT NULL self reference",
+			tables: []Table{
+				fkTable("employees", "manager_id->employees"),
+			},
+			want: []string{
+				"employees -> employees (via employees.manager_id)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := DependencyOrder(tt.tables)
+			require.Error(t, err)
+			assert.Nil(t, order)
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestDeferredForeignKeys(t *testing.T) {
+	tables := []Table{
+		fkTable("customers", "?referrer_id->customers"),
+		fkTable("orders", "customer_id->customers", "?latest_invoice_id->invoices"),
+		fkTable("invoices", "order_id->orders"),
+		fkTable("notes", "?order_id->orders"),
+	}
+
+	// Nullable keys on a cycle are deferred; other nullable keys are not
+	assert.Equal(t, map[string]map[string]bool{
+		"customers": {"referrer_id": true},
+		"orders":    {"latest_invoice_id": true},
+	}, DeferredForeignKeys(tables))
+
+	order, err := DependencyOrder(tables)
+	require.NoError(t, err, "deferred keys should break the cycles")
+	assert.Equal(t, []string{"customers", "orders", "invoices", "notes"}, order)
+
+	// A declared order may list a deferred key's parent later
+	assert.NoError(t, ValidateDependencyOrder(tables, []string{"customers", "orders", "invoices", "notes"}))
+}
+
+func TestValidateDependencyOrder(t *testing.T) {
+	tables := []Table{
+		fkTable("borrowers"),
+		fkTable("loans", "borrower_id->borrowers"),
+	}
+
+	assert.NoError(t, ValidateDependencyOrder(tables, []string{"borrowers", "loans"}))
+
+	err := ValidateDependencyOrder(tables, []string{"loans", "borrowers"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid generation_order: 'loans' has foreign key to 'borrowers' (column 'borrower_id'), but 'borrowers' appears later in generation_order (position 1 vs 0)", err.Error())
+}
+
+func TestValidateSchema_GenerationOrderDependencies(t *testing.T) {
+	s := &Schema{
+		Name:            "test",
+		DatabaseType:    []string{"mysql"},
+		Tables:          []Table{fkTable("borrowers"), fkTable("loans", "borrower_id->borrowers")},
+		GenerationOrder: []string{"loans", "borrowers"},
+	}
+	err := ValidateSchema(s)
+	require.Error(t, err, "a child listed before its parent should be rejected")
+	assert.Contains(t, err.Error(), "'borrowers' appears later in generation_order")
+
+	s.Tables = append(s.Tables, fkTable("a", "b_id->b"), fkTable("b", "a_id->a"))
+	s.GenerationOrder = nil
+	err = ValidateSchema(s)
+	require.Error(t, err, "cycles should be rejected even without a generation_order")
+	assert.Contains(t, err.Error(), "circular dependency detected: a -> b -> a")
+}
+
+func TestTableOrder(t *testing.T) {
+	s := &Schema{Tables: []Table{fkTable("loans", "borrower_id->borrowers"), fkTable("borrowers")}}
+
+	order, err := s.TableOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"borrowers", "loans"}, order, "missing order should be derived")
+
+	s.GenerationOrder = []string{"loans", "borrowers"}
+	order, err = s.TableOrder()
+	require.NoError(t, err)
+	assert.Equal(t, s.GenerationOrder, order, "declared order should be returned as is")
+}
+
+func TestValidateForeignKeys_DeferredKeys(t *testing.T) {
+	names := map[string]bool{"employees": true, "teams": true}
+
+	employees := fkTable("employees", "?manager_id->employees")
+	employees.Columns[1].ForeignKey.Hierarchy = &Hierarchy{MaxDepth: 3, MaxChildren: 4}
+	assert.NoError(t, ValidateForeignKeys([]Table{employees, fkTable("teams")}, names))
+
+	employees.Columns[1].ForeignKey.Hierarchy = &Hierarchy{MaxDepth: -1}
+	err := ValidateForeignKeys([]Table{employees, fkTable("teams")}, names)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'employees': column 'manager_id': hierarchy roots, max_depth and max_children must not be negative")
+
+	teams := fkTable("teams", "lead_id->employees")
+	teams.Columns[1].ForeignKey.Hierarchy = &Hierarchy{}
+	err = ValidateForeignKeys([]Table{fkTable("employees"), teams}, names)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hierarchy requires a self-referencing foreign key, but it references 'employees'")
+
+	employees.Columns[1].ForeignKey.Hierarchy = nil
+	employees.Columns[1].ForeignKey.Cardinality = &Cardinality{Min: 1, Max: 2}
+	err = ValidateForeignKeys([]Table{employees, fkTable("teams")}, names)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cardinality is not supported on a self-referencing or circular foreign key")
+}
@@ -1,54 +1,94 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
-)
 
-// validTypes defines all supported data types from F007 specification.
-// Stored as lowercase for case-insensitive matching via prefix matching.
-// Supports parameterized types like varchar(255), decimal(10,2), enum('a','b').
-var validTypes = []string{
-	// Integer types
-	"int", "bigint", "smallint", "tinyint",
-	// Decimal types
-	"decimal", "float", "double",
-	// String types
-	"varchar", "text", "char",
-	// Date/Time types
-	"date", "datetime", "timestamp",
-	// Boolean types
-	"boolean", "bit",
-	// JSON types
-	"json", "jsonb",
-	// Enum type
-	"enum",
-}
+	"github.com/jbeausoleil/sourcebox/pkg/distribution"
+)
 
 // ParseSchema parses a schema from an io.Reader.
 // Returns the parsed Schema or an error if parsing fails.
 // Uses strict parsing to catch unknown fields in the JSON.
+//
+// Syntax errors carry their position in the document as an *Error, and
+// validation failures as an ErrorList of every problem found (warnings
+// included), which errors.As can extract.
 func ParseSchema(r io.Reader) (*Schema, error) {
-	decoder := json.NewDecoder(r)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchema: failed to read schema: %w", err)
+	}
+
+	schema, _, err := decode(data, FormatJSON)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchema: %w", err)
+	}
+	return schema, nil
+}
+
+// decode parses and validates a document in either format, returning the
+// schema and its warnings.
+func decode(data []byte, format Format) (*Schema, ErrorList, error) {
+	if format != FormatYAML {
+		return parse(data, FormatJSON, nil)
+	}
+	doc, err := yamlToJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+	return parse(doc.json, FormatYAML, doc.relocate)
+}
+
+// parse decodes and validates a JSON document, returning the schema and its
+// located warnings. format names the source format in decode errors, and
+// relocate, when set, moves each diagnostic from data to the source
+// document it was converted from.
+func parse(data []byte, format Format, relocate func(*Error)) (*Schema, ErrorList, error) {
+	if relocate == nil {
+		relocate = func(*Error) {}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
 	decoder.DisallowUnknownFields()
 
 	var schema Schema
 	if err := decoder.Decode(&schema); err != nil {
-		return nil, fmt.Errorf("ParseSchema: failed to decode JSON: %w", err)
+		located := decodeError(data, err)
+		relocate(located)
+		return nil, nil, fmt.Errorf("failed to decode %s: %w", strings.ToUpper(string(format)), located)
 	}
 
-	// Validate the schema after parsing
-	if err := ValidateSchema(&schema); err != nil {
-		return nil, fmt.Errorf("ParseSchema: %w", err)
+	// Validate the schema after parsing, reporting every error at once
+	list := Validate(&schema)
+	locateErrors(data, list)
+	for _, e := range list {
+		relocate(e)
+	}
+	if list.Err() != nil {
+		return nil, nil, list
+	}
+
+	// Derive generation_order from foreign keys when the schema omits it
+	if len(schema.GenerationOrder) == 0 {
+		order, err := DependencyOrder(schema.Tables)
+		if err != nil {
+			return nil, nil, err
+		}
+		schema.GenerationOrder = order
 	}
 
-	return &schema, nil
+	return &schema, list.Warnings(), nil
 }
 
-// LoadSchema loads and parses a schema from a file path.
+// LoadSchema loads and parses a schema from a file path. Files ending in
+// .yaml or .yml are read as YAML, anything else as JSON.
 // Returns the parsed Schema or an error if loading or parsing fails.
 func LoadSchema(path string) (*Schema, error) {
 	f, err := os.Open(path)
@@ -57,53 +97,101 @@ func LoadSchema(path string) (*Schema, error) {
 	}
 	defer f.Close()
 
-	schema, err := ParseSchema(f)
+	schema, err := Parse(f, FormatOf(path))
 	if err != nil {
+		var list ErrorList
+		var located *Error
+		if errors.As(err, &list) {
+			for _, e := range list {
+				e.File = path
+			}
+		} else if errors.As(err, &located) {
+			located.File = path
+		}
 		return nil, fmt.Errorf("LoadSchema: failed to parse schema from %q: %w", path, err)
 	}
 
 	return schema, nil
 }
 
-// ValidateSchema validates a schema's structure and semantic rules.
-// Implements multi-phase fail-fast validation for User Stories 2-6:
+// CheckSchemaFile validates a schema file like LoadSchema but returns every
+// diagnostic found, warnings included, located in the file: the syntax
+// error, the validation problems, or the warnings of a schema that loaded.
+// The schema is nil when any diagnostic is an error. err is set only when
+// the file cannot be read.
+func CheckSchemaFile(path string) (*Schema, ErrorList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read schema %q: %w", path, err)
+	}
+
+	schema, list, err := decode(data, FormatOf(path))
+	if err != nil {
+		var located *Error
+		if !errors.As(err, &list) {
+			if !errors.As(err, &located) {
+				return nil, nil, err
+			}
+			list = ErrorList{located}
+		}
+	}
+	for _, e := range list {
+		e.File = path
+	}
+	return schema, list, nil
+}
+
+// Validate checks a schema against every validation rule and returns all
+// problems found, errors and warnings alike, or nil if there are none.
+// Checks run in the spec's priority order (schema-level fields, tables,
+// columns, foreign keys, generation order), and each entry carries its rule
+// code and the JSON pointer of the offending value:
 // - User Story 2: Detect missing required fields
 // - User Story 3: Validate foreign key references
 // - User Story 4: Validate data types
-// - User Story 5: Validate generation order
+// - User Story 5: Validate generation order (when declared) and foreign key cycles
 // - User Story 6: Detect duplicate names
-//
-// Returns the first validation error encountered, or nil if valid.
+func Validate(s *Schema) ErrorList {
+	var c collector
+	c.schema(s)
+	return c.list
+}
+
+// ValidateSchema validates a schema's structure and semantic rules.
+// It is the fail-fast form of Validate: it returns the first error (warnings
+// are ignored) as an *Error, or nil if valid.
 func ValidateSchema(s *Schema) error {
+	return Validate(s).Err()
+}
+
+func (c *collector) schema(s *Schema) {
 	// User Story 2: Detect Missing Required Fields
 
 	// T030: Check schema name is required
 	if s.Name == "" {
-		return fmt.Errorf("schema name is required")
+		c.error("V-S001", "/name", fmt.Errorf("schema name is required"))
 	}
 
 	// T032: Check database_type is required and valid
 	if len(s.DatabaseType) == 0 {
-		return fmt.Errorf("database_type is required")
+		c.error("V-S005", "/database_type", fmt.Errorf("database_type is required"))
 	}
 
 	// Validate each database type is either "mysql" or "postgres"
-	for _, dbType := range s.DatabaseType {
+	var databases []string
+	for i, dbType := range s.DatabaseType {
 		if dbType != "mysql" && dbType != "postgres" {
-			return fmt.Errorf("invalid database_type %q: must be \"mysql\" or \"postgres\"", dbType)
+			c.error("V-S005", fmt.Sprintf("/database_type/%d", i), fmt.Errorf("invalid database_type %q: must be \"mysql\" or \"postgres\"", dbType))
+		}
+		if (dbType == "mysql" || dbType == "postgres") && !containsString(databases, dbType) {
+			databases = append(databases, dbType)
 		}
 	}
 
 	// T031: Check tables field is present (not nil)
 	// Note: Empty tables array is allowed for minimal schemas
 	if s.Tables == nil {
-		return fmt.Errorf("tables field is required")
-	}
-
-	// T033: Check generation_order field is present (not nil)
-	// Note: Empty generation_order array is allowed for minimal schemas
-	if s.GenerationOrder == nil {
-		return fmt.Errorf("generation_order is required")
+		c.error("V-S001", "/tables", fmt.Errorf("tables field is required"))
 	}
 
 	// T038: Integrate table and column validation
@@ -114,12 +202,30 @@ func ValidateSchema(s *Schema) error {
 	for i, table := range s.Tables {
 		// T077: Check for duplicate table names
 		if tableNames[table.Name] {
-			return fmt.Errorf("duplicate table name '%s'", table.Name)
+			c.error("V-T001", fmt.Sprintf("/tables/%d/name", i), fmt.Errorf("duplicate table name '%s'", table.Name))
 		}
 
 		// Validate each table
-		if err := ValidateTable(&table, i); err != nil {
-			return err
+		c.table(&table, i)
+
+		// V-C002: Lengths, precision and modifiers must suit every target
+		// database; types that failed to parse were reported by c.table
+		for j, col := range table.Columns {
+			dataType, err := ParseDataType(col.Type)
+			if err != nil {
+				continue
+			}
+			pointer := fmt.Sprintf("/tables/%d/columns/%d/type", i, j)
+			for _, dbType := range databases {
+				if err := dataType.ValidateFor(dbType); err != nil {
+					c.error("V-C002", pointer, fmt.Errorf("table '%s': column '%s': %w", table.Name, col.Name, err))
+				}
+			}
+
+			// jsonb is a PostgreSQL type; MySQL stores it as plain JSON
+			if dataType.Base == "jsonb" && containsString(databases, "mysql") {
+				c.warn("V-C002", pointer, fmt.Errorf("table '%s': column '%s': jsonb is PostgreSQL-specific and is created as json in MySQL", table.Name, col.Name))
+			}
 		}
 
 		// Track table names for foreign key validation and duplicate detection
@@ -127,29 +233,50 @@ func ValidateSchema(s *Schema) error {
 	}
 
 	// T048: User Story 3: Validate Foreign Key Integrity
-	if err := ValidateForeignKeys(s.Tables, tableNames); err != nil {
-		return err
-	}
+	c.foreignKeys(s.Tables, tableNames)
+
+	// V-R005: Relationships must describe the inline foreign keys
+	c.relationships(s)
+
+	// V-G002: Foreign keys must not form a cycle
+	c.error("V-G002", "", detectCycle(s.Tables))
 
 	// T069-T073: User Story 5: Validate Generation Order
-	if err := ValidateGenerationOrder(s.GenerationOrder, tableNames); err != nil {
-		return err
+	// generation_order is optional; when omitted it is derived from foreign keys
+	if len(s.GenerationOrder) > 0 {
+		before := len(c.list)
+		c.generationOrder(s.GenerationOrder, tableNames)
+
+		// V-G001: Parent tables must appear before child tables. Positions
+		// are only meaningful once the order lists every table exactly once.
+		if len(c.list) == before {
+			c.error("V-G001", "", ValidateDependencyOrder(s.Tables, s.GenerationOrder))
+		}
 	}
 
-	return nil
+	// V-V001: validation_rules must parse and name existing tables and columns
+	c.rules(s)
 }
 
 // ValidateTable validates a single table's structure and constraints.
 // Returns the first validation error encountered, or nil if valid.
 func ValidateTable(t *Table, tableIndex int) error {
+	var c collector
+	c.table(t, tableIndex)
+	return c.list.Err()
+}
+
+func (c *collector) table(t *Table, tableIndex int) {
+	pointer := fmt.Sprintf("/tables/%d", tableIndex)
+
 	// T034: Check table name is required
 	if t.Name == "" {
-		return fmt.Errorf("table %d: table name is required", tableIndex)
+		c.error("V-T001", pointer+"/name", fmt.Errorf("table %d: table name is required", tableIndex))
 	}
 
 	// T037: Check record_count is positive (> 0)
 	if t.RecordCount <= 0 {
-		return fmt.Errorf("table %d (%s): record_count must be greater than 0", tableIndex, t.Name)
+		c.error("V-T002", pointer+"/record_count", fmt.Errorf("table %d (%s): record_count must be greater than 0", tableIndex, t.Name))
 	}
 
 	// T036: Exactly one primary key per table (checked before empty columns)
@@ -161,17 +288,14 @@ func ValidateTable(t *Table, tableIndex int) error {
 		}
 	}
 
-	if pkCount == 0 {
-		return fmt.Errorf("table %d (%s): must have exactly one primary key", tableIndex, t.Name)
-	}
-
-	if pkCount > 1 {
-		return fmt.Errorf("table %d (%s): must have exactly one primary key, found %d", tableIndex, t.Name, pkCount)
-	}
-
-	// T034: Check columns array is non-empty (after primary key check)
-	if len(t.Columns) == 0 {
-		return fmt.Errorf("table %d (%s): columns are required", tableIndex, t.Name)
+	switch {
+	case pkCount == 0:
+		c.error("V-T004", pointer+"/columns", fmt.Errorf("table %d (%s): must have exactly one primary key", tableIndex, t.Name))
+	case pkCount > 1:
+		c.error("V-T004", pointer+"/columns", fmt.Errorf("table %d (%s): must have exactly one primary key, found %d", tableIndex, t.Name, pkCount))
+	case len(t.Columns) == 0:
+		// T034: Check columns array is non-empty (after primary key check)
+		c.error("V-T003", pointer+"/columns", fmt.Errorf("table %d (%s): columns are required", tableIndex, t.Name))
 	}
 
 	// T078: Detect duplicate column names (User Story 6)
@@ -181,95 +305,282 @@ func ValidateTable(t *Table, tableIndex int) error {
 	for j, col := range t.Columns {
 		// T078: Check for duplicate column names
 		if columnNames[col.Name] {
-			return fmt.Errorf("table '%s': duplicate column name '%s'", t.Name, col.Name)
+			c.error("V-C001", fmt.Sprintf("%s/columns/%d/name", pointer, j), fmt.Errorf("table '%s': duplicate column name '%s'", t.Name, col.Name))
 		}
 
-		if err := ValidateColumn(&col, tableIndex, t.Name, j); err != nil {
-			return err
-		}
+		c.column(&col, tableIndex, t.Name, j)
 
 		// Track column names for duplicate detection
 		columnNames[col.Name] = true
 	}
-
-	return nil
 }
 
 // ValidateColumn validates a single column's structure and constraints.
 // Returns the first validation error encountered, or nil if valid.
-func ValidateColumn(c *Column, tableIndex int, tableName string, colIndex int) error {
+func ValidateColumn(col *Column, tableIndex int, tableName string, colIndex int) error {
+	var c collector
+	c.column(col, tableIndex, tableName, colIndex)
+	return c.list.Err()
+}
+
+func (c *collector) column(col *Column, tableIndex int, tableName string, colIndex int) {
+	pointer := fmt.Sprintf("/tables/%d/columns/%d", tableIndex, colIndex)
+
 	// T035: Check column name is required
-	if c.Name == "" {
-		return fmt.Errorf("table %d (%s): column %d: column name is required", tableIndex, tableName, colIndex)
+	if col.Name == "" {
+		c.error("V-C001", pointer+"/name", fmt.Errorf("table %d (%s): column %d: column name is required", tableIndex, tableName, colIndex))
 	}
 
 	// T062: Validate data type first (User Story 4)
 	// This will catch both empty types and invalid types with consistent error messaging
-	if err := ValidateDataType(c.Type); err != nil {
-		return fmt.Errorf("table %d (%s): column %d (%s): %w", tableIndex, tableName, colIndex, c.Name, err)
+	if err := ValidateDataType(col.Type); err != nil {
+		c.error("V-C002", pointer+"/type", fmt.Errorf("table %d (%s): column %d (%s): %w", tableIndex, tableName, colIndex, col.Name, err))
 	}
 
-	return nil
+	// V-C003 to V-C005: generator and generator_params
+	c.generator(col, tableName, pointer)
 }
 
-// ValidateDataType validates that a data type is supported.
-// T060: Implements case-insensitive prefix matching against supported types list.
-// Handles parameterized types like varchar(255), decimal(10,2), enum('a','b','c').
-// Returns an error if the type is not supported, or nil if valid.
+// ValidateDataType validates that a data type is supported and well formed:
+// a known base type with arguments that suit it, such as varchar(255),
+// decimal(10,2) or enum('a','b','c'). See ParseDataType for the grammar.
+// Returns an error if the type is invalid, or nil if valid.
 func ValidateDataType(dataType string) error {
-	// Empty type is treated as invalid data type with "required" hint
-	// This satisfies both User Story 2 (required field) and User Story 4 (invalid type) tests
-	if dataType == "" {
-		return fmt.Errorf("invalid data type: column type is required")
-	}
-
-	// Normalize to lowercase for case-insensitive matching
-	normalized := strings.ToLower(dataType)
-
-	// Check if normalized type starts with any valid type (prefix matching)
-	for _, validType := range validTypes {
-		if strings.HasPrefix(normalized, validType) {
-			return nil // Valid type found
-		}
-	}
-
-	// Type not supported (User Story 4)
-	return fmt.Errorf("invalid data type %q: type not supported", dataType)
+	_, err := ParseDataType(dataType)
+	return err
 }
 
 // ValidateForeignKeys validates all foreign key references in the schema.
 // T046: Checks that foreign keys reference tables that exist in tableNames map.
 // Returns the first validation error encountered, or nil if all foreign keys are valid.
 func ValidateForeignKeys(tables []Table, tableNames map[string]bool) error {
-	for _, table := range tables {
-		for _, col := range table.Columns {
+	var c collector
+	c.foreignKeys(tables, tableNames)
+	return c.list.Err()
+}
+
+func (c *collector) foreignKeys(tables []Table, tableNames map[string]bool) {
+	deferred := DeferredForeignKeys(tables)
+
+	for i, table := range tables {
+		cardinalityColumn := ""
+		for j, col := range table.Columns {
 			// Skip columns without foreign keys
 			if col.ForeignKey == nil {
 				continue
 			}
 
 			fk := col.ForeignKey
+			pointer := fmt.Sprintf("/tables/%d/columns/%d/foreign_key", i, j)
+
+			// T047: Validate on_delete and on_update actions
+			onDelete := ValidateReferentialAction(fk.OnDelete, "on_delete", table.Name, col.Name)
+			onUpdate := ValidateReferentialAction(fk.OnUpdate, "on_update", table.Name, col.Name)
 
-			// T046: Check that referenced table exists
+			// T046: Check that referenced table exists; the remaining checks
+			// need it
 			if !tableNames[fk.Table] {
-				return fmt.Errorf("table '%s': column '%s': foreign key references table '%s' which does not exist in schema", table.Name, col.Name, fk.Table)
+				c.error("V-C006", pointer+"/table", fmt.Errorf("table '%s': column '%s': foreign key references table '%s' which does not exist in schema", table.Name, col.Name, fk.Table))
+				c.error("V-R003", pointer+"/on_delete", onDelete)
+				c.error("V-R003", pointer+"/on_update", onUpdate)
+				continue
+			}
+			c.error("V-R003", pointer+"/on_delete", onDelete)
+			c.error("V-R003", pointer+"/on_update", onUpdate)
+
+			// V-R004: SET NULL needs somewhere to put the NULL
+			if !col.Nullable {
+				for _, action := range []struct{ name, value string }{{"on_delete", fk.OnDelete}, {"on_update", fk.OnUpdate}} {
+					if strings.ToUpper(action.value) == "SET NULL" {
+						c.error("V-R004", pointer+"/"+action.name, fmt.Errorf("table '%s': column '%s': foreign key uses %s SET NULL but the column is not nullable (set nullable: true)",
+							table.Name, col.Name, action.name))
+					}
+				}
 			}
 
-			// T047: Validate on_delete action
-			if err := ValidateReferentialAction(fk.OnDelete, "on_delete", table.Name, col.Name); err != nil {
-				return err
+			// V-C006, V-R001, V-R002: the referenced column must exist, be a
+			// primary key or unique, and hold the same type of value
+			parent := tableByName(tables, fk.Table)
+			ref := parent.Column(fk.Column)
+			switch {
+			case ref == nil:
+				c.error("V-C006", pointer+"/column", fmt.Errorf("table '%s': column '%s': foreign key references column '%s.%s' which does not exist in schema",
+					table.Name, col.Name, fk.Table, fk.Column))
+			case !ref.PrimaryKey && !ref.Unique && !parent.uniqueIndex(ref.Name):
+				c.error("V-R001", pointer+"/column", fmt.Errorf("table '%s': column '%s': foreign key must reference a primary key or unique column, but '%s.%s' is neither",
+					table.Name, col.Name, fk.Table, fk.Column))
+			default:
+				c.error("V-R002", fmt.Sprintf("/tables/%d/columns/%d/type", i, j), ValidateForeignKeyType(&col, ref, table.Name, fk.Table))
 			}
 
-			// T047: Validate on_update action
-			if err := ValidateReferentialAction(fk.OnUpdate, "on_update", table.Name, col.Name); err != nil {
-				return err
+			if fk.Hierarchy != nil {
+				c.error("V-C006", pointer+"/hierarchy", ValidateHierarchy(fk.Hierarchy, table.Name, col.Name, fk.Table))
+			}
+
+			if fk.Cardinality == nil {
+				continue
+			}
+
+			// Deferred keys are filled in after every table exists, so they
+			// cannot size the table they belong to
+			if deferred[table.Name][col.Name] {
+				c.error("V-C006", pointer+"/cardinality", fmt.Errorf("table '%s': column '%s': cardinality is not supported on a self-referencing or circular foreign key",
+					table.Name, col.Name))
+				continue
+			}
+
+			// Cardinality sets the table's row count, so only one foreign key may drive it
+			if cardinalityColumn != "" {
+				c.error("V-C006", pointer+"/cardinality", fmt.Errorf("table '%s': column '%s': cardinality is already set on column '%s' (only one foreign key per table may declare cardinality)",
+					table.Name, col.Name, cardinalityColumn))
+				continue
 			}
+			cardinalityColumn = col.Name
+
+			c.error("V-C006", pointer+"/cardinality", ValidateCardinality(fk.Cardinality, tables, fk.Table, table.Name, col.Name))
 		}
 	}
+}
 
+// ValidateHierarchy validates a foreign key's hierarchy settings.
+// Checks that the foreign key references its own table and that the limits
+// are not negative. Returns an error with table and column context, or nil if valid.
+func ValidateHierarchy(h *Hierarchy, tableName, colName, parentTable string) error {
+	if parentTable != tableName {
+		return fmt.Errorf("table '%s': column '%s': hierarchy requires a self-referencing foreign key, but it references '%s'",
+			tableName, colName, parentTable)
+	}
+	if h.Roots < 0 || h.MaxDepth < 0 || h.MaxChildren < 0 {
+		return fmt.Errorf("table '%s': column '%s': hierarchy roots, max_depth and max_children must not be negative", tableName, colName)
+	}
 	return nil
 }
 
+// ValidateCardinality validates a foreign key's child-per-parent cardinality.
+// Checks that min and max are non-negative and ordered, that the distribution
+// is a known type, and that count_column exists in the referenced table.
+// Returns an error with table and column context, or nil if valid.
+func ValidateCardinality(c *Cardinality, tables []Table, parentTable, tableName, colName string) error {
+	if c.Min < 0 || c.Max < 0 {
+		return fmt.Errorf("table '%s': column '%s': cardinality min and max must not be negative", tableName, colName)
+	}
+
+	if c.CountColumn != "" {
+		for _, t := range tables {
+			if t.Name != parentTable {
+				continue
+			}
+			for _, pc := range t.Columns {
+				if pc.Name == c.CountColumn {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("table '%s': column '%s': cardinality count_column '%s' does not exist in table '%s'",
+			tableName, colName, c.CountColumn, parentTable)
+	}
+
+	if c.Max == 0 {
+		return fmt.Errorf("table '%s': column '%s': cardinality max is required (must be at least 1)", tableName, colName)
+	}
+	if c.Min > c.Max {
+		return fmt.Errorf("table '%s': column '%s': cardinality min (%d) must not be greater than max (%d)",
+			tableName, colName, c.Min, c.Max)
+	}
+
+	if c.Distribution != "" {
+		for _, known := range distribution.Types {
+			if c.Distribution == known {
+				return nil
+			}
+		}
+		return fmt.Errorf("table '%s': column '%s': unknown cardinality distribution '%s': must be one of: %s",
+			tableName, colName, c.Distribution, strings.Join(distribution.Types, ", "))
+	}
+
+	return nil
+}
+
+// integerTypes must match exactly across a foreign key: MySQL rejects a
+// constraint between integers of different sizes.
+var integerTypes = map[string]bool{"tinyint": true, "smallint": true, "int": true, "bigint": true}
+
+// stringTypes may reference one another as long as the child column is long
+// enough for the parent's values.
+var stringTypes = map[string]bool{"char": true, "varchar": true, "text": true}
+
+// uuidLength is the length of the values the uuid generator produces.
+const uuidLength = 36
+
+// ValidateForeignKeyType validates that a foreign key column can hold the
+// values of the column it references (V-R002). Integer and other types must
+// match exactly, ignoring case and spacing; string types may mix char,
+// varchar and text, but the child must be at least as long as the parent
+// (and as a generated UUID, when the parent uses the uuid generator).
+// Returns an error with table and column context, or nil if compatible.
+func ValidateForeignKeyType(col, ref *Column, tableName, parentTable string) error {
+	child, parent := col.DataType(), ref.DataType()
+
+	mismatch := fmt.Errorf("table '%s': column '%s': foreign key type '%s' does not match referenced column type '%s' in '%s.%s'",
+		tableName, col.Name, col.Type, ref.Type, parentTable, ref.Name)
+
+	if !stringTypes[child.Base] || !stringTypes[parent.Base] || child.Array || parent.Array {
+		if !sameType(child, parent) {
+			return mismatch
+		}
+		return nil
+	}
+
+	// text has no declared length and holds any string
+	if child.Base == "text" {
+		return nil
+	}
+	need := parent.Length
+	if ref.Generator == "uuid" {
+		need = max(need, uuidLength)
+	}
+	if parent.Base == "text" && need == 0 {
+		return fmt.Errorf("table '%s': column '%s': foreign key type '%s' may be too short for referenced column type 'text' in '%s.%s'",
+			tableName, col.Name, col.Type, parentTable, ref.Name)
+	}
+	if child.Length < need {
+		return fmt.Errorf("table '%s': column '%s': foreign key type '%s' is too short for the values of '%s.%s' (needs length %d)",
+			tableName, col.Name, col.Type, parentTable, ref.Name, need)
+	}
+	return nil
+}
+
+// sameType reports whether two non-string types hold the same values. An
+// integer's display width does not change its range, so int(11) matches
+// int; everything else must be identical.
+func sameType(a, b DataType) bool {
+	if integerTypes[a.Base] {
+		return a.Base == b.Base && a.Unsigned == b.Unsigned && a.Array == b.Array
+	}
+	return a.String() == b.String()
+}
+
+// tableByName returns the table with the given name, or nil.
+func tableByName(tables []Table, name string) *Table {
+	for i := range tables {
+		if tables[i].Name == name {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+// uniqueIndex reports whether the table declares a unique index on exactly
+// the named column.
+func (t *Table) uniqueIndex(column string) bool {
+	for _, idx := range t.Indexes {
+		if idx.Unique && len(idx.Columns) == 1 && idx.Columns[0] == column {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateReferentialAction validates a foreign key referential action.
 // T047: Checks that action is one of: CASCADE, SET NULL, RESTRICT (case-sensitive).
 // Returns an error if the action is invalid, or nil if valid.
@@ -302,36 +613,41 @@ func ValidateReferentialAction(action string, actionType string, tableName strin
 //
 // Returns the first validation error encountered, or nil if valid.
 func ValidateGenerationOrder(generationOrder []string, tableNames map[string]bool) error {
-	// T073: Empty generation_order is only valid if there are no tables
-	if len(generationOrder) == 0 && len(tableNames) > 0 {
-		// Find first missing table for error message
-		for tableName := range tableNames {
-			return fmt.Errorf("generation_order is missing table '%s' (and possibly others)", tableName)
-		}
-	}
+	var c collector
+	c.generationOrder(generationOrder, tableNames)
+	return c.list.Err()
+}
 
+func (c *collector) generationOrder(generationOrder []string, tableNames map[string]bool) {
 	// T070: Build set of tables in generation_order to detect duplicates
 	orderSet := make(map[string]bool)
 
-	for _, tableName := range generationOrder {
+	for i, tableName := range generationOrder {
+		pointer := fmt.Sprintf("/generation_order/%d", i)
+
 		// T070: Check for duplicates
 		if orderSet[tableName] {
-			return fmt.Errorf("generation_order contains duplicate table '%s'", tableName)
+			c.error("V-S007", pointer, fmt.Errorf("generation_order contains duplicate table '%s'", tableName))
+			continue
 		}
 		orderSet[tableName] = true
 
 		// T072: Check that table exists in schema
 		if !tableNames[tableName] {
-			return fmt.Errorf("generation_order references table '%s' which does not exist in schema", tableName)
+			c.error("V-S007", pointer, fmt.Errorf("generation_order references table '%s' which does not exist in schema", tableName))
 		}
 	}
 
-	// T071: Check all tables are included in generation_order
+	// T071, T073: Check all tables are included in generation_order, in a
+	// stable order
+	missing := make([]string, 0, len(tableNames))
 	for tableName := range tableNames {
 		if !orderSet[tableName] {
-			return fmt.Errorf("generation_order is missing table '%s'", tableName)
+			missing = append(missing, tableName)
 		}
 	}
-
-	return nil
+	sort.Strings(missing)
+	for _, tableName := range missing {
+		c.error("V-S007", "/generation_order", fmt.Errorf("generation_order is missing table '%s'", tableName))
+	}
 }
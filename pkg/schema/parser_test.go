@@ -196,7 +196,21 @@ func TestParseSchema_ValidJSON(t *testing.T) {
 				"author": "Test Author",
 				"version": "1.0.0",
 				"database_type": ["mysql"],
-				"tables": [],
+				"tables": [
+					{
+						"name": "users",
+						"record_count": 10,
+						"columns": [{"name": "id", "type": "int", "primary_key": true}]
+					},
+					{
+						"name": "posts",
+						"record_count": 20,
+						"columns": [
+							{"name": "id", "type": "int", "primary_key": true},
+							{"name": "user_id", "type": "int", "foreign_key": {"table": "users", "column": "id", "on_delete": "CASCADE", "on_update": "CASCADE"}}
+						]
+					}
+				],
 				"relationships": [
 					{
 						"from_table": "posts",
@@ -229,7 +243,16 @@ func TestParseSchema_ValidJSON(t *testing.T) {
 				"author": "Test Author",
 				"version": "1.0.0",
 				"database_type": ["mysql"],
-				"tables": [],
+				"tables": [
+					{
+						"name": "users",
+						"record_count": 10,
+						"columns": [
+							{"name": "id", "type": "int", "primary_key": true},
+							{"name": "email", "type": "varchar(255)"}
+						]
+					}
+				],
 				"validation_rules": [
 					{
 						"rule": "users.email UNIQUE",
@@ -387,7 +410,7 @@ func TestLoadSchema_ValidFile(t *testing.T) {
 
 	// Validate metadata
 	assert.Equal(t, "fintech", schema.Metadata.Industry)
-	assert.Equal(t, 4950, schema.Metadata.TotalRecords)
+	assert.Equal(t, 16200, schema.Metadata.TotalRecords)
 	assert.Equal(t, 1, schema.Metadata.ComplexityTier)
 
 	// Validate tables
@@ -549,6 +572,7 @@ func TestParseMissingDatabaseType(t *testing.T) {
 }
 
 func TestParseMissingGenerationOrder(t *testing.T) {
+	// generation_order is optional: when omitted it is derived from foreign keys
 	input := `{
 		"schema_version": "1.0",
 		"name": "test-schema",
@@ -556,16 +580,28 @@ func TestParseMissingGenerationOrder(t *testing.T) {
 		"author": "Test Author",
 		"version": "1.0.0",
 		"database_type": ["mysql"],
-		"tables": []
+		"tables": [
+			{
+				"name": "loans",
+				"record_count": 10,
+				"columns": [
+					{"name": "id", "type": "int", "primary_key": true},
+					{"name": "borrower_id", "type": "int", "foreign_key": {"table": "borrowers", "column": "id", "on_delete": "CASCADE", "on_update": "CASCADE"}}
+				]
+			},
+			{
+				"name": "borrowers",
+				"record_count": 5,
+				"columns": [{"name": "id", "type": "int", "primary_key": true}]
+			}
+		]
 	}`
 
 	reader := strings.NewReader(input)
 	schema, err := ParseSchema(reader)
 
-	require.Error(t, err, "ParseSchema should fail when generation_order is missing")
-	assert.Nil(t, schema)
-	assert.Contains(t, err.Error(), "generation_order")
-	assert.Contains(t, err.Error(), "required")
+	require.NoError(t, err, "ParseSchema should derive a missing generation_order")
+	assert.Equal(t, []string{"borrowers", "loans"}, schema.GenerationOrder)
 }
 
 func TestParseTableMissingName(t *testing.T) {
@@ -1243,7 +1279,7 @@ func TestParseForeignKeyValidActions(t *testing.T) {
 							{
 								"name": "user_id",
 								"type": "int",
-								"nullable": false,
+								"nullable": true,
 								"foreign_key": {
 									"table": "users",
 									"column": "id",
@@ -1373,11 +1409,176 @@ func TestParseForeignKeyMultipleReferences(t *testing.T) {
 	assert.Equal(t, "RESTRICT", categoryIDCol.ForeignKey.OnUpdate)
 }
 
+func TestValidateForeignKeys_Targets(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   Column
+		child    Column
+		onDelete string
+		errorMsg string
+		code     string
+	}{
+		{
+			name:   "matching primary key",
+			parent: Column{Name: "id", Type: "int", PrimaryKey: true},
+			child:  Column{Name: "ref", Type: "INT"},
+		},
+		{
+			name:   "unique column of a longer string type",
+			parent: Column{Name: "id", Type: "char(36)", Unique: true, Generator: "uuid"},
+			child:  Column{Name: "ref", Type: "varchar(36)"},
+		},
+		{
+			name:     "missing column",
+			parent:   Column{Name: "key", Type: "int"},
+			child:    Column{Name: "ref", Type: "int"},
+			errorMsg: "foreign key references column 'parents.id' which does not exist in schema",
+			code:     "V-C006",
+		},
+		{
+			name:     "neither primary key nor unique",
+			parent:   Column{Name: "id", Type: "int"},
+			child:    Column{Name: "ref", Type: "int"},
+			errorMsg: "foreign key must reference a primary key or unique column, but 'parents.id' is neither",
+			code:     "V-R001",
+		},
+		{
+			name:     "int referencing bigint",
+			parent:   Column{Name: "id", Type: "bigint", PrimaryKey: true},
+			child:    Column{Name: "ref", Type: "int"},
+			errorMsg: "foreign key type 'int' does not match referenced column type 'bigint' in 'parents.id'",
+			code:     "V-R002",
+		},
+		{
+			name:     "string too short for generated UUIDs",
+			parent:   Column{Name: "id", Type: "varchar(64)", PrimaryKey: true, Generator: "uuid"},
+			child:    Column{Name: "ref", Type: "char(32)"},
+			errorMsg: "foreign key type 'char(32)' is too short for the values of 'parents.id' (needs length 64)",
+			code:     "V-R002",
+		},
+		{
+			name:     "SET NULL on a NOT NULL column",
+			parent:   Column{Name: "id", Type: "int", PrimaryKey: true},
+			child:    Column{Name: "ref", Type: "int"},
+			onDelete: "SET NULL",
+			errorMsg: "foreign key uses on_delete SET NULL but the column is not nullable (set nullable: true)",
+			code:     "V-R004",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parents := Table{Name: "parents", Columns: []Column{tt.parent}}
+			if !tt.parent.PrimaryKey {
+				parents.Columns = append(parents.Columns, Column{Name: "pk", Type: "int", PrimaryKey: true})
+			}
+			child := tt.child
+			child.ForeignKey = &ForeignKey{Table: "parents", Column: "id", OnDelete: "CASCADE", OnUpdate: "CASCADE"}
+			if tt.onDelete != "" {
+				child.ForeignKey.OnDelete = tt.onDelete
+			}
+			children := Table{Name: "children", Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}, child}}
+
+			err := ValidateForeignKeys([]Table{parents, children}, map[string]bool{"parents": true, "children": true})
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "table 'children': column 'ref': "+tt.errorMsg)
+			assert.ErrorIs(t, err, &Error{Code: tt.code})
+		})
+	}
+}
+
 // ============================================================================
 // User Story 4: Validate Data Types (T051-T059) - TDD RED Phase
 // These tests should FAIL initially until data type validation is implemented
 // ============================================================================
 
+func TestParseForeignKeyCardinality(t *testing.T) {
+	// Test that cardinality on a foreign key parses and is validated
+	schemaWith := func(cardinality string) string {
+		return `{
+		"schema_version": "1.0",
+		"name": "test-schema",
+		"description": "Test schema",
+		"author": "Test Author",
+		"version": "1.0.0",
+		"database_type": ["mysql"],
+		"tables": [
+			{
+				"name": "loans",
+				"record_count": 100,
+				"columns": [
+					{"name": "id", "type": "int", "primary_key": true},
+					{"name": "term_months", "type": "int"}
+				]
+			},
+			{
+				"name": "payments",
+				"record_count": 100,
+				"columns": [
+					{"name": "id", "type": "int", "primary_key": true},
+					{
+						"name": "loan_id",
+						"type": "int",
+						"foreign_key": {
+							"table": "loans",
+							"column": "id",
+							"on_delete": "CASCADE",
+							"on_update": "CASCADE",
+							"cardinality": ` + cardinality + `
+						}
+					}
+				]
+			}
+		],
+		"generation_order": ["loans", "payments"]
+	}`
+	}
+
+	t.Run("valid range with distribution", func(t *testing.T) {
+		schema, err := ParseSchema(strings.NewReader(schemaWith(`{"min": 0, "max": 10, "distribution": "zipf", "params": {"s": 1.5}}`)))
+		require.NoError(t, err)
+
+		c := schema.Tables[1].Columns[1].ForeignKey.Cardinality
+		require.NotNil(t, c)
+		assert.Equal(t, 0, c.Min)
+		assert.Equal(t, 10, c.Max)
+		assert.Equal(t, "zipf", c.Distribution)
+		assert.Equal(t, 1.5, c.Params["s"])
+	})
+
+	t.Run("valid count_column", func(t *testing.T) {
+		schema, err := ParseSchema(strings.NewReader(schemaWith(`{"count_column": "term_months"}`)))
+		require.NoError(t, err)
+		assert.Equal(t, "term_months", schema.Tables[1].Columns[1].ForeignKey.Cardinality.CountColumn)
+	})
+
+	errorCases := []struct {
+		name        string
+		cardinality string
+		errMsg      string
+	}{
+		{"missing max", `{"min": 1}`, "cardinality max is required"},
+		{"min greater than max", `{"min": 5, "max": 2}`, "cardinality min (5) must not be greater than max (2)"},
+		{"negative min", `{"min": -1, "max": 2}`, "must not be negative"},
+		{"unknown distribution", `{"min": 0, "max": 2, "distribution": "pareto"}`, "unknown cardinality distribution 'pareto'"},
+		{"unknown count_column", `{"count_column": "term"}`, "count_column 'term' does not exist in table 'loans'"},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := ParseSchema(strings.NewReader(schemaWith(tc.cardinality)))
+			require.Error(t, err)
+			assert.Nil(t, schema)
+			assert.Contains(t, err.Error(), "table 'payments': column 'loan_id'")
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
 func TestValidateDataTypeInt(t *testing.T) {
 	// Test that int, bigint, smallint, tinyint are valid
 	tests := []struct {
@@ -2011,7 +2212,7 @@ func TestValidateGenerationOrderNonExistentTable(t *testing.T) {
 }
 
 func TestValidateGenerationOrderEmpty(t *testing.T) {
-	// Test that empty generation_order with non-empty tables produces an error
+	// Test that an empty generation_order is derived rather than rejected
 	input := `{
 		"schema_version": "1.0",
 		"name": "test-schema",
@@ -2035,11 +2236,13 @@ func TestValidateGenerationOrderEmpty(t *testing.T) {
 	reader := strings.NewReader(input)
 	schema, err := ParseSchema(reader)
 
-	require.Error(t, err, "ParseSchema should fail when generation_order is empty but tables exist")
-	assert.Nil(t, schema)
-	assert.Contains(t, err.Error(), "generation_order")
-	assert.Contains(t, err.Error(), "missing")
-	assert.Contains(t, err.Error(), "users")
+	require.NoError(t, err, "ParseSchema should derive an empty generation_order")
+	assert.Equal(t, []string{"users"}, schema.GenerationOrder)
+
+	// Called directly, ValidateGenerationOrder still rejects an incomplete order
+	err = ValidateGenerationOrder([]string{}, map[string]bool{"users": true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing table 'users'")
 }
 
 // ============================================================================
@@ -2296,16 +2499,6 @@ func TestParseNullRequiredFields(t *testing.T) {
 				"generation_order": []
 			}`,
 		},
-		{
-			name: "null generation_order field",
-			input: `{
-				"schema_version": "1.0",
-				"name": "test-schema",
-				"database_type": ["mysql"],
-				"tables": [],
-				"generation_order": null
-			}`,
-		},
 	}
 
 	for _, tt := range tests {
@@ -2357,3 +2550,51 @@ func TestLoadSchemaNonExistentFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSchemaFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("warnings of a valid schema", func(t *testing.T) {
+		path := write("events.json", `{
+  "name": "events",
+  "database_type": ["mysql"],
+  "tables": [{"name": "events", "record_count": 1, "columns": [
+    {"name": "id", "type": "int", "primary_key": true},
+    {"name": "payload", "type": "jsonb"}
+  ]}]
+}`)
+		s, list, err := CheckSchemaFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		require.Len(t, list, 1)
+		assert.Equal(t, SeverityWarning, list[0].Severity)
+		assert.Equal(t, path, list[0].File)
+		assert.Equal(t, 6, list[0].Line)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		path := write("broken.yaml", "name: [\n")
+		s, list, err := CheckSchemaFile(path)
+		require.NoError(t, err)
+		assert.Nil(t, s)
+		require.Len(t, list, 1)
+		assert.Equal(t, path, list[0].File)
+		assert.Equal(t, 1, list[0].Line)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		path := write("empty.json", `{"tables": []}`)
+		s, list, err := CheckSchemaFile(path)
+		require.NoError(t, err)
+		assert.Nil(t, s)
+		assert.Len(t, list.Errors(), 2)
+	})
+
+	_, _, err := CheckSchemaFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// Relationship types accepted in relationship_type.
+const (
+	OneToOne   = "one_to_one"
+	OneToMany  = "one_to_many"
+	ManyToOne  = "many_to_one"
+	ManyToMany = "many_to_many"
+)
+
+// ValidRelationshipTypes lists the accepted relationship_type values.
+var ValidRelationshipTypes = []string{OneToOne, OneToMany, ManyToOne, ManyToMany}
+
+// foreignKeyOf returns the foreign key declared on table.column, or nil.
+func (s *Schema) foreignKeyOf(table, column string) *ForeignKey {
+	t := s.Table(table)
+	if t == nil {
+		return nil
+	}
+	col := t.Column(column)
+	if col == nil {
+		return nil
+	}
+	return col.ForeignKey
+}
+
+// describes reports whether rel describes the foreign key on table.column,
+// written either from the child's side (from the foreign key column) or
+// from the parent's side (from the referenced column).
+func (rel Relationship) describes(table, column string, fk *ForeignKey) bool {
+	childSide := rel.FromTable == table && rel.FromColumn == column &&
+		rel.ToTable == fk.Table && rel.ToColumn == fk.Column
+	parentSide := rel.ToTable == table && rel.ToColumn == column &&
+		rel.FromTable == fk.Table && rel.FromColumn == fk.Column
+	return childSide || parentSide
+}
+
+// RelationshipFor returns the declared relationship describing the foreign
+// key on table.column, from either side. ok is false when the column has no
+// foreign key or no relationship describes it.
+func (s *Schema) RelationshipFor(table, column string) (rel Relationship, ok bool) {
+	fk := s.foreignKeyOf(table, column)
+	if fk == nil {
+		return Relationship{}, false
+	}
+	for _, rel := range s.Relationships {
+		if rel.describes(table, column, fk) {
+			return rel, true
+		}
+	}
+	return Relationship{}, false
+}
+
+// AllRelationships returns the declared relationships followed by one
+// synthesized entry for each foreign key that none of them describes, so
+// documentation built from the list covers every foreign key. Synthesized
+// entries are written from the child's side: one_to_one when the foreign
+// key column is unique, many_to_one otherwise.
+func (s *Schema) AllRelationships() []Relationship {
+	all := append([]Relationship(nil), s.Relationships...)
+	for _, t := range s.Tables {
+		for _, col := range t.Columns {
+			if col.ForeignKey == nil {
+				continue
+			}
+			if _, ok := s.RelationshipFor(t.Name, col.Name); ok {
+				continue
+			}
+			kind := ManyToOne
+			if col.Unique {
+				kind = OneToOne
+			}
+			all = append(all, Relationship{
+				FromTable:        t.Name,
+				FromColumn:       col.Name,
+				ToTable:          col.ForeignKey.Table,
+				ToColumn:         col.ForeignKey.Column,
+				RelationshipType: kind,
+				Description:      fmt.Sprintf("%s.%s references %s.%s", t.Name, col.Name, col.ForeignKey.Table, col.ForeignKey.Column),
+			})
+		}
+	}
+	return all
+}
+
+// relationships cross-checks the relationships array against the inline
+// foreign keys (V-R005). Each entry must have a known relationship_type,
+// name existing columns, and describe exactly one foreign key, from either
+// side, without contradicting it.
+func (c *collector) relationships(s *Schema) {
+	described := make(map[string]int)
+
+	for i, rel := range s.Relationships {
+		pointer := fmt.Sprintf("/relationships/%d", i)
+		label := fmt.Sprintf("relationship %d (%s.%s -> %s.%s)", i, rel.FromTable, rel.FromColumn, rel.ToTable, rel.ToColumn)
+
+		if !containsString(ValidRelationshipTypes, rel.RelationshipType) {
+			c.error("V-R005", pointer+"/relationship_type", fmt.Errorf("%s: invalid relationship_type %q: must be one of: one_to_one, one_to_many, many_to_one, many_to_many",
+				label, rel.RelationshipType))
+		}
+
+		if err := relationshipColumns(s, rel); err != nil {
+			c.error("V-R005", pointer, fmt.Errorf("%s: %w", label, err))
+			continue
+		}
+
+		// The foreign key may sit on either end
+		table, column := rel.FromTable, rel.FromColumn
+		fk := s.foreignKeyOf(table, column)
+		if fk == nil || !rel.describes(table, column, fk) {
+			table, column = rel.ToTable, rel.ToColumn
+			fk = s.foreignKeyOf(table, column)
+		}
+		if fk == nil || !rel.describes(table, column, fk) {
+			c.error("V-R005", pointer, fmt.Errorf("%s: %s", label, missingForeignKey(s, rel)))
+			continue
+		}
+
+		key := table + "." + column
+		if first, seen := described[key]; seen {
+			c.error("V-R005", pointer, fmt.Errorf("%s: foreign key '%s' is already described by relationship %d", label, key, first))
+			continue
+		}
+		described[key] = i
+
+		switch rel.RelationshipType {
+		case ManyToMany:
+			c.error("V-R005", pointer+"/relationship_type", fmt.Errorf("%s: a single foreign key cannot be many_to_many; describe the two foreign keys of the junction table instead", label))
+		case OneToOne:
+			if card := fk.Cardinality; card != nil && (card.CountColumn != "" || card.Max > 1) {
+				c.error("V-R005", pointer+"/relationship_type", fmt.Errorf("%s: relationship is one_to_one but the cardinality of '%s' allows more than one child per parent", label, key))
+			}
+		}
+	}
+}
+
+// relationshipColumns reports the first table or column a relationship
+// names that the schema does not define.
+func relationshipColumns(s *Schema, rel Relationship) error {
+	for _, ref := range []ColumnRef{{rel.FromTable, rel.FromColumn}, {rel.ToTable, rel.ToColumn}} {
+		t := s.Table(ref.Table)
+		if t == nil {
+			return fmt.Errorf("table '%s' does not exist in schema", ref.Table)
+		}
+		if t.Column(ref.Column) == nil {
+			return fmt.Errorf("column '%s' does not exist in schema", ref)
+		}
+	}
+	return nil
+}
+
+// missingForeignKey explains why no foreign key matches rel, pointing at a
+// foreign key on either column that references something else.
+func missingForeignKey(s *Schema, rel Relationship) string {
+	for _, ref := range []ColumnRef{{rel.FromTable, rel.FromColumn}, {rel.ToTable, rel.ToColumn}} {
+		if fk := s.foreignKeyOf(ref.Table, ref.Column); fk != nil {
+			return fmt.Sprintf("foreign key on '%s' references '%s.%s', not the other end of the relationship", ref, fk.Table, fk.Column)
+		}
+	}
+	return fmt.Sprintf("neither '%s.%s' nor '%s.%s' has a foreign_key object", rel.FromTable, rel.FromColumn, rel.ToTable, rel.ToColumn)
+}
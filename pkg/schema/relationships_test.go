@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relationshipSchema has loans -> borrowers and a one-to-one
+// loan_terms -> loans foreign key; tests attach the relationships under test.
+func relationshipSchema(rels ...Relationship) *Schema {
+	fk := func(table string) *ForeignKey {
+		return &ForeignKey{Table: table, Column: "id", OnDelete: "CASCADE", OnUpdate: "CASCADE"}
+	}
+	return &Schema{
+		Name:         "relationships",
+		DatabaseType: []string{"postgres"},
+		Tables: []Table{
+			{Name: "borrowers", RecordCount: 5, Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "name", Type: "varchar(50)"},
+			}},
+			{Name: "loans", RecordCount: 5, Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "borrower_id", Type: "int", ForeignKey: fk("borrowers")},
+			}},
+			{Name: "loan_terms", RecordCount: 5, Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "loan_id", Type: "int", Unique: true, ForeignKey: fk("loans")},
+			}},
+		},
+		Relationships: rels,
+	}
+}
+
+func TestValidate_Relationships(t *testing.T) {
+	rel := func(from, fromCol, to, toCol, kind string) Relationship {
+		return Relationship{FromTable: from, FromColumn: fromCol, ToTable: to, ToColumn: toCol, RelationshipType: kind}
+	}
+
+	t.Run("valid from either side", func(t *testing.T) {
+		s := relationshipSchema(
+			rel("loans", "borrower_id", "borrowers", "id", ManyToOne),
+			rel("loans", "id", "loan_terms", "loan_id", OneToOne),
+		)
+		assert.Empty(t, Validate(s))
+	})
+
+	tests := []struct {
+		name     string
+		rel      Relationship
+		pointer  string
+		errorMsg string
+	}{
+		{"unknown type", rel("loans", "borrower_id", "borrowers", "id", "belongs_to"),
+			"/relationships/0/relationship_type", `invalid relationship_type "belongs_to": must be one of: one_to_one, one_to_many, many_to_one, many_to_many`},
+		{"missing table", rel("payments", "loan_id", "loans", "id", ManyToOne),
+			"/relationships/0", "table 'payments' does not exist in schema"},
+		{"missing column", rel("loans", "borrower_id", "borrowers", "uuid", ManyToOne),
+			"/relationships/0", "column 'borrowers.uuid' does not exist in schema"},
+		{"no foreign key", rel("borrowers", "name", "loans", "id", ManyToOne),
+			"/relationships/0", "neither 'borrowers.name' nor 'loans.id' has a foreign_key object"},
+		{"contradicts foreign key", rel("loans", "borrower_id", "loan_terms", "id", ManyToOne),
+			"/relationships/0", "foreign key on 'loans.borrower_id' references 'borrowers.id', not the other end of the relationship"},
+		{"many to many", rel("loans", "borrower_id", "borrowers", "id", ManyToMany),
+			"/relationships/0/relationship_type", "a single foreign key cannot be many_to_many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := Validate(relationshipSchema(tt.rel))
+			require.Len(t, list, 1)
+			assert.Equal(t, "V-R005", list[0].Code)
+			assert.Equal(t, tt.pointer, list[0].Pointer)
+			assert.Contains(t, list[0].Error(), tt.errorMsg)
+		})
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		list := Validate(relationshipSchema(
+			rel("loans", "borrower_id", "borrowers", "id", ManyToOne),
+			rel("borrowers", "id", "loans", "borrower_id", OneToMany),
+		))
+		require.Len(t, list, 1)
+		assert.Equal(t, "/relationships/1", list[0].Pointer)
+		assert.Contains(t, list[0].Error(), "foreign key 'loans.borrower_id' is already described by relationship 0")
+	})
+
+	t.Run("one to one with cardinality", func(t *testing.T) {
+		s := relationshipSchema(rel("loans", "borrower_id", "borrowers", "id", OneToOne))
+		s.Tables[1].Columns[1].ForeignKey.Cardinality = &Cardinality{Min: 0, Max: 3}
+		list := Validate(s)
+		require.Len(t, list, 1)
+		assert.Contains(t, list[0].Error(), "relationship is one_to_one but the cardinality of 'loans.borrower_id' allows more than one child per parent")
+	})
+}
+
+func TestAllRelationships(t *testing.T) {
+	declared := Relationship{FromTable: "borrowers", FromColumn: "id", ToTable: "loans", ToColumn: "borrower_id",
+		RelationshipType: OneToMany, Description: "A borrower can have many loans"}
+	s := relationshipSchema(declared)
+
+	rel, ok := s.RelationshipFor("loans", "borrower_id")
+	require.True(t, ok)
+	assert.Equal(t, declared, rel)
+	_, ok = s.RelationshipFor("loan_terms", "loan_id")
+	assert.False(t, ok)
+
+	assert.Equal(t, []Relationship{
+		declared,
+		{FromTable: "loan_terms", FromColumn: "loan_id", ToTable: "loans", ToColumn: "id",
+			RelationshipType: OneToOne, Description: "loan_terms.loan_id references loans.id"},
+	}, s.AllRelationships())
+	assert.Len(t, s.Relationships, 1)
+}
@@ -0,0 +1,448 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CheckKind identifies what a validation rule asserts.
+type CheckKind string
+
+const (
+	// CheckReferences: every non-NULL value of Column appears in Ref,
+	// e.g. "loans.borrower_id REFERENCES borrowers.id".
+	CheckReferences CheckKind = "references"
+
+	// CheckUnique: no non-NULL value of Column appears twice,
+	// e.g. "borrowers.email UNIQUE".
+	CheckUnique CheckKind = "unique"
+
+	// CheckNotNull: Column is never NULL, e.g. "loans.amount NOT NULL".
+	CheckNotNull CheckKind = "not_null"
+
+	// CheckCompare: every row satisfies a comparison of Column with a
+	// literal or with another column of the same table, e.g.
+	// "borrowers.credit_score BETWEEN 300 AND 850" or
+	// "loans.end_date > loans.start_date". Rows where either side is NULL
+	// are not checked, as in SQL.
+	CheckCompare CheckKind = "compare"
+
+	// CheckRowCount: a table's row count, or the ratio of two tables' row
+	// counts, satisfies a comparison with numbers, e.g.
+	// "COUNT(loans) / COUNT(borrowers) BETWEEN 1 AND 3".
+	CheckRowCount CheckKind = "row_count"
+)
+
+// ColumnRef names a column as table.column.
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+func (c ColumnRef) String() string {
+	return c.Table + "." + c.Column
+}
+
+// Check is a parsed validation rule. The rule language is
+//
+//	table.column REFERENCES table.column
+//	table.column UNIQUE
+//	table.column NOT NULL
+//	table.column <op> literal
+//	table.column <op> table.column
+//	table.column BETWEEN literal AND literal
+//	COUNT(table) [ / COUNT(table) ] <op> number
+//	COUNT(table) [ / COUNT(table) ] BETWEEN number AND number
+//
+// where <op> is one of =, !=, <>, <, <=, > and >=, and a literal is a
+// number, a single-quoted string (such as a '2020-01-01' date) or
+// TRUE/FALSE. Keywords are case-insensitive.
+type Check struct {
+	Kind CheckKind
+
+	// Column is the column the rule is about (all kinds but CheckRowCount).
+	Column ColumnRef
+
+	// Ref is the referenced column of CheckReferences, or the right-hand
+	// column of a column-to-column CheckCompare.
+	Ref *ColumnRef
+
+	// Op is the comparison operator of CheckCompare and CheckRowCount:
+	// "=", "!=", "<", "<=", ">", ">=" or "BETWEEN". "<>" is read as "!=".
+	Op string
+
+	// Values holds the literal operands: one for a comparison, the lower
+	// and upper bound for BETWEEN. Numbers are float64, strings are
+	// string and TRUE/FALSE are bool.
+	Values []interface{}
+
+	// Count holds the table whose rows CheckRowCount counts and, for a
+	// ratio, the table it is divided by.
+	Count []string
+}
+
+// Tables returns the tables the check reads, in order of appearance.
+func (c *Check) Tables() []string {
+	if c.Kind == CheckRowCount {
+		return c.Count
+	}
+	tables := []string{c.Column.Table}
+	if c.Ref != nil && c.Ref.Table != c.Column.Table {
+		tables = append(tables, c.Ref.Table)
+	}
+	return tables
+}
+
+// Level returns the rule's severity: SeverityWarning for "warning" and
+// SeverityError otherwise, since a rule is an error unless it says not.
+func (r ValidationRule) Level() Severity {
+	if strings.EqualFold(r.Severity, string(SeverityWarning)) {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// ParseCheck parses the rule text of a validation rule.
+func ParseCheck(rule string) (*Check, error) {
+	tokens, err := tokenizeRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule %q: %w", rule, err)
+	}
+	p := &ruleParser{tokens: tokens}
+	check, err := p.check()
+	if err == nil && !p.done() {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule %q: %w", rule, err)
+	}
+	return check, nil
+}
+
+// ruleToken is one lexical token of a rule: an identifier or keyword, a
+// number, a quoted string, or punctuation (an operator, ".", "(", ")" or "/").
+type ruleToken struct {
+	kind  byte // 'i' identifier, 'n' number, 's' string, 'p' punctuation
+	text  string
+	value interface{}
+}
+
+func (t ruleToken) String() string {
+	if t.kind == 0 {
+		return "end of rule"
+	}
+	return "'" + t.text + "'"
+}
+
+func tokenizeRule(s string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case isLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (isLetter(s[j]) || s[j] == '_' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, ruleToken{kind: 'i', text: s[i:j]})
+			i = j
+
+		case (c >= '0' && c <= '9') || (c == '-' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9'):
+			j := i + 1
+			for j < len(s) && ((s[j] >= '0' && s[j] <= '9') || s[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:j])
+			}
+			tokens = append(tokens, ruleToken{kind: 'n', text: s[i:j], value: n})
+			i = j
+
+		case c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s); j++ {
+				if s[j] == '\'' {
+					if j+1 < len(s) && s[j+1] == '\'' {
+						b.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, ruleToken{kind: 's', text: b.String(), value: b.String()})
+			i = j + 1
+
+		default:
+			op := ""
+			for _, candidate := range []string{"<=", ">=", "!=", "<>", "<", ">", "=", ".", "(", ")", "/"} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			if op == "<>" {
+				op = "!="
+			}
+			tokens = append(tokens, ruleToken{kind: 'p', text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// ruleParser is a recursive-descent parser over the tokens of one rule.
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+func (p *ruleParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *ruleParser) peek() ruleToken {
+	if p.done() {
+		return ruleToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword consumes the next token if it is the keyword word.
+func (p *ruleParser) keyword(word string) bool {
+	if t := p.peek(); t.kind == 'i' && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// punct consumes the next token if it is the punctuation s.
+func (p *ruleParser) punct(s string) bool {
+	if t := p.peek(); t.kind == 'p' && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) expect(what string, ok bool) error {
+	if !ok {
+		return fmt.Errorf("expected %s, got %s", what, p.peek())
+	}
+	return nil
+}
+
+func (p *ruleParser) check() (*Check, error) {
+	if p.keyword("COUNT") {
+		return p.rowCount()
+	}
+
+	col, err := p.columnRef()
+	if err != nil {
+		return nil, err
+	}
+	c := &Check{Column: col}
+
+	switch {
+	case p.keyword("REFERENCES"):
+		ref, err := p.columnRef()
+		if err != nil {
+			return nil, err
+		}
+		c.Kind, c.Ref = CheckReferences, &ref
+	case p.keyword("UNIQUE"):
+		c.Kind = CheckUnique
+	case p.keyword("NOT"):
+		if err := p.expect("NULL after NOT", p.keyword("NULL")); err != nil {
+			return nil, err
+		}
+		c.Kind = CheckNotNull
+	default:
+		c.Kind = CheckCompare
+		if err := p.comparison(c, true); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// rowCount parses the rest of a COUNT(table) [/ COUNT(table)] rule.
+func (p *ruleParser) rowCount() (*Check, error) {
+	c := &Check{Kind: CheckRowCount}
+	for {
+		if err := p.expect("'('", p.punct("(")); err != nil {
+			return nil, err
+		}
+		t := p.peek()
+		if err := p.expect("a table name", t.kind == 'i'); err != nil {
+			return nil, err
+		}
+		p.pos++
+		if err := p.expect("')'", p.punct(")")); err != nil {
+			return nil, err
+		}
+		c.Count = append(c.Count, t.text)
+
+		if len(c.Count) == 2 || !p.punct("/") {
+			break
+		}
+		if err := p.expect("COUNT after '/'", p.keyword("COUNT")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.comparison(c, false); err != nil {
+		return nil, err
+	}
+	for _, v := range c.Values {
+		if _, ok := v.(float64); !ok {
+			return nil, fmt.Errorf("row counts can only be compared with numbers")
+		}
+	}
+	return c, nil
+}
+
+// comparison parses "<op> operand" or "BETWEEN literal AND literal" into c.
+// A column operand is only allowed when columns is set.
+func (p *ruleParser) comparison(c *Check, columns bool) error {
+	if p.keyword("BETWEEN") {
+		c.Op = "BETWEEN"
+		lo, err := p.literal()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("AND", p.keyword("AND")); err != nil {
+			return err
+		}
+		hi, err := p.literal()
+		if err != nil {
+			return err
+		}
+		c.Values = []interface{}{lo, hi}
+		return nil
+	}
+
+	t := p.peek()
+	switch t.text {
+	case "=", "!=", "<", "<=", ">", ">=":
+		if t.kind != 'p' {
+			break
+		}
+		p.pos++
+		c.Op = t.text
+		if next := p.peek(); columns && next.kind == 'i' && !isBoolKeyword(next.text) {
+			ref, err := p.columnRef()
+			if err != nil {
+				return err
+			}
+			// Columns are compared row by row, which needs no join
+			if ref.Table != c.Column.Table {
+				return fmt.Errorf("'%s' and '%s' must be in the same table to be compared", c.Column, ref)
+			}
+			c.Ref = &ref
+			return nil
+		}
+		v, err := p.literal()
+		if err != nil {
+			return err
+		}
+		c.Values = []interface{}{v}
+		return nil
+	}
+	return fmt.Errorf("expected REFERENCES, UNIQUE, NOT NULL, BETWEEN or a comparison operator, got %s", t)
+}
+
+func (p *ruleParser) columnRef() (ColumnRef, error) {
+	table := p.peek()
+	if err := p.expect("table.column", table.kind == 'i'); err != nil {
+		return ColumnRef{}, err
+	}
+	p.pos++
+	if err := p.expect("'.' after table name", p.punct(".")); err != nil {
+		return ColumnRef{}, err
+	}
+	column := p.peek()
+	if err := p.expect("a column name", column.kind == 'i'); err != nil {
+		return ColumnRef{}, err
+	}
+	p.pos++
+	return ColumnRef{Table: table.text, Column: column.text}, nil
+}
+
+func (p *ruleParser) literal() (interface{}, error) {
+	t := p.peek()
+	switch {
+	case t.kind == 'n' || t.kind == 's':
+		p.pos++
+		return t.value, nil
+	case t.kind == 'i' && isBoolKeyword(t.text):
+		p.pos++
+		return strings.EqualFold(t.text, "TRUE"), nil
+	}
+	return nil, fmt.Errorf("expected a number, a quoted string, TRUE or FALSE, got %s", t)
+}
+
+func isBoolKeyword(s string) bool {
+	return strings.EqualFold(s, "TRUE") || strings.EqualFold(s, "FALSE")
+}
+
+// rules validates the schema's validation_rules: each must parse, name
+// tables and columns that exist, and declare a known severity (V-V001).
+func (c *collector) rules(s *Schema) {
+	for i, r := range s.ValidationRules {
+		pointer := fmt.Sprintf("/validation_rules/%d", i)
+
+		switch r.Severity {
+		case "", string(SeverityError), string(SeverityWarning):
+		default:
+			c.error("V-V001", pointer+"/severity", fmt.Errorf("validation rule %d: invalid severity %q: must be \"error\" or \"warning\"", i, r.Severity))
+		}
+
+		check, err := ParseCheck(r.Rule)
+		if err != nil {
+			c.error("V-V001", pointer+"/rule", fmt.Errorf("validation rule %d: %w", i, err))
+			continue
+		}
+		c.error("V-V001", pointer+"/rule", checkReferences(s, check, i))
+	}
+}
+
+// checkReferences reports the first table or column a check names that the
+// schema does not define.
+func checkReferences(s *Schema, check *Check, index int) error {
+	if check.Kind == CheckRowCount {
+		for _, name := range check.Count {
+			if s.Table(name) == nil {
+				return fmt.Errorf("validation rule %d: table '%s' does not exist in schema", index, name)
+			}
+		}
+		return nil
+	}
+
+	refs := []ColumnRef{check.Column}
+	if check.Ref != nil {
+		refs = append(refs, *check.Ref)
+	}
+	for _, ref := range refs {
+		t := s.Table(ref.Table)
+		if t == nil {
+			return fmt.Errorf("validation rule %d: table '%s' does not exist in schema", index, ref.Table)
+		}
+		if t.Column(ref.Column) == nil {
+			return fmt.Errorf("validation rule %d: column '%s' does not exist in schema", index, ref)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheck(t *testing.T) {
+	loans := func(column string) ColumnRef { return ColumnRef{Table: "loans", Column: column} }
+
+	tests := []struct {
+		rule string
+		want Check
+	}{
+		{"loans.borrower_id REFERENCES borrowers.id",
+			Check{Kind: CheckReferences, Column: loans("borrower_id"), Ref: &ColumnRef{Table: "borrowers", Column: "id"}}},
+		{"loans.code unique", Check{Kind: CheckUnique, Column: loans("code")}},
+		{"loans.amount NOT NULL", Check{Kind: CheckNotNull, Column: loans("amount")}},
+		{"loans.amount >= 100", Check{Kind: CheckCompare, Column: loans("amount"), Op: ">=", Values: []interface{}{100.0}}},
+		{"loans.rate <> -1.5", Check{Kind: CheckCompare, Column: loans("rate"), Op: "!=", Values: []interface{}{-1.5}}},
+		{"loans.status != 'it''s'", Check{Kind: CheckCompare, Column: loans("status"), Op: "!=", Values: []interface{}{"it's"}}},
+		{"loans.active = TRUE", Check{Kind: CheckCompare, Column: loans("active"), Op: "=", Values: []interface{}{true}}},
+		{"loans.score BETWEEN 300 AND 850", Check{Kind: CheckCompare, Column: loans("score"), Op: "BETWEEN", Values: []interface{}{300.0, 850.0}}},
+		{"loans.opened BETWEEN '2020-01-01' and '2024-12-31'",
+			Check{Kind: CheckCompare, Column: loans("opened"), Op: "BETWEEN", Values: []interface{}{"2020-01-01", "2024-12-31"}}},
+		{"loans.end_date > loans.start_date", Check{Kind: CheckCompare, Column: loans("end_date"), Op: ">", Ref: &ColumnRef{Table: "loans", Column: "start_date"}}},
+		{"COUNT(loans) >= 10", Check{Kind: CheckRowCount, Op: ">=", Values: []interface{}{10.0}, Count: []string{"loans"}}},
+		{"count(loans) / count(borrowers) BETWEEN 1 AND 3",
+			Check{Kind: CheckRowCount, Op: "BETWEEN", Values: []interface{}{1.0, 3.0}, Count: []string{"loans", "borrowers"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := ParseCheck(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, &tt.want, got)
+		})
+	}
+}
+
+func TestParseCheckInvalid(t *testing.T) {
+	tests := []struct {
+		rule     string
+		errorMsg string
+	}{
+		{"", "expected table.column, got end of rule"},
+		{"loans", "expected '.' after table name, got end of rule"},
+		{"loans.amount", "expected REFERENCES, UNIQUE, NOT NULL, BETWEEN or a comparison operator, got end of rule"},
+		{"loans.amount NOT", "expected NULL after NOT, got end of rule"},
+		{"loans.amount REFERENCES borrowers", "expected '.' after table name"},
+		{"loans.amount BETWEEN 1", "expected AND, got end of rule"},
+		{"loans.amount > ", "expected a number, a quoted string, TRUE or FALSE"},
+		{"loans.amount UNIQUE extra", "unexpected 'extra'"},
+		{"loans.status = 'open", "unterminated string"},
+		{"loans.amount ~ 3", "unexpected character '~'"},
+		{"payments.sum(amount) <= loans.amount", "expected REFERENCES, UNIQUE, NOT NULL, BETWEEN or a comparison operator, got '('"},
+		{"loans.amount <= borrowers.max_loan", "'loans.amount' and 'borrowers.max_loan' must be in the same table to be compared"},
+		{"COUNT(loans) > 'many'", "row counts can only be compared with numbers"},
+		{"COUNT(loans) / borrowers > 1", "expected COUNT after '/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseCheck(tt.rule)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestValidate_ValidationRules(t *testing.T) {
+	s := &Schema{
+		Name:         "rules",
+		DatabaseType: []string{"postgres"},
+		Tables: []Table{
+			{Name: "borrowers", RecordCount: 5, Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+			{Name: "loans", RecordCount: 5, Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "start_date", Type: "date"},
+			}},
+		},
+		ValidationRules: []ValidationRule{
+			{Rule: "loans.id REFERENCES borrowers.id"},
+			{Rule: "loans.end_date > loans.start_date", Severity: "warning"},
+			{Rule: "COUNT(loans) / COUNT(payments) > 1"},
+			{Rule: "loans.start_date > borrowers.id"},
+			{Rule: "loans.id UNIQUE", Severity: "fatal"},
+			{Rule: "loans.id IS UNIQUE"},
+		},
+	}
+
+	list := Validate(s)
+	require.Len(t, list, 5)
+	for _, e := range list {
+		assert.Equal(t, "V-V001", e.Code)
+		assert.Equal(t, SeverityError, e.Severity)
+	}
+	assert.Equal(t, "/validation_rules/1/rule", list[0].Pointer)
+	assert.Contains(t, list[0].Error(), "column 'loans.end_date' does not exist in schema")
+	assert.Contains(t, list[1].Error(), "table 'payments' does not exist in schema")
+	assert.Contains(t, list[2].Error(), "'loans.start_date' and 'borrowers.id' must be in the same table to be compared")
+	assert.Equal(t, "/validation_rules/4/severity", list[3].Pointer)
+	assert.Contains(t, list[3].Error(), `invalid severity "fatal"`)
+	assert.Equal(t, "/validation_rules/5/rule", list[4].Pointer)
+	assert.Contains(t, list[4].Error(), "got 'IS'")
+
+	assert.Equal(t, SeverityWarning, s.ValidationRules[1].Level())
+	assert.Equal(t, SeverityError, s.ValidationRules[0].Level())
+}
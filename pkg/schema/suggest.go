@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"math"
+	"strings"
+)
+
+// nameRule picks a generator for columns whose name matches.
+type nameRule struct {
+	// names are exact column names; suffixes match themselves and
+	// "<anything>_<suffix>"
+	names    []string
+	suffixes []string
+
+	generator string
+	params    map[string]interface{}
+}
+
+func (r nameRule) matches(name string) bool {
+	for _, n := range r.names {
+		if name == n {
+			return true
+		}
+	}
+	for _, s := range r.suffixes {
+		if name == s || strings.HasSuffix(name, "_"+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameRules are tried in order; the first whose generator suits the
+// column's type wins.
+var nameRules = []nameRule{
+	{names: []string{"email", "email_address"}, suffixes: []string{"email"}, generator: "email"},
+	{names: []string{"phone", "phone_number", "mobile", "telephone", "cell_phone"}, suffixes: []string{"phone", "phone_number"}, generator: "phone"},
+	{names: []string{"first_name", "firstname", "given_name"}, generator: "first_name"},
+	{names: []string{"last_name", "lastname", "surname", "family_name"}, generator: "last_name"},
+	{names: []string{"full_name", "display_name", "contact_name"}, generator: "full_name"},
+	{names: []string{"address", "street", "street_address", "address_line1", "address1"}, suffixes: []string{"street_address"}, generator: "address"},
+	{names: []string{"ssn", "social_security_number"}, generator: "ssn"},
+	{names: []string{"company", "company_name", "employer", "organization"}, generator: "company_name"},
+	{names: []string{"job_title"}, generator: "job_title"},
+	{names: []string{"domain", "domain_name"}, generator: "domain"},
+	{names: []string{"uuid", "guid"}, suffixes: []string{"uuid", "guid"}, generator: "uuid"},
+
+	{names: []string{"birth_date", "date_of_birth", "dob", "birthday", "birthdate"}, generator: "date_of_birth"},
+	{names: []string{"expires", "expiry", "deadline"}, suffixes: []string{"expires_at", "expiry_date", "due_at", "due_date", "due_on", "scheduled_at", "scheduled_for", "deadline"}, generator: "timestamp_future"},
+	{names: []string{"created", "updated", "modified", "deleted", "timestamp"}, suffixes: []string{"at", "on", "date", "time"}, generator: "timestamp_past"},
+
+	{names: []string{"age"}, generator: "int_range", params: map[string]interface{}{"min": 18.0, "max": 90.0}},
+	{names: []string{"quantity", "qty"}, suffixes: []string{"quantity", "qty"}, generator: "int_range", params: map[string]interface{}{"min": 1.0, "max": 10.0}},
+	{names: []string{"salary"}, suffixes: []string{"salary"}, generator: "decimal_range", params: map[string]interface{}{"min": 30000.0, "max": 200000.0}},
+	{names: []string{"price", "amount", "total", "subtotal", "cost", "fee", "balance"},
+		suffixes: []string{"price", "amount", "total", "cost", "fee", "balance"}, generator: "decimal_range", params: map[string]interface{}{"min": 1.0, "max": 1000.0}},
+	{names: []string{"rate", "percent", "percentage"}, suffixes: []string{"rate", "percent", "pct"}, generator: "decimal_range", params: map[string]interface{}{"min": 0.0, "max": 100.0}},
+}
+
+// SuggestGenerator chooses a generator for col from its name and type, such
+// as email for an email column or timestamp_past for created_at, with
+// parameters that fit the type. It returns "" when nothing suits better
+// than the type-based fallback. Keys are left alone: primary keys are
+// numbered and foreign keys pick parent rows.
+func SuggestGenerator(col *Column) (string, map[string]interface{}) {
+	if col.PrimaryKey || col.ForeignKey != nil || col.AutoIncrement {
+		return "", nil
+	}
+	t := col.DataType()
+	family := t.Family()
+	name := strings.ToLower(col.Name)
+
+	for _, rule := range nameRules {
+		if !rule.matches(name) {
+			continue
+		}
+		generator := rule.generator
+		// Ranges follow the column: float_range for float and double
+		if generator == "decimal_range" && t.Base != "decimal" {
+			generator = "float_range"
+		}
+		if !suits(generator, family) {
+			continue
+		}
+		return generator, rangeParams(rule.params, t)
+	}
+
+	switch {
+	case family == FamilyString && t.Base == "char" && t.Length == 36:
+		// A UUID column, such as PostgreSQL's uuid
+		return "uuid", nil
+	case family == FamilyString && strings.HasSuffix(name, "_id") && (t.Base == "text" || t.Length >= 36):
+		return "uuid", nil
+	case family == FamilyInteger && strings.HasSuffix(name, "_id") && t.Base != "boolean":
+		// An identifier from another system, such as stripe_customer_id
+		return "int_range", rangeParams(map[string]interface{}{"min": 1.0, "max": 100000.0}, t)
+	case family == FamilyBoolean && !t.Array:
+		return "boolean", nil
+	case family == FamilyEnum:
+		return "enum", nil
+	}
+	return "", nil
+}
+
+// suits reports whether generator can fill a column of the given family.
+func suits(generator, family string) bool {
+	spec, ok := LookupGenerator(generator)
+	if !ok {
+		return false
+	}
+	for _, f := range spec.Types {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeParams copies a rule's parameters, lowering max to what the column
+// can hold, such as 999.99 for decimal(5,2) or 127 for tinyint.
+func rangeParams(params map[string]interface{}, t DataType) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		out[k] = v
+	}
+
+	limit := math.Inf(1)
+	switch {
+	case t.Base == "decimal" && t.Precision > 0:
+		limit = math.Pow10(t.Precision-t.Scale) - math.Pow10(-t.Scale)
+	case t.Base == "tinyint" && !t.Unsigned:
+		limit = math.MaxInt8
+	case t.Base == "smallint" && !t.Unsigned:
+		limit = math.MaxInt16
+	}
+	if hi, ok := out["max"].(float64); ok && hi > limit {
+		out["max"] = math.Floor(limit*100) / 100
+		if lo, ok := out["min"].(float64); ok && lo > out["max"].(float64) {
+			out["min"] = 0.0
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestGenerator(t *testing.T) {
+	tests := []struct {
+		column    Column
+		generator string
+		params    map[string]interface{}
+	}{
+		{Column{Name: "email", Type: "varchar(255)"}, "email", nil},
+		{Column{Name: "billing_email", Type: "text"}, "email", nil},
+		{Column{Name: "email", Type: "int"}, "", nil},
+		{Column{Name: "mobile", Type: "varchar(20)"}, "phone", nil},
+		{Column{Name: "created_at", Type: "datetime"}, "timestamp_past", nil},
+		{Column{Name: "expires_at", Type: "timestamp"}, "timestamp_future", nil},
+		{Column{Name: "dob", Type: "date"}, "date_of_birth", nil},
+		{Column{Name: "session_id", Type: "char(36)"}, "uuid", nil},
+		{Column{Name: "external_id", Type: "bigint"}, "int_range", map[string]interface{}{"min": 1.0, "max": 100000.0}},
+		{Column{Name: "age", Type: "tinyint"}, "int_range", map[string]interface{}{"min": 18.0, "max": 90.0}},
+		{Column{Name: "qty", Type: "tinyint"}, "int_range", map[string]interface{}{"min": 1.0, "max": 10.0}},
+		{Column{Name: "price", Type: "decimal(4,2)"}, "decimal_range", map[string]interface{}{"min": 1.0, "max": 99.99}},
+		{Column{Name: "rate", Type: "double"}, "float_range", map[string]interface{}{"min": 0.0, "max": 100.0}},
+		{Column{Name: "active", Type: "boolean"}, "boolean", nil},
+		{Column{Name: "kind", Type: "enum('a','b')"}, "enum", nil},
+		{Column{Name: "id", Type: "varchar(36)", PrimaryKey: true}, "", nil},
+		{Column{Name: "user_id", Type: "int", ForeignKey: &ForeignKey{Table: "users", Column: "id"}}, "", nil},
+		{Column{Name: "notes", Type: "text"}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column.Name+" "+tt.column.Type, func(t *testing.T) {
+			generator, params := SuggestGenerator(&tt.column)
+			assert.Equal(t, tt.generator, generator)
+			assert.Equal(t, tt.params, params)
+		})
+	}
+}
@@ -35,6 +35,17 @@ type Schema struct {
 	ValidationRules []ValidationRule `json:"validation_rules"`
 }
 
+// Table returns the table with the given name, or nil if the schema has no
+// such table.
+func (s *Schema) Table(name string) *Table {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
 // SchemaMetadata contains metadata about the schema.
 type SchemaMetadata struct {
 	Industry       string   `json:"industry"`
@@ -52,6 +63,27 @@ type Table struct {
 	Indexes     []Index  `json:"indexes"`
 }
 
+// Column returns the column with the given name, or nil if the table has no
+// such column.
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// PrimaryKey returns the table's primary key column, or nil if none is declared.
+func (t *Table) PrimaryKey() *Column {
+	for i := range t.Columns {
+		if t.Columns[i].PrimaryKey {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
 // Column represents a database column definition.
 type Column struct {
 	Name            string                 `json:"name"`
@@ -69,10 +101,49 @@ type Column struct {
 
 // ForeignKey represents a foreign key constraint on a column.
 type ForeignKey struct {
-	Table    string `json:"table"`
-	Column   string `json:"column"`
-	OnDelete string `json:"on_delete"`
-	OnUpdate string `json:"on_update"`
+	Table       string       `json:"table"`
+	Column      string       `json:"column"`
+	OnDelete    string       `json:"on_delete"`
+	OnUpdate    string       `json:"on_update"`
+	Cardinality *Cardinality `json:"cardinality,omitempty"`
+	Hierarchy   *Hierarchy   `json:"hierarchy,omitempty"`
+}
+
+// Cardinality controls how many child rows reference each parent row.
+// When set, the child table's row count is the sum of the per-parent counts
+// and its record_count is ignored.
+//
+// Counts are drawn per parent from Distribution over [Min, Max], or read from
+// the parent's CountColumn (for example one payment per month of a loan's
+// term). Examples:
+//
+//	{"min": 0, "max": 10, "distribution": "zipf"}  // a few parents get many children
+//	{"min": 1, "max": 1}                           // exactly one child per parent
+//	{"min": 1, "max": 5}                           // at least one, uniform up to five
+//	{"count_column": "term_months"}                // as many children as the parent's term
+type Cardinality struct {
+	Min          int                    `json:"min"`
+	Max          int                    `json:"max"`
+	Distribution string                 `json:"distribution,omitempty"`
+	Params       map[string]interface{} `json:"params,omitempty"`
+	CountColumn  string                 `json:"count_column,omitempty"`
+}
+
+// Hierarchy shapes a self-referencing foreign key, such as
+// employees.manager_id -> employees.id, into trees.
+//
+// The first Roots rows are roots (NULL). Every later row picks a random
+// earlier row as its parent among those less than MaxDepth levels deep that
+// have fewer than MaxChildren children; a row with no such parent becomes
+// another root. Zero means one root and no depth or branching limit.
+// Examples:
+//
+//	{"max_depth": 4, "max_children": 8}  // an org chart at most four levels deep
+//	{"roots": 5, "max_depth": 3}         // five category trees, three levels each
+type Hierarchy struct {
+	Roots       int `json:"roots,omitempty"`
+	MaxDepth    int `json:"max_depth,omitempty"`
+	MaxChildren int `json:"max_children,omitempty"`
 }
 
 // Index represents a database index definition.
@@ -84,8 +155,10 @@ type Index struct {
 }
 
 // Relationship represents an explicit relationship between tables.
-// This is for documentation purposes; the actual foreign key constraint
-// is defined inline in the Column struct.
+// The actual foreign key constraint is defined inline in the Column struct;
+// each relationship must describe one of those foreign keys, from either
+// side (V-R005). RelationshipType is one of the OneToOne, OneToMany,
+// ManyToOne and ManyToMany constants.
 type Relationship struct {
 	FromTable        string `json:"from_table"`
 	FromColumn       string `json:"from_column"`
@@ -163,3 +163,30 @@ func TestColumnParams(t *testing.T) {
 	assert.Equal(t, 500.0, col.GeneratorParams["mean"])
 	assert.Equal(t, 100.0, col.GeneratorParams["std_dev"])
 }
+
+// TestLookupHelpers verifies the table, column and primary key lookups.
+func TestLookupHelpers(t *testing.T) {
+	schema := &Schema{
+		Tables: []Table{
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "email", Type: "varchar(255)"},
+				},
+			},
+		},
+	}
+
+	table := schema.Table("users")
+	assert.NotNil(t, table)
+	assert.Nil(t, schema.Table("missing"))
+
+	assert.Equal(t, "email", table.Column("email").Name)
+	assert.Nil(t, table.Column("missing"))
+	assert.Equal(t, "id", table.PrimaryKey().Name)
+
+	// Lookups return pointers into the schema, not copies
+	table.Column("email").Unique = true
+	assert.True(t, schema.Tables[0].Columns[1].Unique)
+}
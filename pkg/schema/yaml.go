@@ -0,0 +1,418 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a schema file format.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatOf returns the format of a schema file from its extension: YAML
+// for .yaml and .yml, JSON for anything else.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+// Parse parses a schema in the given format.
+func Parse(r io.Reader, format Format) (*Schema, error) {
+	if format == FormatYAML {
+		return ParseSchemaYAML(r)
+	}
+	return ParseSchema(r)
+}
+
+// ParseSchemaYAML parses a schema written in YAML. It accepts the same
+// fields as ParseSchema, under the same names, and is just as strict about
+// unknown fields; comments are allowed anywhere.
+//
+// The document is converted to JSON and decoded by the JSON path, so both
+// formats produce identical schemas. Errors are located in the YAML source.
+func ParseSchemaYAML(r io.Reader) (*Schema, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchemaYAML: failed to read schema: %w", err)
+	}
+
+	s, _, err := decode(src, FormatYAML)
+	if err != nil {
+		return nil, fmt.Errorf("ParseSchemaYAML: %w", err)
+	}
+	return s, nil
+}
+
+// Convert rewrites a schema document from one format to another, keeping
+// its keys in their original order. Comments do not survive a conversion
+// from YAML to JSON. The input is not validated; parse it first.
+func Convert(data []byte, from, to Format) ([]byte, error) {
+	if from == to {
+		return data, nil
+	}
+
+	if from == FormatYAML {
+		doc, err := yamlToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, doc.json, "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	// JSON is valid YAML, so it parses straight into a node tree that only
+	// needs its flow styles dropped to print as block YAML
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Marshal writes a schema in the given format, leaving out fields that hold
+// their zero value, such as "unique": false, so that the document reads like
+// one written by hand. Generator parameters are written as they are.
+func Marshal(s *Schema, format Format) ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	prune(&node)
+	blockStyle(&node)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if format == FormatYAML {
+		return out.Bytes(), nil
+	}
+	return Convert(out.Bytes(), FormatYAML, FormatJSON)
+}
+
+// prune drops the mapping entries of a tree parsed from JSON whose values
+// are null, "", false or empty. A column's default is kept, since an empty
+// default differs from none, and parameter maps are not entered.
+func prune(n *yaml.Node) {
+	for _, child := range n.Content {
+		if n.Kind != yaml.MappingNode {
+			prune(child)
+		}
+	}
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+
+	kept := n.Content[:0]
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch key.Value {
+		case "generator_params", "params":
+		default:
+			prune(value)
+		}
+		if key.Value != "default" && empty(value) {
+			continue
+		}
+		kept = append(kept, key, value)
+	}
+	n.Content = kept
+}
+
+// empty reports whether n is null, "", false or an empty collection.
+func empty(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(n.Content) == 0
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!null":
+			return true
+		case "!!str":
+			return n.Value == ""
+		case "!!bool":
+			return n.Value == "false"
+		}
+	}
+	return false
+}
+
+// blockStyle clears the flow and quoting styles of a tree parsed from JSON.
+// The encoder still quotes strings that would otherwise read as another
+// type, such as "123" or "true".
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// yamlDocument is a YAML schema converted to JSON, written one token per
+// line so that each JSON line maps back to a YAML position.
+type yamlDocument struct {
+	json  []byte
+	lines []string // YAML source lines
+
+	// spots holds the YAML line and column of each JSON line's token
+	spots [][2]int
+}
+
+// relocate moves e's position from the JSON document to the YAML source.
+func (d *yamlDocument) relocate(e *Error) {
+	if e.Line < 1 || e.Line > len(d.spots) {
+		return
+	}
+	spot := d.spots[e.Line-1]
+	e.Line, e.Column = spot[0], spot[1]
+	e.Source = ""
+	if e.Line <= len(d.lines) {
+		e.Source = strings.TrimRight(d.lines[e.Line-1], "\r")
+	}
+}
+
+// yamlLine finds the line number in the parser's syntax errors.
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// yamlToJSON converts a single YAML document to JSON.
+func yamlToJSON(src []byte) (*yamlDocument, error) {
+	doc := &yamlDocument{lines: strings.Split(string(src), "\n")}
+
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	var root yaml.Node
+	if err := dec.Decode(&root); err != nil && err != io.EOF {
+		return nil, doc.syntaxError(err)
+	}
+	if len(root.Content) == 0 {
+		return nil, &Error{Severity: SeverityError, Err: fmt.Errorf("schema is empty")}
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); err != io.EOF {
+		if err != nil {
+			return nil, doc.syntaxError(err)
+		}
+		at := &extra
+		if len(extra.Content) > 0 {
+			at = extra.Content[0]
+		}
+		return nil, doc.errorAt(at, fmt.Errorf("a schema file must hold a single YAML document"))
+	}
+
+	var w jsonWriter
+	if err := w.value(doc, root.Content[0]); err != nil {
+		return nil, err
+	}
+	doc.json = []byte(strings.Join(w.lines, "\n"))
+	doc.spots = w.spots
+	return doc, nil
+}
+
+// syntaxError locates an error from the YAML parser.
+func (d *yamlDocument) syntaxError(err error) error {
+	msg := err.Error()
+	located := &Error{Severity: SeverityError, Err: errors.New(strings.TrimPrefix(msg, "yaml: "))}
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		located.Err = errors.New(strings.TrimPrefix(msg, m[0]))
+		if line >= 1 && line <= len(d.lines) {
+			located.Line, located.Column = line, 1
+			located.Source = strings.TrimRight(d.lines[line-1], "\r")
+		}
+	}
+	return located
+}
+
+// errorAt returns err located at node n.
+func (d *yamlDocument) errorAt(n *yaml.Node, err error) *Error {
+	e := &Error{Severity: SeverityError, Line: n.Line, Column: n.Column, Err: err}
+	if n.Line >= 1 && n.Line <= len(d.lines) {
+		e.Source = strings.TrimRight(d.lines[n.Line-1], "\r")
+	}
+	return e
+}
+
+// jsonWriter emits JSON one token per line, recording the YAML node each
+// line came from.
+type jsonWriter struct {
+	lines []string
+	spots [][2]int
+}
+
+func (w *jsonWriter) emit(text string, n *yaml.Node) {
+	w.lines = append(w.lines, text)
+	w.spots = append(w.spots, [2]int{n.Line, n.Column})
+}
+
+// comma ends the previous line with a separator.
+func (w *jsonWriter) comma() {
+	w.lines[len(w.lines)-1] += ","
+}
+
+func (w *jsonWriter) value(doc *yamlDocument, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return w.value(doc, n.Alias)
+
+	case yaml.MappingNode:
+		w.emit("{", n)
+		pairs, err := mappingPairs(doc, n)
+		if err != nil {
+			return err
+		}
+		for i, pair := range pairs {
+			if i > 0 {
+				w.comma()
+			}
+			w.emit(jsonString(pair[0].Value)+":", pair[0])
+			if err := w.value(doc, pair[1]); err != nil {
+				return err
+			}
+		}
+		w.emit("}", n)
+
+	case yaml.SequenceNode:
+		w.emit("[", n)
+		for i, item := range n.Content {
+			if i > 0 {
+				w.comma()
+			}
+			if err := w.value(doc, item); err != nil {
+				return err
+			}
+		}
+		w.emit("]", n)
+
+	case yaml.ScalarNode:
+		text, err := scalarJSON(n)
+		if err != nil {
+			return doc.errorAt(n, err)
+		}
+		w.emit(text, n)
+	}
+	return nil
+}
+
+// mappingPairs returns a mapping's key/value pairs with merge keys (<<)
+// expanded. Keys defined in the mapping itself override merged ones, and a
+// key defined twice is an error, as JSON decoding would silently keep the
+// last value.
+func mappingPairs(doc *yamlDocument, n *yaml.Node) ([][2]*yaml.Node, error) {
+	var pairs, merged [][2]*yaml.Node
+	seen := make(map[string]*yaml.Node)
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind != yaml.ScalarNode {
+			return nil, doc.errorAt(key, fmt.Errorf("mapping keys must be strings"))
+		}
+
+		if key.Tag == "!!merge" {
+			sources := []*yaml.Node{value}
+			if resolve(value).Kind == yaml.SequenceNode {
+				sources = resolve(value).Content
+			}
+			for _, src := range sources {
+				src = resolve(src)
+				if src.Kind != yaml.MappingNode {
+					return nil, doc.errorAt(src, fmt.Errorf("merge key (<<) needs a mapping or a list of mappings"))
+				}
+				inner, err := mappingPairs(doc, src)
+				if err != nil {
+					return nil, err
+				}
+				merged = append(merged, inner...)
+			}
+			continue
+		}
+
+		if first, ok := seen[key.Value]; ok {
+			return nil, doc.errorAt(key, fmt.Errorf("mapping key %q already defined at line %d", key.Value, first.Line))
+		}
+		seen[key.Value] = key
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+
+	for _, pair := range merged {
+		if _, ok := seen[pair[0].Value]; !ok {
+			seen[pair[0].Value] = pair[0]
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// scalarJSON renders a scalar as a JSON value. Numbers, booleans and null
+// keep their type; everything else, including dates, is a string.
+func scalarJSON(n *yaml.Node) (string, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return "null", nil
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return "", err
+		}
+		if f, ok := v.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			return "", fmt.Errorf("%s cannot be used in a schema", n.Value)
+		}
+		out, err := json.Marshal(v)
+		return string(out), err
+	}
+	return jsonString(n.Value), nil
+}
+
+// jsonString quotes s as a JSON string without escaping HTML characters.
+func jsonString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
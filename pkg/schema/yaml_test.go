@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const loansYAML = `# Loans and the people who take them out
+name: loans
+description: "Borrowers & loans"
+database_type: [postgres]
+
+tables:
+  - name: borrowers
+    record_count: 10
+    columns:
+      - name: id
+        type: int
+        primary_key: true
+      - name: joined
+        type: date
+        generator: date_between
+        generator_params: {start_date: 2020-01-01, end_date: 2024-12-31}   # dates stay strings
+
+  - name: loans
+    record_count: 20
+    columns:
+      - name: id
+        type: int
+        primary_key: true
+      - name: borrower_id
+        type: int
+        foreign_key: &cascade
+          table: borrowers
+          column: id
+          on_delete: CASCADE
+          on_update: CASCADE
+      - name: cosigner_id
+        type: int
+        nullable: true
+        foreign_key:
+          <<: *cascade
+          on_delete: SET NULL
+      - name: rate
+        type: decimal(5,2)
+        generator: float_range
+        generator_params: {min: 1.5, max: 12}
+`
+
+const loansJSON = `{
+  "name": "loans",
+  "description": "Borrowers & loans",
+  "database_type": ["postgres"],
+  "tables": [
+    {"name": "borrowers", "record_count": 10, "columns": [
+      {"name": "id", "type": "int", "primary_key": true},
+      {"name": "joined", "type": "date", "generator": "date_between", "generator_params": {"start_date": "2020-01-01", "end_date": "2024-12-31"}}
+    ]},
+    {"name": "loans", "record_count": 20, "columns": [
+      {"name": "id", "type": "int", "primary_key": true},
+      {"name": "borrower_id", "type": "int", "foreign_key": {"table": "borrowers", "column": "id", "on_delete": "CASCADE", "on_update": "CASCADE"}},
+      {"name": "cosigner_id", "type": "int", "nullable": true, "foreign_key": {"on_delete": "SET NULL", "table": "borrowers", "column": "id", "on_update": "CASCADE"}},
+      {"name": "rate", "type": "decimal(5,2)", "generator": "float_range", "generator_params": {"min": 1.5, "max": 12}}
+    ]}
+  ]
+}`
+
+func TestParseSchemaYAML(t *testing.T) {
+	fromYAML, err := ParseSchemaYAML(strings.NewReader(loansYAML))
+	require.NoError(t, err)
+	fromJSON, err := ParseSchema(strings.NewReader(loansJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, fromJSON, fromYAML)
+	assert.Equal(t, "2020-01-01", fromYAML.Tables[0].Columns[1].GeneratorParams["start_date"])
+	assert.Equal(t, 12.0, fromYAML.Tables[1].Columns[3].GeneratorParams["max"])
+	assert.Equal(t, "SET NULL", fromYAML.Tables[1].Columns[2].ForeignKey.OnDelete)
+}
+
+func TestParseSchemaYAML_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		line     int
+		column   int
+		pointer  string
+		errorMsg string
+	}{
+		{"unknown field", "name: x\ndatabase_type: [mysql]\ntables: []\nauthor_email: a@b.c\n",
+			4, 1, "/author_email", `failed to decode YAML: line 4, column 1: json: unknown field "author_email"`},
+		{"wrong type", "name: x\ndatabase_type: [mysql]\ntables:\n  - name: t\n    record_count: many\n",
+			5, 19, "/tables/0/record_count", "cannot unmarshal string"},
+		{"validation", "name: x\ndatabase_type: [mysql]\ntables:\n  - name: t\n    record_count: 1\n    columns:\n      - {name: id, type: integr, primary_key: true}\n",
+			7, 26, "/tables/0/columns/0/type", "type not supported"},
+		{"syntax", "name: x\ntables: [\n", 2, 1, "", "did not find expected node content"},
+		{"duplicate key", "name: x\ndatabase_type: [mysql]\nname: y\n", 3, 1, "", `mapping key "name" already defined at line 1`},
+		{"several documents", "name: x\n---\nname: y\n", 3, 1, "", "a schema file must hold a single YAML document"},
+		{"infinity", "name: x\ndatabase_type: [mysql]\ntables: []\nversion: .inf\n", 4, 10, "", ".inf cannot be used in a schema"},
+		{"empty", "# nothing yet\n", 0, 0, "", "schema is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchemaYAML(strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+
+			var located *Error
+			require.True(t, errors.As(err, &located))
+			assert.Equal(t, tt.line, located.Line)
+			assert.Equal(t, tt.column, located.Column)
+			assert.Equal(t, tt.pointer, located.Pointer)
+			if tt.line > 0 {
+				assert.Equal(t, strings.Split(tt.input, "\n")[tt.line-1], located.Source)
+			}
+		})
+	}
+}
+
+func TestLoadSchema_YAML(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"loans.yaml", "loans.YML"} {
+		path := dir + "/" + name
+		require.NoError(t, os.WriteFile(path, []byte(loansYAML), 0o644))
+		s, err := LoadSchema(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, "loans", s.Name)
+	}
+
+	path := dir + "/broken.yml"
+	require.NoError(t, os.WriteFile(path, []byte("name: x\nbogus: 1\n"), 0o644))
+	_, err := LoadSchema(path)
+	var located *Error
+	require.True(t, errors.As(err, &located))
+	assert.Equal(t, path, located.File)
+	assert.Equal(t, 2, located.Line)
+}
+
+func TestConvert(t *testing.T) {
+	data, err := os.ReadFile("../../schemas/example-schema.json")
+	require.NoError(t, err)
+	original, err := ParseSchema(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	yamlData, err := Convert(data, FormatJSON, FormatYAML)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(yamlData), "schema_version: \"1.0\"\n"), "keys keep their order and strings that look like numbers stay quoted")
+	assert.NotContains(t, string(yamlData), "{", "output uses block style")
+
+	fromYAML, err := ParseSchemaYAML(strings.NewReader(string(yamlData)))
+	require.NoError(t, err)
+	assert.Equal(t, original, fromYAML)
+
+	jsonData, err := Convert(yamlData, FormatYAML, FormatJSON)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(jsonData), "{\n  \"schema_version\": \"1.0\",\n"))
+	roundTrip, err := ParseSchema(strings.NewReader(string(jsonData)))
+	require.NoError(t, err)
+	assert.Equal(t, original, roundTrip)
+
+	converted, err := Convert([]byte(loansYAML), FormatYAML, FormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, string(converted), `"description": "Borrowers & loans"`)
+	assert.NotContains(t, string(converted), "dates stay strings", "comments are dropped")
+}
+
+func TestMarshal(t *testing.T) {
+	original, err := LoadSchema("../../schemas/example-schema.json")
+	require.NoError(t, err)
+	zero := ""
+	original.Tables[0].Columns[1].Default = &zero
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		data, err := Marshal(original, format)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), ": false", "zero values are left out")
+		assert.NotContains(t, string(data), ": null")
+
+		parsed, err := Parse(strings.NewReader(string(data)), format)
+		require.NoError(t, err, string(data))
+		assert.Equal(t, original, parsed, "%s round trip", format)
+	}
+
+	data, err := Marshal(original, FormatYAML)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "schema_version: \"1.0\"\nname: fintech-loans\n"))
+	assert.Contains(t, string(data), "default: \"\"\n", "an empty default is kept")
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatYAML, FormatOf("a/b.yaml"))
+	assert.Equal(t, FormatYAML, FormatOf("b.YML"))
+	assert.Equal(t, FormatJSON, FormatOf("b.json"))
+	assert.Equal(t, FormatJSON, FormatOf("b"))
+}
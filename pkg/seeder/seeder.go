@@ -0,0 +1,370 @@
+// Package seeder writes generated datasets into a live MySQL or PostgreSQL
+// database.
+//
+// Tables are created and filled in generation order so that foreign keys
+// always point at rows that already exist. Deferred foreign keys
+// (self-references and keys that close a cycle) are inserted as NULL and
+// back-filled with UPDATE statements once every table is loaded. Indexes are
+// created after the data is loaded. Rows are inserted with batched
+// multi-row INSERT statements, one transaction per table, so a failure never
+// leaves a table half-filled.
+//
+// The database must not already hold the schema's tables: Seed stops with an
+// error naming the first table that exists rather than adding rows to it,
+// and drops the tables it had created before finding it.
+//
+// Example usage:
+//
+//	d, _ := dialect.For("mysql")
+//	db, err := seeder.Connect(ctx, d, dialect.ConnConfig{Host: "localhost", User: "root", Database: "demo"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer db.Close()
+//
+//	result, err := seeder.New(db, d, seeder.Options{}).Seed(ctx, s, ds)
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/ddl"
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+
+	// Importing the drivers also registers them with database/sql
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// DefaultBatchSize is the number of rows per INSERT statement when
+// Options.BatchSize is zero.
+const DefaultBatchSize = 500
+
+// Options configures a Seeder.
+type Options struct {
+	// BatchSize is the maximum number of rows per INSERT statement.
+	// It is lowered automatically for wide tables so a statement never
+	// exceeds the dialect's bind parameter limit.
+	BatchSize int
+
+	// Progress, when set, is called after every batch with the table name,
+	// the rows inserted so far and the table's total row count.
+	Progress func(table string, done, total int)
+}
+
+// TableResult reports what was written to one table.
+type TableResult struct {
+	Name string
+	Rows int
+}
+
+// Result reports what a Seed call wrote, in generation order.
+type Result struct {
+	Tables []TableResult
+}
+
+// TotalRows returns the number of rows inserted across all tables.
+func (r *Result) TotalRows() int {
+	total := 0
+	for _, t := range r.Tables {
+		total += t.Rows
+	}
+	return total
+}
+
+// Seeder inserts generated data into a database.
+type Seeder struct {
+	db      *sql.DB
+	dialect dialect.Dialect
+	opts    Options
+}
+
+// New creates a Seeder that writes to db using the given dialect.
+func New(db *sql.DB, d dialect.Dialect, opts Options) *Seeder {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Seeder{
+		db:      db,
+		dialect: d,
+		opts:    opts,
+	}
+}
+
+// Connect opens a connection pool for the dialect and verifies the server is
+// reachable.
+func Connect(ctx context.Context, d dialect.Dialect, cfg dialect.ConnConfig) (*sql.DB, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(dialect.Port(d, cfg)))
+
+	db, err := sql.Open(d.DriverName(), d.DSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s connection to %s: %w", d.Name(), addr, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s at %s (database '%s'): %w", d.Name(), addr, cfg.Database, err)
+	}
+	return db, nil
+}
+
+// Seed creates every table in ds and inserts its rows.
+// Tables are processed in the dataset's order, which is the schema's
+// generation order. Returns the first error encountered.
+func (s *Seeder) Seed(ctx context.Context, sch *schema.Schema, ds *generator.Dataset) (*Result, error) {
+	// Back-fill UPDATEs find their rows by primary key; check before creating
+	// anything rather than leave the deferred columns NULL
+	for _, data := range ds.Tables {
+		if t := sch.Table(data.Name); t != nil && len(data.Deferred) > 0 && len(data.Rows) > 0 && t.PrimaryKey() == nil {
+			return nil, fmt.Errorf("table '%s': deferred foreign keys require a primary key", data.Name)
+		}
+	}
+
+	created := make([]string, 0, len(ds.Tables))
+	for _, data := range ds.Tables {
+		table := sch.Table(data.Name)
+		if table == nil {
+			return nil, fmt.Errorf("table '%s': not found in schema", data.Name)
+		}
+		stmt := ddl.CreateTable(s.dialect, table, ddl.Options{InlineForeignKeys: true, SkipForeignKeys: data.Deferred})
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			if tableExists(err) {
+				err = fmt.Errorf("table '%s' already exists in database; seed into an empty database, or drop the schema's tables first: %w", data.Name, err)
+			} else {
+				err = fmt.Errorf("table '%s': failed to create table: %w", data.Name, err)
+			}
+			return nil, errors.Join(err, s.dropTables(ctx, created))
+		}
+		created = append(created, data.Name)
+	}
+
+	// Deferred foreign keys may point at tables created after their own
+	for _, data := range ds.Tables {
+		table := sch.Table(data.Name)
+		for _, name := range data.Deferred {
+			if _, err := s.db.ExecContext(ctx, ddl.AddForeignKey(s.dialect, table.Name, table.Column(name))); err != nil {
+				return nil, fmt.Errorf("table '%s': failed to add foreign key '%s': %w", table.Name, ddl.ForeignKeyName(table.Name, name), err)
+			}
+		}
+	}
+
+	result := &Result{}
+	for _, data := range ds.Tables {
+		if err := s.insertTable(ctx, sch.Table(data.Name), data); err != nil {
+			return nil, err
+		}
+		result.Tables = append(result.Tables, TableResult{Name: data.Name, Rows: len(data.Rows)})
+	}
+
+	for _, data := range ds.Tables {
+		if err := s.backfillTable(ctx, sch.Table(data.Name), data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Indexes are built once the data is loaded, which is faster than
+	// maintaining them row by row
+	for _, data := range ds.Tables {
+		table := sch.Table(data.Name)
+		for _, idx := range table.Indexes {
+			if _, err := s.db.ExecContext(ctx, ddl.CreateIndex(s.dialect, table.Name, idx)); err != nil {
+				return nil, fmt.Errorf("table '%s': failed to create index '%s': %w", table.Name, idx.Name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// dropTables drops the named tables in reverse order, so children go before
+// the parents their inline foreign keys point at. It is used to undo a Seed
+// that failed while creating tables, and returns the first error.
+func (s *Seeder) dropTables(ctx context.Context, tables []string) error {
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := s.db.ExecContext(ctx, ddl.DropTable(s.dialect, tables[i])); err != nil {
+			return fmt.Errorf("table '%s': failed to drop table: %w", tables[i], err)
+		}
+	}
+	return nil
+}
+
+// tableExists reports whether err is the server refusing to create a table
+// that already exists: MySQL error 1050 (ER_TABLE_EXISTS_ERROR) or
+// PostgreSQL SQLSTATE 42P07 (duplicate_table).
+func tableExists(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1050
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "42P07"
+	}
+	return false
+}
+
+// insertTable writes all rows of one table inside a single transaction.
+func (s *Seeder) insertTable(ctx context.Context, table *schema.Table, data *generator.TableData) error {
+	if len(data.Rows) == 0 {
+		return nil
+	}
+
+	batch := s.batchSize(len(data.Columns))
+	fullBatchSQL := InsertSQL(s.dialect, data.Name, data.Columns, batch)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("table '%s': failed to begin transaction: %w", data.Name, err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback() }()
+
+	// Deferred columns are inserted as NULL and set by backfillTable
+	var deferred []int
+	for _, name := range data.Deferred {
+		deferred = append(deferred, data.ColumnIndex(name))
+	}
+
+	args := make([]interface{}, 0, batch*len(data.Columns))
+	for start := 0; start < len(data.Rows); start += batch {
+		end := min(start+batch, len(data.Rows))
+
+		args = args[:0]
+		for _, row := range data.Rows[start:end] {
+			first := len(args)
+			args = append(args, row...)
+			for _, i := range deferred {
+				args[first+i] = nil
+			}
+		}
+
+		query := fullBatchSQL
+		if end-start != batch {
+			query = InsertSQL(s.dialect, data.Name, data.Columns, end-start)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("table '%s': failed to insert rows %d-%d: %w", data.Name, start+1, end, err)
+		}
+
+		if s.opts.Progress != nil {
+			s.opts.Progress(data.Name, end, len(data.Rows))
+		}
+	}
+
+	if stmt := ddl.ResetIdentity(s.dialect, table); stmt != "" {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("table '%s': failed to reset identity sequence: %w", data.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("table '%s': failed to commit: %w", data.Name, err)
+	}
+	return nil
+}
+
+// backfillTable sets the deferred foreign key columns of a table, one UPDATE
+// per row that has a non-NULL value, inside a single transaction.
+func (s *Seeder) backfillTable(ctx context.Context, table *schema.Table, data *generator.TableData) error {
+	if len(data.Deferred) == 0 || len(data.Rows) == 0 {
+		return nil
+	}
+	// Seed has already checked that a table with deferred keys has one
+	pk := table.PrimaryKey()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("table '%s': failed to begin transaction: %w", data.Name, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := UpdateSQL(s.dialect, data.Name, data.Deferred, pk.Name)
+	indexes := make([]int, len(data.Deferred))
+	for i, name := range data.Deferred {
+		indexes[i] = data.ColumnIndex(name)
+	}
+	key := data.ColumnIndex(pk.Name)
+
+	args := make([]interface{}, len(indexes)+1)
+	for r, row := range data.Rows {
+		set := false
+		for i, idx := range indexes {
+			args[i] = row[idx]
+			set = set || row[idx] != nil
+		}
+		if !set {
+			continue
+		}
+		args[len(indexes)] = row[key]
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("table '%s': failed to back-fill row %d: %w", data.Name, r+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("table '%s': failed to commit: %w", data.Name, err)
+	}
+	return nil
+}
+
+// batchSize returns the rows per statement for a table with the given
+// number of columns, respecting the dialect's bind parameter limit.
+func (s *Seeder) batchSize(columns int) int {
+	if columns == 0 {
+		return s.opts.BatchSize
+	}
+	return max(1, min(s.opts.BatchSize, s.dialect.MaxParams()/columns))
+}
+
+// InsertSQL returns a multi-row INSERT statement with bind placeholders for
+// the given number of rows.
+func InsertSQL(d dialect.Dialect, table string, columns []string, rows int) string {
+	var b strings.Builder
+
+	b.WriteString("INSERT INTO ")
+	b.WriteString(d.QuoteIdent(table))
+	b.WriteString(" (")
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(d.QuoteIdent(col))
+	}
+	b.WriteString(") VALUES ")
+
+	n := 1
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for c := range columns {
+			if c > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(d.Placeholder(n))
+			n++
+		}
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// UpdateSQL returns an UPDATE statement that sets columns on the row whose
+// key column matches. The bind placeholders are the column values in order,
+// followed by the key.
+func UpdateSQL(d dialect.Dialect, table string, columns []string, key string) string {
+	set := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = d.QuoteIdent(col) + " = " + d.Placeholder(i+1)
+	}
+	return "UPDATE " + d.QuoteIdent(table) + " SET " + strings.Join(set, ", ") +
+		" WHERE " + d.QuoteIdent(key) + " = " + d.Placeholder(len(columns)+1)
+}
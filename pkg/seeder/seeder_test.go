@@ -0,0 +1,332 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a database/sql driver that records every statement and
+// transaction event instead of talking to a database. A statement containing
+// failOn returns failErr, or a generic error when failErr is nil.
+type recorder struct {
+	mu      sync.Mutex
+	events  []string
+	args    [][]driver.NamedValue
+	failOn  string
+	failErr error
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &recorderConn{r}, nil }
+
+func (r *recorder) record(event string, args []driver.NamedValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	r.args = append(r.args, args)
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *recorderConn) Close() error { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("BEGIN", nil)
+	return &recorderTx{c.r}, nil
+}
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.r.failOn != "" && strings.Contains(query, c.r.failOn) {
+		if c.r.failErr != nil {
+			return nil, c.r.failErr
+		}
+		return nil, errors.New("simulated failure")
+	}
+	c.r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (tx *recorderTx) Commit() error   { tx.r.record("COMMIT", nil); return nil }
+func (tx *recorderTx) Rollback() error { tx.r.record("ROLLBACK", nil); return nil }
+
+var (
+	registerOnce sync.Once
+	current      *recorder
+)
+
+// recorderDriver forwards to whichever recorder the running test installed.
+type recorderDriver struct{}
+
+func (recorderDriver) Open(name string) (driver.Conn, error) { return current.Open(name) }
+
+// openRecorder returns a *sql.DB backed by a fresh recorder.
+func openRecorder(t *testing.T) (*sql.DB, *recorder) {
+	t.Helper()
+	registerOnce.Do(func() { sql.Register("seeder-recorder", recorderDriver{}) })
+
+	current = &recorder{}
+	db, err := sql.Open("seeder-recorder", "")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, current
+}
+
+// testSchema is a two-table schema with a foreign key between them.
+func testSchema() *schema.Schema {
+	return &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql", "postgres"},
+		Tables: []schema.Table{
+			{
+				Name:        "borrowers",
+				RecordCount: 5,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true, AutoIncrement: true},
+					{Name: "email", Type: "varchar(255)", Unique: true, Generator: "email"},
+				},
+			},
+			{
+				Name:        "loans",
+				RecordCount: 7,
+				Columns: []schema.Column{
+					{Name: "id", Type: "int", PrimaryKey: true},
+					{Name: "borrower_id", Type: "int", ForeignKey: &schema.ForeignKey{
+						Table: "borrowers", Column: "id", OnDelete: "CASCADE", OnUpdate: "CASCADE",
+					}},
+					{Name: "amount", Type: "decimal(10,2)", Nullable: true, Generator: "decimal_range",
+						GeneratorParams: map[string]interface{}{"min": 100.0, "max": 200.0}},
+				},
+				Indexes: []schema.Index{{Name: "idx_borrower_id", Columns: []string{"borrower_id"}}},
+			},
+		},
+		GenerationOrder: []string{"borrowers", "loans"},
+	}
+}
+
+func TestSeed_CreatesTablesAndInsertsInBatches(t *testing.T) {
+	db, rec := openRecorder(t)
+	s := testSchema()
+	ds, err := generator.Generate(s, generator.Options{Seed: 1})
+	require.NoError(t, err)
+
+	var progress []int
+	result, err := New(db, dialect.Postgres{}, Options{
+		BatchSize: 3,
+		Progress: func(table string, done, total int) {
+			if table == "loans" {
+				progress = append(progress, done)
+			}
+		},
+	}).Seed(context.Background(), s, ds)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TableResult{{Name: "borrowers", Rows: 5}, {Name: "loans", Rows: 7}}, result.Tables)
+	assert.Equal(t, 12, result.TotalRows())
+	assert.Equal(t, []int{3, 6, 7}, progress)
+
+	var kinds []string
+	for _, e := range rec.events {
+		switch {
+		case strings.HasPrefix(e, "CREATE TABLE"):
+			kinds = append(kinds, "CREATE "+strings.Fields(e)[2])
+		case strings.HasPrefix(e, "INSERT INTO"):
+			kinds = append(kinds, "INSERT "+strings.Fields(e)[2])
+		case strings.HasPrefix(e, "CREATE INDEX"):
+			kinds = append(kinds, "INDEX "+strings.Fields(e)[2])
+		case strings.HasPrefix(e, "SELECT setval"):
+			kinds = append(kinds, "SETVAL "+strings.Fields(e)[len(strings.Fields(e))-1])
+		default:
+			kinds = append(kinds, e)
+		}
+	}
+	assert.Equal(t, []string{
+		`CREATE "borrowers"`, `CREATE "loans"`,
+		"BEGIN", `INSERT "borrowers"`, `INSERT "borrowers"`, `SETVAL "borrowers"`, "COMMIT",
+		"BEGIN", `INSERT "loans"`, `INSERT "loans"`, `INSERT "loans"`, "COMMIT",
+		`INDEX "idx_borrower_id"`,
+	}, kinds)
+
+	// Foreign keys are declared inline since parents are created first
+	assert.Contains(t, rec.events[1], `CONSTRAINT "fk_loans_borrower_id" FOREIGN KEY ("borrower_id") REFERENCES "borrowers" ("id")`)
+
+	// Batches carry every row's values in order
+	first := rec.events[3]
+	assert.Equal(t, `INSERT INTO "borrowers" ("id", "email") VALUES ($1, $2), ($3, $4), ($5, $6)`, first)
+	require.Len(t, rec.args[3], 6)
+	assert.Equal(t, int64(1), rec.args[3][0].Value)
+	assert.Equal(t, ds.Table("borrowers").Rows[0][1], rec.args[3][1].Value)
+
+	last := rec.events[len(rec.events)-3]
+	assert.Equal(t, `INSERT INTO "loans" ("id", "borrower_id", "amount") VALUES ($1, $2, $3)`, last)
+}
+
+func TestSeed_BackfillsDeferredForeignKeys(t *testing.T) {
+	db, rec := openRecorder(t)
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{{
+			Name:        "employees",
+			RecordCount: 4,
+			Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "manager_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{
+					Table: "employees", Column: "id", OnDelete: "SET NULL", OnUpdate: "CASCADE",
+				}},
+			},
+		}},
+	}
+	ds, err := generator.Generate(s, generator.Options{Seed: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"manager_id"}, ds.Table("employees").Deferred)
+
+	_, err = New(db, dialect.MySQL{}, Options{}).Seed(context.Background(), s, ds)
+	require.NoError(t, err)
+
+	// The deferred key is added after CREATE TABLE rather than inline
+	assert.NotContains(t, rec.events[0], "fk_employees_manager_id")
+	assert.True(t, strings.HasPrefix(rec.events[1], "ALTER TABLE `employees` ADD CONSTRAINT `fk_employees_manager_id`"), rec.events[1])
+
+	// Rows are inserted with NULL managers
+	require.Equal(t, "INSERT INTO `employees` (`id`, `manager_id`) VALUES (?, ?), (?, ?), (?, ?), (?, ?)", rec.events[3])
+	for i := 1; i < len(rec.args[3]); i += 2 {
+		assert.Nil(t, rec.args[3][i].Value)
+	}
+
+	// Then every non-root row is updated with its manager
+	var updates int
+	for i, e := range rec.events {
+		if e != "UPDATE `employees` SET `manager_id` = ? WHERE `id` = ?" {
+			continue
+		}
+		updates++
+		assert.Equal(t, ds.Table("employees").Rows[rec.args[i][1].Value.(int64)-1][1], rec.args[i][0].Value)
+	}
+	assert.Equal(t, 3, updates, "every row but the root should have a manager")
+}
+
+func TestSeed_DeferredForeignKeysNeedPrimaryKey(t *testing.T) {
+	db, rec := openRecorder(t)
+	s := &schema.Schema{
+		Name:         "test",
+		DatabaseType: []string{"mysql"},
+		Tables: []schema.Table{{
+			Name:        "employees",
+			RecordCount: 4,
+			Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "manager_id", Type: "int", Nullable: true, ForeignKey: &schema.ForeignKey{
+					Table: "employees", Column: "id", OnDelete: "SET NULL", OnUpdate: "CASCADE",
+				}},
+			},
+		}},
+	}
+	ds, err := generator.Generate(s, generator.Options{Seed: 1})
+	require.NoError(t, err)
+	s.Tables[0].Columns[0].PrimaryKey = false
+
+	_, err = New(db, dialect.MySQL{}, Options{}).Seed(context.Background(), s, ds)
+	require.Error(t, err)
+	assert.Equal(t, "table 'employees': deferred foreign keys require a primary key", err.Error())
+	assert.Empty(t, rec.events, "nothing should be created")
+}
+
+func TestSeed_InsertFailureRollsBack(t *testing.T) {
+	db, rec := openRecorder(t)
+	rec.failOn = `INSERT INTO "loans"`
+	s := testSchema()
+	ds, err := generator.Generate(s, generator.Options{})
+	require.NoError(t, err)
+
+	_, err = New(db, dialect.Postgres{}, Options{}).Seed(context.Background(), s, ds)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'loans': failed to insert rows 1-7: simulated failure")
+	assert.Equal(t, "ROLLBACK", rec.events[len(rec.events)-1])
+}
+
+func TestSeed_CreateFailure(t *testing.T) {
+	db, rec := openRecorder(t)
+	rec.failOn = "CREATE TABLE `loans`"
+	s := testSchema()
+	ds, err := generator.Generate(s, generator.Options{})
+	require.NoError(t, err)
+
+	_, err = New(db, dialect.MySQL{}, Options{}).Seed(context.Background(), s, ds)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'loans': failed to create table")
+	assert.Equal(t, "DROP TABLE `borrowers`", rec.events[len(rec.events)-1], "tables created before the failure are dropped")
+}
+
+func TestSeed_TableAlreadyExists(t *testing.T) {
+	tests := []struct {
+		dialect dialect.Dialect
+		failOn  string
+		err     error
+	}{
+		{dialect.MySQL{}, "CREATE TABLE `borrowers`", &mysql.MySQLError{Number: 1050, Message: "Table 'borrowers' already exists"}},
+		{dialect.Postgres{}, `CREATE TABLE "borrowers"`, &pq.Error{Code: "42P07", Message: `relation "borrowers" already exists`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			db, rec := openRecorder(t)
+			rec.failOn, rec.failErr = tt.failOn, tt.err
+			s := testSchema()
+			ds, err := generator.Generate(s, generator.Options{})
+			require.NoError(t, err)
+
+			_, err = New(db, tt.dialect, Options{}).Seed(context.Background(), s, ds)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "table 'borrowers' already exists in database; seed into an empty database, or drop the schema's tables first")
+			assert.ErrorIs(t, err, tt.err)
+			assert.Empty(t, rec.events, "nothing should be written")
+		})
+	}
+}
+
+func TestSeed_LaterTableAlreadyExists(t *testing.T) {
+	db, rec := openRecorder(t)
+	rec.failOn, rec.failErr = `CREATE TABLE "loans"`, &pq.Error{Code: "42P07", Message: `relation "loans" already exists`}
+	s := testSchema()
+	ds, err := generator.Generate(s, generator.Options{})
+	require.NoError(t, err)
+
+	_, err = New(db, dialect.Postgres{}, Options{}).Seed(context.Background(), s, ds)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'loans' already exists in database")
+	require.Len(t, rec.events, 2)
+	assert.True(t, strings.HasPrefix(rec.events[0], `CREATE TABLE "borrowers"`), rec.events[0])
+	assert.Equal(t, `DROP TABLE "borrowers"`, rec.events[1], "the database is left as it was found")
+}
+
+func TestBatchSizeRespectsParameterLimit(t *testing.T) {
+	s := New(nil, dialect.MySQL{}, Options{BatchSize: 10000})
+	assert.Equal(t, 65535/20, s.batchSize(20))
+	assert.Equal(t, DefaultBatchSize, New(nil, dialect.MySQL{}, Options{}).batchSize(3))
+}
+
+func TestUpdateSQL(t *testing.T) {
+	assert.Equal(t, `UPDATE "t" SET "a" = $1, "b" = $2 WHERE "id" = $3`,
+		UpdateSQL(dialect.Postgres{}, "t", []string{"a", "b"}, "id"))
+}
+
+func TestInsertSQL(t *testing.T) {
+	assert.Equal(t, "INSERT INTO `t` (`a`, `b`) VALUES (?, ?), (?, ?)",
+		InsertSQL(dialect.MySQL{}, "t", []string{"a", "b"}, 2))
+}
@@ -0,0 +1,94 @@
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// Database checks the validation rules of s against the rows stored in db,
+// typically right after seeding. Each rule runs as one COUNT query.
+func Database(ctx context.Context, db *sql.DB, d dialect.Dialect, s *schema.Schema) (*Report, error) {
+	return run(s, databaseCounter{ctx: ctx, db: db, dialect: d})
+}
+
+// databaseCounter evaluates checks with SQL queries.
+type databaseCounter struct {
+	ctx     context.Context
+	db      *sql.DB
+	dialect dialect.Dialect
+}
+
+func (d databaseCounter) rows(table string) (int, error) {
+	n, err := d.count("SELECT COUNT(*) FROM " + d.dialect.QuoteIdent(table))
+	if err != nil {
+		return 0, fmt.Errorf("table '%s': %w", table, err)
+	}
+	return n, nil
+}
+
+func (d databaseCounter) violations(c *schema.Check) (int, error) {
+	query, err := ViolationsSQL(d.dialect, c)
+	if err != nil {
+		return 0, err
+	}
+	return d.count(query)
+}
+
+// count runs a COUNT query. Its error leaves the SQL out: the caller names
+// the rule or table, and ViolationsSQL reproduces the query.
+func (d databaseCounter) count(query string) (int, error) {
+	var n int
+	if err := d.db.QueryRowContext(d.ctx, query).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return n, nil
+}
+
+// ViolationsSQL returns a query counting the rows that break a column
+// check (the distinct duplicated values, for UNIQUE). Comparisons skip rows
+// where either side is NULL. Row-count checks have no such query.
+func ViolationsSQL(d dialect.Dialect, c *schema.Check) (string, error) {
+	table := d.QuoteIdent(c.Column.Table)
+	col := d.QuoteIdent(c.Column.Column)
+
+	switch c.Kind {
+	case schema.CheckReferences:
+		return fmt.Sprintf("SELECT COUNT(*) FROM %s c WHERE c.%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s p WHERE p.%s = c.%s)",
+			table, col, d.QuoteIdent(c.Ref.Table), d.QuoteIdent(c.Ref.Column), col), nil
+
+	case schema.CheckUnique:
+		return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s FROM %s WHERE %s IS NOT NULL GROUP BY %s HAVING COUNT(*) > 1) dup",
+			col, table, col, col), nil
+
+	case schema.CheckNotNull:
+		return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", table, col), nil
+
+	case schema.CheckCompare:
+		where := col + " IS NOT NULL"
+		var cond string
+		switch {
+		case c.Ref != nil:
+			other := d.QuoteIdent(c.Ref.Column)
+			where += " AND " + other + " IS NOT NULL"
+			cond = col + " " + sqlOp(c.Op) + " " + other
+		case c.Op == "BETWEEN":
+			cond = fmt.Sprintf("%s BETWEEN %s AND %s", col, d.Literal(c.Values[0]), d.Literal(c.Values[1]))
+		default:
+			cond = col + " " + sqlOp(c.Op) + " " + d.Literal(c.Values[0])
+		}
+		return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s AND NOT (%s)", table, where, cond), nil
+	}
+	return "", fmt.Errorf("rule kind '%s' has no violations query", c.Kind)
+}
+
+// sqlOp spells a rule operator in standard SQL.
+func sqlOp(op string) string {
+	if op == "!=" {
+		return "<>"
+	}
+	return op
+}
@@ -0,0 +1,221 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// Dataset checks the validation rules of s against a generated dataset.
+// It returns an error when a rule cannot be evaluated, for example because
+// it names a table that was not generated.
+func Dataset(s *schema.Schema, ds *generator.Dataset) (*Report, error) {
+	return run(s, datasetCounter{ds})
+}
+
+// datasetCounter evaluates checks over the rows held in memory.
+type datasetCounter struct {
+	ds *generator.Dataset
+}
+
+func (d datasetCounter) rows(table string) (int, error) {
+	t := d.ds.Table(table)
+	if t == nil {
+		return 0, fmt.Errorf("table '%s' was not generated", table)
+	}
+	return len(t.Rows), nil
+}
+
+// values returns every value of a column.
+func (d datasetCounter) values(ref schema.ColumnRef) ([]interface{}, error) {
+	t := d.ds.Table(ref.Table)
+	if t == nil {
+		return nil, fmt.Errorf("table '%s' was not generated", ref.Table)
+	}
+	if t.ColumnIndex(ref.Column) < 0 {
+		return nil, fmt.Errorf("column '%s' was not generated", ref)
+	}
+	return t.ColumnValues(ref.Column), nil
+}
+
+func (d datasetCounter) violations(c *schema.Check) (int, error) {
+	values, err := d.values(c.Column)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	switch c.Kind {
+	case schema.CheckReferences:
+		parents, err := d.values(*c.Ref)
+		if err != nil {
+			return 0, err
+		}
+		known := make(map[interface{}]bool, len(parents))
+		for _, v := range parents {
+			known[valueKey(v)] = true
+		}
+		for _, v := range values {
+			if v != nil && !known[valueKey(v)] {
+				n++
+			}
+		}
+
+	case schema.CheckUnique:
+		seen := make(map[interface{}]int, len(values))
+		for _, v := range values {
+			if v == nil {
+				continue
+			}
+			k := valueKey(v)
+			seen[k]++
+			if seen[k] == 2 {
+				n++
+			}
+		}
+
+	case schema.CheckNotNull:
+		for _, v := range values {
+			if v == nil {
+				n++
+			}
+		}
+
+	case schema.CheckCompare:
+		var others []interface{}
+		if c.Ref != nil {
+			if others, err = d.values(*c.Ref); err != nil {
+				return 0, err
+			}
+		}
+		for i, v := range values {
+			if v == nil || (others != nil && others[i] == nil) {
+				continue
+			}
+			if !compareRow(c, v, others, i) {
+				n++
+			}
+		}
+
+	default:
+		return 0, fmt.Errorf("unsupported rule kind '%s'", c.Kind)
+	}
+	return n, nil
+}
+
+// compareRow reports whether row i, whose checked value is v, satisfies a
+// comparison. Values that cannot be compared with the operand fail.
+func compareRow(c *schema.Check, v interface{}, others []interface{}, i int) bool {
+	ok := true
+	cmp := func(operand interface{}) int {
+		result, comparable := compareValues(v, operand)
+		ok = ok && comparable
+		return result
+	}
+
+	var satisfied bool
+	if others != nil {
+		satisfied = holds(c.Op, cmp(others[i]))
+	} else {
+		satisfied = satisfies(c.Op, cmp, c.Values)
+	}
+	return ok && satisfied
+}
+
+// compareValues orders two values, returning -1, 0 or 1. Numbers compare
+// numerically, times chronologically (a string operand is read as a date or
+// timestamp), strings lexically and false sorts before true. ok is false
+// when the values cannot be compared.
+func compareValues(a, b interface{}) (result int, ok bool) {
+	if x, isNum := number(a); isNum {
+		y, isNum := number(b)
+		if !isNum {
+			return 0, false
+		}
+		return order(x < y, x > y), true
+	}
+
+	switch x := a.(type) {
+	case time.Time:
+		y, isTime := b.(time.Time)
+		if s, isString := b.(string); isString {
+			y, isTime = parseTime(s)
+		}
+		if !isTime {
+			return 0, false
+		}
+		return order(x.Before(y), x.After(y)), true
+	case string:
+		if y, isTime := b.(time.Time); isTime {
+			r, ok := compareValues(y, x)
+			return -r, ok
+		}
+		y, isString := b.(string)
+		if !isString {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case bool:
+		y, isBool := b.(bool)
+		if !isBool {
+			return 0, false
+		}
+		return order(!x && y, x && !y), true
+	}
+	return 0, false
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// number converts the numeric types a dataset or rule can hold to float64.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// timeLayouts are the formats a string literal may use to compare with a
+// date or timestamp column.
+var timeLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// valueKey normalises a value for use as a map key, so that equal numbers
+// of different Go types and equal times in different locations match.
+func valueKey(v interface{}) interface{} {
+	if n, ok := number(v); ok {
+		return n
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return v
+}
@@ -0,0 +1,250 @@
+// Package verify checks a schema's validation_rules against data: a
+// generated dataset before it is written anywhere, or a seeded database
+// after the rows are inserted.
+//
+// Each rule is parsed with schema.ParseCheck and counts the rows that break
+// it. A rule with any violations becomes a Failure; the report's Err is
+// non-nil when a failure has error severity, while warning-severity
+// failures are only reported.
+//
+// Example usage:
+//
+//	report, err := verify.Dataset(s, ds)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, f := range report.Warnings() {
+//	    log.Printf("warning: %v", f)
+//	}
+//	if err := report.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+package verify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+)
+
+// Failure is a validation rule the data does not satisfy.
+type Failure struct {
+	Rule     schema.ValidationRule
+	Severity schema.Severity
+
+	// Violations is the number of rows (or, for UNIQUE, values) breaking
+	// the rule; 0 for row-count rules.
+	Violations int
+
+	// Message describes what was found, e.g. "3 values of
+	// loans.borrower_id have no match in borrowers.id".
+	Message string
+}
+
+func (f Failure) Error() string {
+	msg := fmt.Sprintf("validation rule '%s' failed: %s", f.Rule.Rule, f.Message)
+	if f.Rule.Description != "" {
+		msg += " (" + f.Rule.Description + ")"
+	}
+	return msg
+}
+
+// Report holds the outcome of checking every validation rule.
+type Report struct {
+	// Checked is the number of rules evaluated.
+	Checked  int
+	Failures []Failure
+}
+
+// Errors returns the failures with error severity.
+func (r *Report) Errors() []Failure {
+	return r.filter(schema.SeverityError)
+}
+
+// Warnings returns the failures with warning severity.
+func (r *Report) Warnings() []Failure {
+	return r.filter(schema.SeverityWarning)
+}
+
+func (r *Report) filter(severity schema.Severity) []Failure {
+	var out []Failure
+	for _, f := range r.Failures {
+		if f.Severity == severity {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Err returns an error listing the error-severity failures, or nil if
+// there are none.
+func (r *Report) Err() error {
+	errs := r.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, f := range errs {
+		msgs[i] = f.Error()
+	}
+	return fmt.Errorf("%d of %d validation rules failed:\n  %s", len(errs), r.Checked, strings.Join(msgs, "\n  "))
+}
+
+// counter abstracts where the data lives, so Dataset and Database share
+// the evaluation of each rule kind.
+type counter interface {
+	// violations returns the number of rows (or values, for UNIQUE)
+	// breaking a column check.
+	violations(c *schema.Check) (int, error)
+
+	// rows returns a table's row count.
+	rows(table string) (int, error)
+}
+
+// run evaluates every rule of s against data.
+func run(s *schema.Schema, data counter) (*Report, error) {
+	report := &Report{}
+	for i, rule := range s.ValidationRules {
+		check, err := schema.ParseCheck(rule.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("validation rule %d: %w", i, err)
+		}
+
+		msg, n, err := evaluate(check, data)
+		if err != nil {
+			return nil, fmt.Errorf("validation rule '%s': %w", rule.Rule, err)
+		}
+		report.Checked++
+		if msg != "" {
+			report.Failures = append(report.Failures, Failure{Rule: rule, Severity: rule.Level(), Violations: n, Message: msg})
+		}
+	}
+	return report, nil
+}
+
+// evaluate returns a description of how data breaks check and the number
+// of violations, or "" when it holds.
+func evaluate(check *schema.Check, data counter) (string, int, error) {
+	if check.Kind == schema.CheckRowCount {
+		return evaluateRowCount(check, data)
+	}
+
+	n, err := data.violations(check)
+	if err != nil || n == 0 {
+		return "", 0, err
+	}
+
+	switch check.Kind {
+	case schema.CheckReferences:
+		return fmt.Sprintf("%d %s of %s %s no match in %s", n, plural(n, "value", "values"), check.Column, plural(n, "has", "have"), check.Ref), n, nil
+	case schema.CheckUnique:
+		return fmt.Sprintf("%d %s of %s %s more than once", n, plural(n, "value", "values"), check.Column, plural(n, "appears", "appear")), n, nil
+	case schema.CheckNotNull:
+		return fmt.Sprintf("%d %s of %s %s a NULL %s", n, plural(n, "row", "rows"), check.Column.Table, plural(n, "has", "have"), check.Column.Column), n, nil
+	default:
+		return fmt.Sprintf("%d %s of %s %s not satisfy %s", n, plural(n, "row", "rows"), check.Column.Table, plural(n, "does", "do"), condition(check)), n, nil
+	}
+}
+
+func evaluateRowCount(check *schema.Check, data counter) (string, int, error) {
+	counts := make([]int, len(check.Count))
+	for i, table := range check.Count {
+		n, err := data.rows(table)
+		if err != nil {
+			return "", 0, err
+		}
+		counts[i] = n
+	}
+
+	value := float64(counts[0])
+	label := fmt.Sprintf("COUNT(%s)", check.Count[0])
+	if len(counts) == 2 {
+		if counts[1] == 0 {
+			return fmt.Sprintf("COUNT(%s) is 0, so the ratio is undefined", check.Count[1]), 0, nil
+		}
+		value /= float64(counts[1])
+		label += fmt.Sprintf(" / COUNT(%s)", check.Count[1])
+	}
+
+	cmp := func(bound interface{}) int {
+		b := bound.(float64)
+		switch {
+		case value < b:
+			return -1
+		case value > b:
+			return 1
+		}
+		return 0
+	}
+	if satisfies(check.Op, cmp, check.Values) {
+		return "", 0, nil
+	}
+	return fmt.Sprintf("%s is %s, want %s", label, strconv.FormatFloat(value, 'g', 4, 64), bounds(check)), 0, nil
+}
+
+// satisfies applies op to a value whose comparison with each literal is
+// given by cmp (-1, 0 or 1).
+func satisfies(op string, cmp func(interface{}) int, values []interface{}) bool {
+	if op == "BETWEEN" {
+		return cmp(values[0]) >= 0 && cmp(values[1]) <= 0
+	}
+	return holds(op, cmp(values[0]))
+}
+
+// holds reports whether a comparison result (-1, 0 or 1) satisfies op.
+func holds(op string, c int) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// condition renders a CheckCompare for messages, e.g. "credit_score
+// BETWEEN 300 AND 850".
+func condition(check *schema.Check) string {
+	if check.Ref != nil {
+		return fmt.Sprintf("%s %s %s", check.Column.Column, check.Op, check.Ref.Column)
+	}
+	return check.Column.Column + " " + bounds(check)
+}
+
+// bounds renders the operator and literals of a comparison, e.g. ">= 1"
+// or "BETWEEN 1 AND 3".
+func bounds(check *schema.Check) string {
+	if check.Op == "BETWEEN" {
+		return fmt.Sprintf("BETWEEN %s AND %s", literal(check.Values[0]), literal(check.Values[1]))
+	}
+	return check.Op + " " + literal(check.Values[0])
+}
+
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	}
+	return fmt.Sprint(v)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,266 @@
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbeausoleil/sourcebox/pkg/dialect"
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loanSchema is a two-table schema; tests attach the rules under test.
+func loanSchema(rules ...schema.ValidationRule) *schema.Schema {
+	return &schema.Schema{
+		Name:         "loans",
+		DatabaseType: []string{"postgres"},
+		Tables: []schema.Table{
+			{Name: "borrowers", Columns: []schema.Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+			{Name: "loans", Columns: []schema.Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "borrower_id", Type: "int"},
+				{Name: "amount", Type: "decimal(10,2)", Nullable: true},
+				{Name: "start_date", Type: "date"},
+				{Name: "end_date", Type: "date"},
+			}},
+		},
+		ValidationRules: rules,
+	}
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// loanDataset has one dangling borrower_id, one duplicate amount, one NULL
+// amount and one loan that ends before it starts.
+func loanDataset() *generator.Dataset {
+	return &generator.Dataset{Tables: []*generator.TableData{
+		{Name: "borrowers", Columns: []string{"id"}, Rows: [][]interface{}{{int64(1)}, {int64(2)}}},
+		{Name: "loans", Columns: []string{"id", "borrower_id", "amount", "start_date", "end_date"}, Rows: [][]interface{}{
+			{int64(1), int64(1), 100.0, day(1), day(5)},
+			{int64(2), int64(2), 100.0, day(2), day(9)},
+			{int64(3), int64(7), 250.5, day(8), day(3)},
+			{int64(4), nil, nil, day(4), day(4)},
+		}},
+	}}
+}
+
+func TestDataset(t *testing.T) {
+	tests := []struct {
+		rule    string
+		message string
+		count   int
+	}{
+		{"loans.borrower_id REFERENCES borrowers.id", "1 value of loans.borrower_id has no match in borrowers.id", 1},
+		{"loans.id REFERENCES loans.id", "", 0},
+		{"loans.amount UNIQUE", "1 value of loans.amount appears more than once", 1},
+		{"loans.id UNIQUE", "", 0},
+		{"loans.amount NOT NULL", "1 row of loans has a NULL amount", 1},
+		{"loans.amount BETWEEN 100 AND 200", "1 row of loans does not satisfy amount BETWEEN 100 AND 200", 1},
+		{"loans.amount > 50", "", 0},
+		{"loans.end_date > loans.start_date", "2 rows of loans do not satisfy end_date > start_date", 2},
+		{"loans.end_date >= loans.start_date", "1 row of loans does not satisfy end_date >= start_date", 1},
+		{"loans.start_date >= '2024-01-02'", "1 row of loans does not satisfy start_date >= '2024-01-02'", 1},
+		{"loans.amount = 'high'", "3 rows of loans do not satisfy amount = 'high'", 3},
+		{"COUNT(loans) / COUNT(borrowers) BETWEEN 1 AND 2", "", 0},
+		{"COUNT(loans) / COUNT(borrowers) > 2", "COUNT(loans) / COUNT(borrowers) is 2, want > 2", 0},
+		{"COUNT(borrowers) >= 5", "COUNT(borrowers) is 2, want >= 5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			report, err := Dataset(loanSchema(schema.ValidationRule{Rule: tt.rule}), loanDataset())
+			require.NoError(t, err)
+			assert.Equal(t, 1, report.Checked)
+
+			if tt.message == "" {
+				assert.Empty(t, report.Failures)
+				assert.NoError(t, report.Err())
+				return
+			}
+			require.Len(t, report.Failures, 1)
+			assert.Equal(t, tt.message, report.Failures[0].Message)
+			assert.Equal(t, tt.count, report.Failures[0].Violations)
+		})
+	}
+}
+
+func TestDataset_Severity(t *testing.T) {
+	s := loanSchema(
+		schema.ValidationRule{Rule: "loans.borrower_id REFERENCES borrowers.id", Description: "Loans need borrowers", Severity: "error"},
+		schema.ValidationRule{Rule: "loans.amount NOT NULL", Severity: "warning"},
+		schema.ValidationRule{Rule: "loans.id UNIQUE"},
+	)
+
+	report, err := Dataset(s, loanDataset())
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	require.Len(t, report.Errors(), 1)
+	require.Len(t, report.Warnings(), 1)
+	assert.Equal(t, "validation rule 'loans.amount NOT NULL' failed: 1 row of loans has a NULL amount", report.Warnings()[0].Error())
+
+	err = report.Err()
+	require.Error(t, err)
+	assert.Equal(t, "1 of 3 validation rules failed:\n"+
+		"  validation rule 'loans.borrower_id REFERENCES borrowers.id' failed: 1 value of loans.borrower_id has no match in borrowers.id (Loans need borrowers)",
+		err.Error())
+}
+
+func TestDataset_MissingTable(t *testing.T) {
+	ds := loanDataset()
+	ds.Tables = ds.Tables[1:]
+
+	_, err := Dataset(loanSchema(schema.ValidationRule{Rule: "loans.borrower_id REFERENCES borrowers.id"}), ds)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table 'borrowers' was not generated")
+}
+
+func TestDataset_ExampleSchema(t *testing.T) {
+	s, err := schema.LoadSchema("../../schemas/example-schema.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, s.ValidationRules)
+
+	ds, err := generator.Generate(s, generator.Options{Seed: 7})
+	require.NoError(t, err)
+
+	report, err := Dataset(s, ds)
+	require.NoError(t, err)
+	assert.Equal(t, len(s.ValidationRules), report.Checked)
+	assert.Empty(t, report.Failures)
+}
+
+func TestViolationsSQL(t *testing.T) {
+	tests := []struct {
+		rule     string
+		postgres string
+	}{
+		{"loans.borrower_id REFERENCES borrowers.id",
+			`SELECT COUNT(*) FROM "loans" c WHERE c."borrower_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "borrowers" p WHERE p."id" = c."borrower_id")`},
+		{"loans.amount UNIQUE",
+			`SELECT COUNT(*) FROM (SELECT "amount" FROM "loans" WHERE "amount" IS NOT NULL GROUP BY "amount" HAVING COUNT(*) > 1) dup`},
+		{"loans.amount NOT NULL", `SELECT COUNT(*) FROM "loans" WHERE "amount" IS NULL`},
+		{"loans.amount BETWEEN 1 AND 2.5",
+			`SELECT COUNT(*) FROM "loans" WHERE "amount" IS NOT NULL AND NOT ("amount" BETWEEN 1 AND 2.5)`},
+		{"loans.status != 'closed'",
+			`SELECT COUNT(*) FROM "loans" WHERE "status" IS NOT NULL AND NOT ("status" <> 'closed')`},
+		{"loans.end_date > loans.start_date",
+			`SELECT COUNT(*) FROM "loans" WHERE "end_date" IS NOT NULL AND "start_date" IS NOT NULL AND NOT ("end_date" > "start_date")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			check, err := schema.ParseCheck(tt.rule)
+			require.NoError(t, err)
+			query, err := ViolationsSQL(dialect.Postgres{}, check)
+			require.NoError(t, err)
+			assert.Equal(t, tt.postgres, query)
+		})
+	}
+
+	check, err := schema.ParseCheck("COUNT(loans) > 1")
+	require.NoError(t, err)
+	_, err = ViolationsSQL(dialect.MySQL{}, check)
+	assert.Error(t, err)
+}
+
+// counts is a database/sql driver that answers every query with a single
+// integer: the answer keyed by the query, or 0. A query containing failOn
+// fails instead.
+type counts struct {
+	answers map[string]int
+	queries []string
+	failOn  string
+}
+
+var (
+	registerOnce sync.Once
+	current      *counts
+)
+
+type countsDriver struct{}
+
+func (countsDriver) Open(string) (driver.Conn, error) { return countsConn{}, nil }
+
+type countsConn struct{}
+
+func (countsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (countsConn) Close() error              { return nil }
+func (countsConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+func (countsConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	current.queries = append(current.queries, query)
+	if current.failOn != "" && strings.Contains(query, current.failOn) {
+		return nil, errors.New("relation does not exist")
+	}
+	return &countRows{n: int64(current.answers[query])}, nil
+}
+
+type countRows struct {
+	n    int64
+	done bool
+}
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.n
+	return nil
+}
+
+func openCounts(t *testing.T, answers map[string]int) (*sql.DB, *counts) {
+	t.Helper()
+	registerOnce.Do(func() { sql.Register("verify-counts", countsDriver{}) })
+
+	current = &counts{answers: answers}
+	db, err := sql.Open("verify-counts", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, current
+}
+
+func TestDatabase(t *testing.T) {
+	db, rec := openCounts(t, map[string]int{
+		`SELECT COUNT(*) FROM "loans" c WHERE c."borrower_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "borrowers" p WHERE p."id" = c."borrower_id")`: 2,
+		`SELECT COUNT(*) FROM "loans"`:     6,
+		`SELECT COUNT(*) FROM "borrowers"`: 2,
+	})
+	s := loanSchema(
+		schema.ValidationRule{Rule: "loans.borrower_id REFERENCES borrowers.id"},
+		schema.ValidationRule{Rule: "COUNT(loans) / COUNT(borrowers) <= 2", Severity: "warning"},
+	)
+
+	report, err := Database(context.Background(), db, dialect.Postgres{}, s)
+	require.NoError(t, err)
+	require.Len(t, rec.queries, 3)
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, "2 values of loans.borrower_id have no match in borrowers.id", report.Failures[0].Message)
+	assert.Equal(t, schema.SeverityWarning, report.Failures[1].Severity)
+	assert.Equal(t, "COUNT(loans) / COUNT(borrowers) is 3, want <= 2", report.Failures[1].Message)
+}
+
+func TestDatabase_QueryFailure(t *testing.T) {
+	db, rec := openCounts(t, nil)
+	rec.failOn = `"borrowers"`
+	s := loanSchema(schema.ValidationRule{Rule: "loans.borrower_id REFERENCES borrowers.id"})
+
+	_, err := Database(context.Background(), db, dialect.Postgres{}, s)
+	require.Error(t, err)
+	assert.Equal(t, "validation rule 'loans.borrower_id REFERENCES borrowers.id': failed to count rows: relation does not exist", err.Error(),
+		"the error names the rule rather than repeating its SQL")
+}
@@ -0,0 +1,11 @@
+// Package schemas holds the built-in schema definitions that are compiled
+// into the sourcebox binary, so they are available offline without any
+// files on disk.
+package schemas
+
+import "embed"
+
+// FS contains every built-in schema JSON file.
+//
+//go:embed *.json
+var FS embed.FS
@@ -0,0 +1,107 @@
+package schemas
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/jbeausoleil/sourcebox/pkg/generator"
+	"github.com/jbeausoleil/sourcebox/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuiltinSchemasGenerate verifies that every embedded schema is valid,
+// that its metadata matches its tables, and that it generates the declared
+// number of rows. Tables sized by a foreign key cardinality only need rows.
+func TestBuiltinSchemasGenerate(t *testing.T) {
+	files, err := fs.Glob(FS, "*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			f, err := FS.Open(file)
+			require.NoError(t, err)
+			defer f.Close()
+
+			s, err := schema.ParseSchema(f)
+			require.NoError(t, err)
+
+			total := 0
+			for _, table := range s.Tables {
+				total += table.RecordCount
+			}
+			assert.Equal(t, total, s.Metadata.TotalRecords, "metadata.total_records should match the tables")
+			assert.ElementsMatch(t, []string{"mysql", "postgres"}, s.DatabaseType)
+
+			ds, err := generator.Generate(s, generator.Options{Seed: 1})
+			require.NoError(t, err)
+			for _, table := range s.Tables {
+				if cardinality(&table) != nil {
+					assert.NotEmpty(t, ds.Table(table.Name).Rows, "table %s", table.Name)
+					continue
+				}
+				assert.Len(t, ds.Table(table.Name).Rows, table.RecordCount, "table %s", table.Name)
+			}
+		})
+	}
+}
+
+// TestFintechLoansCardinality verifies that fintech-loans looks like a real
+// portfolio: loans per borrower are skewed rather than uniform, and each
+// loan has one payment per month of its term.
+func TestFintechLoansCardinality(t *testing.T) {
+	f, err := FS.Open("example-schema.json")
+	require.NoError(t, err)
+	defer f.Close()
+	s, err := schema.ParseSchema(f)
+	require.NoError(t, err)
+
+	ds, err := generator.Generate(s, generator.Options{Seed: 1})
+	require.NoError(t, err)
+
+	loans := make(map[interface{}]int)
+	for _, id := range ds.Table("loans").ColumnValues("borrower_id") {
+		loans[id]++
+	}
+	none, many, most := 0, 0, 0
+	for _, id := range ds.Table("borrowers").ColumnValues("id") {
+		n := loans[id]
+		switch {
+		case n == 0:
+			none++
+		case n >= 5:
+			many++
+		}
+		most = max(most, n)
+	}
+	borrowers := len(ds.Table("borrowers").Rows)
+	assert.InDelta(t, 0.27, float64(none)/float64(borrowers), 0.07, "about a quarter of borrowers have no loans")
+	assert.InDelta(t, 0.23, float64(many)/float64(borrowers), 0.07, "a sizeable tail has five or more")
+	assert.LessOrEqual(t, most, 12)
+	assert.Greater(t, most, 8, "a few borrowers have many loans")
+
+	payments := make(map[interface{}]int)
+	for _, id := range ds.Table("payments").ColumnValues("loan_id") {
+		payments[id]++
+	}
+	terms := make(map[int64]int)
+	months := ds.Table("loans").ColumnValues("term_months")
+	for i, id := range ds.Table("loans").ColumnValues("id") {
+		term := months[i].(int64)
+		terms[term]++
+		assert.Equal(t, int(term), payments[id], "loan %v should have one payment per month of its term", id)
+	}
+	assert.Len(t, terms, 3, "terms of 12, 24 and 36 months")
+	assert.Greater(t, terms[12], terms[36])
+}
+
+// cardinality returns the cardinality that sizes t, or nil.
+func cardinality(t *schema.Table) *schema.Cardinality {
+	for _, col := range t.Columns {
+		if col.ForeignKey != nil && col.ForeignKey.Cardinality != nil {
+			return col.ForeignKey.Cardinality
+		}
+	}
+	return nil
+}